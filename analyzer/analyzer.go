@@ -13,12 +13,22 @@ const (
 	ReasonDomainCannotReceiveEmail           = "Domain cannot receive email"
 	ReasonSuspiciousEmailPatternDetected     = "Suspicious email pattern detected"
 	ReasonShortLocalPart                     = "Email has unusually short local part"
+	ReasonKeyboardSequence                   = "Local part contains a keyboard sequence"
+	ReasonFrequentCaseSwitches               = "Local part switches case frequently"
+	ReasonUnusualScript                      = "Local part has few letters"
+	ReasonNotNameLike                        = "Local part does not look like a name"
+	ReasonHighEntropyLocalPart               = "Local part has high entropy"
+	ReasonGibberishLocalPart                 = "Local part is not pronounceable"
 	ReasonTooManyConsecutiveNumbers          = "Email has too many consecutive numbers"
 	ReasonEmailHasExcessiveSpecialChars      = "Email has excessive special characters"
 	ReasonMultipleSuspiciousPatternsDetected = "Multiple suspicious patterns detected - likely automated"
 	ReasonRandomPatternOnWellKnownDomain     = "Random pattern on well-known domain - likely bot generated"
 	ReasonRandomPatternOnUnknownDomain       = "Random pattern on unknown domain - likely bot generated"
 	ReasonWellKnownEmailProvider             = "Well-known email provider"
+	ReasonTop1KEmailProvider                 = "Well-known email provider (top 1K domain)"
+	ReasonTop10KEmailProvider                = "Well-known email provider (top 10K domain)"
+	ReasonTop100KEmailProvider               = "Well-known email provider (top 100K domain)"
+	ReasonTop1MEmailProvider                 = "Well-known email provider (top 1M domain)"
 	ReasonUnknownEmailProvider               = "Unknown email provider"
 	ReasonOnlyOneMXRecord                    = "Domain has only one MX record"
 	ReasonLackSPFRecord                      = "Domain lacks SPF record"
@@ -27,9 +37,18 @@ const (
 	ReasonHasStrongDMARCPolicy               = "Domain has strong DMARC policy"
 	ReasonNoSupiciousSignalsDetected         = "No suspicious signals detected"
 	ReasonEducationalInstitutionDomain       = "Email from educational institution domain"
+	ReasonPublicSectorDomain                 = "Email from government, military or non-profit domain"
 	ReasonStudentIDStaffIDPatternDetected    = "Student/Staff ID pattern detected"
 	ReasonParkedDomain                       = "Domain is parked or inactive"
 	ReasonTooStrictSPFPolicy                 = "Domain has too strict SPF policy"
+	ReasonDisposableMailServers              = "Domain uses disposable mail servers"
+	ReasonDisposableNameServers              = "Domain uses disposable name servers"
+	ReasonRecentlyRegisteredDomain           = "Domain was registered recently"
+	ReasonImplicitMX                         = "Domain has no MX record and relies on its address records"
+	ReasonSharedDisposableInfrastructure     = "Domain shares mail infrastructure with disposable domains"
+	ReasonConfusableDomain                   = "Domain imitates a well-known domain with look-alike characters"
+	ReasonMixedScriptDomain                  = "Domain mixes scripts"
+	ReasonMixedScriptLocalPart               = "Local part mixes scripts"
 )
 
 type Analyzer struct{}
@@ -44,17 +63,27 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 		Reasons: []string{},
 	}
 
-	isEducational := result.Educational.Checked && result.Educational.Value
+	isEducational := result.Educational.Checked && result.Educational.Value.Educational
+	isPublicSector := result.Sector.Checked && result.Sector.Value.Sector != ""
 
-	if result.Disposable.Checked && result.Disposable.Value {
+	// Institutions hand out ID-style addresses, so their local parts are
+	// not held against them.
+	isInstitutional := isEducational || isPublicSector
+
+	institutionReason := ReasonEducationalInstitutionDomain
+	if !isEducational {
+		institutionReason = ReasonPublicSectorDomain
+	}
+
+	if result.Disposable.Checked && result.Disposable.Value.Disposable {
 		report.Score = 1.0
 		report.RiskLevel = emailchecker.RiskLevelHigh
-		report.Reasons = append(report.Reasons, ReasonDisposableBlocked)
+		report.Reasons = append(report.Reasons, disposableReason(result.Disposable.Value.Reason))
 
 		return report
 	}
 
-	if result.DNS.Checked && !result.DNS.Value.HasMX {
+	if result.DNS.Checked && !result.DNS.Value.HasMX && !result.DNS.Value.ImplicitMX {
 		report.Score = 1.0
 		report.RiskLevel = emailchecker.RiskLevelHigh
 		report.Reasons = append(report.Reasons, ReasonDomainCannotReceiveEmail)
@@ -73,28 +102,35 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 	suspicionLevel := 0
 	hasRandomPattern := false
 
+	mixedScriptLocalPart := result.Confusable.Checked && result.Confusable.Value.MixedScriptLocalPart
+	if mixedScriptLocalPart {
+		suspicionLevel++
+		report.Reasons = append(report.Reasons, ReasonMixedScriptLocalPart)
+	}
+
 	if result.Pattern.Checked {
 		pattern := result.Pattern.Value
 
 		if pattern.HasRandomPattern {
-			if isEducational {
-				report.Reasons = append(report.Reasons, ReasonEducationalInstitutionDomain)
+			if isInstitutional {
+				report.Reasons = append(report.Reasons, institutionReason)
 			} else {
 				hasRandomPattern = true
 				suspicionLevel++
 				report.Reasons = append(report.Reasons, ReasonSuspiciousEmailPatternDetected)
+				report.Reasons = append(report.Reasons, randomPatternReasons(pattern.Features)...)
 			}
 		}
 
 		if pattern.ShortLocalPart {
-			if !isEducational {
+			if !isInstitutional {
 				suspicionLevel++
 				report.Reasons = append(report.Reasons, ReasonShortLocalPart)
 			}
 		}
 
 		if pattern.TooManyConsecutiveNumbers {
-			if !isEducational {
+			if !isInstitutional {
 				suspicionLevel++
 				report.Reasons = append(report.Reasons, ReasonTooManyConsecutiveNumbers)
 			} else {
@@ -108,7 +144,7 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 		}
 
 		blockThreshold := 3
-		if isEducational {
+		if isInstitutional {
 			blockThreshold = 4
 		}
 
@@ -119,7 +155,7 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 			return report
 		}
 
-		if hasRandomPattern && !isEducational {
+		if hasRandomPattern && !isInstitutional {
 			report.Score = 0.8
 			report.RiskLevel = emailchecker.RiskLevelHigh
 
@@ -137,11 +173,11 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 	if result.Pattern.Checked {
 		pattern := result.Pattern.Value
 
-		if pattern.ShortLocalPart && !isEducational {
+		if pattern.ShortLocalPart && !isInstitutional {
 			patternScore += 0.2
 		}
 
-		if pattern.TooManyConsecutiveNumbers && !isEducational {
+		if pattern.TooManyConsecutiveNumbers && !isInstitutional {
 			patternScore += 0.2
 		}
 
@@ -150,26 +186,46 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 		}
 	}
 
+	if mixedScriptLocalPart {
+		patternScore += 0.3
+	}
+
 	domainScore := 0.0
 	if result.WellKnown.Checked {
 		if result.WellKnown.Value {
-			domainScore -= 0.15
-			report.Reasons = append(report.Reasons, ReasonWellKnownEmailProvider)
+			weight, reason := wellKnownWeight(result.WellKnownRank)
+			domainScore -= weight
+			report.Reasons = append(report.Reasons, reason)
 		} else {
 			domainScore += 0.25
 			report.Reasons = append(report.Reasons, ReasonUnknownEmailProvider)
 		}
 	}
 
-	if isEducational {
+	if isInstitutional {
 		domainScore -= 0.2
-		report.Reasons = append(report.Reasons, ReasonEducationalInstitutionDomain)
+		report.Reasons = append(report.Reasons, institutionReason)
+	}
+
+	if result.Confusable.Checked && result.Confusable.Value.ImitatedDomain != "" {
+		domainScore += imitationWeight(result.Confusable.Value.ImitatedRank)
+		report.Reasons = append(report.Reasons, ReasonConfusableDomain)
+	}
+
+	if result.Confusable.Checked && result.Confusable.Value.MixedScriptDomain {
+		domainScore += 0.3
+		report.Reasons = append(report.Reasons, ReasonMixedScriptDomain)
 	}
 
 	dnsScore := 0.0
 	if result.DNS.Checked {
 		dns := result.DNS.Value
 
+		if dns.ImplicitMX {
+			dnsScore += 0.2
+			report.Reasons = append(report.Reasons, ReasonImplicitMX)
+		}
+
 		if len(dns.MXRecords) == 1 {
 			dnsScore += 0.1
 			report.Reasons = append(report.Reasons, ReasonOnlyOneMXRecord)
@@ -210,3 +266,84 @@ func (a *Analyzer) Analyze(ctx context.Context, result *emailchecker.EmailCheckR
 
 	return report
 }
+
+// wellKnownWeight trusts a well-known domain by its rank tier. Unranked
+// domains, which are well-known by an override, get the weight of the old
+// flat rule.
+func wellKnownWeight(rank int) (float64, string) {
+	switch {
+	case rank <= 0:
+		return 0.15, ReasonWellKnownEmailProvider
+	case rank <= 1_000:
+		return 0.3, ReasonTop1KEmailProvider
+	case rank <= 10_000:
+		return 0.2, ReasonTop10KEmailProvider
+	case rank <= 100_000:
+		return 0.1, ReasonTop100KEmailProvider
+	case rank <= 1_000_000:
+		return 0.05, ReasonTop1MEmailProvider
+	default:
+		return 0.05, ReasonWellKnownEmailProvider
+	}
+}
+
+// imitationWeight weighs the imitation of a well-known domain by its rank
+// tier: the more visited the imitated domain, the likelier the spoofing.
+// Together with an unknown provider, imitating a top 1K domain is high risk.
+func imitationWeight(rank int) float64 {
+	switch {
+	case rank > 0 && rank <= 1_000:
+		return 0.6
+	case rank > 0 && rank <= 100_000:
+		return 0.5
+	default:
+		return 0.4
+	}
+}
+
+func disposableReason(reason emailchecker.DisposableReason) string {
+	switch reason {
+	case emailchecker.DisposableReasonDisposableMX:
+		return ReasonDisposableMailServers
+	case emailchecker.DisposableReasonDisposableNS:
+		return ReasonDisposableNameServers
+	case emailchecker.DisposableReasonRecentlyRegistered:
+		return ReasonRecentlyRegisteredDomain
+	case emailchecker.DisposableReasonSharedInfra:
+		return ReasonSharedDisposableInfrastructure
+	default:
+		return ReasonDisposableBlocked
+	}
+}
+
+// randomPatternReasons explains which features made a local part random.
+// Consecutive numbers and special chars have reasons of their own.
+func randomPatternReasons(f emailchecker.PatternFeatures) []string {
+	var reasons []string
+
+	if f.KeyboardSequence.Triggered {
+		reasons = append(reasons, ReasonKeyboardSequence)
+	}
+
+	if f.CaseSwitches.Triggered {
+		reasons = append(reasons, ReasonFrequentCaseSwitches)
+	}
+
+	if f.ScriptLetters.Triggered {
+		reasons = append(reasons, ReasonUnusualScript)
+	}
+
+	if f.Gibberish.Triggered {
+		reasons = append(reasons, ReasonGibberishLocalPart)
+	}
+
+	if f.NameLikeness.Triggered {
+		reasons = append(reasons, ReasonNotNameLike)
+
+		if f.Entropy.Triggered {
+			reasons = append(reasons, ReasonHighEntropyLocalPart)
+		}
+	}
+
+	return reasons
+}
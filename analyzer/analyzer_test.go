@@ -0,0 +1,144 @@
+package analyzer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emailchecker"
+	"emailchecker/analyzer"
+)
+
+func TestAnalyzer_WellKnownRank(t *testing.T) {
+	cases := []struct {
+		name    string
+		unknown bool
+		rank    int
+		weight  float64
+		reason  string
+	}{
+		{name: "Not well-known", unknown: true, weight: -0.25, reason: analyzer.ReasonUnknownEmailProvider},
+		// Domains well-known by an override have no rank.
+		{name: "Unranked", rank: 0, weight: 0.15, reason: analyzer.ReasonWellKnownEmailProvider},
+		{name: "Invalid rank", rank: -1, weight: 0.15, reason: analyzer.ReasonWellKnownEmailProvider},
+		{name: "First", rank: 1, weight: 0.3, reason: analyzer.ReasonTop1KEmailProvider},
+		{name: "Last of the top 1K", rank: 1_000, weight: 0.3, reason: analyzer.ReasonTop1KEmailProvider},
+		{name: "First of the top 10K", rank: 1_001, weight: 0.2, reason: analyzer.ReasonTop10KEmailProvider},
+		{name: "Last of the top 10K", rank: 10_000, weight: 0.2, reason: analyzer.ReasonTop10KEmailProvider},
+		{name: "First of the top 100K", rank: 10_001, weight: 0.1, reason: analyzer.ReasonTop100KEmailProvider},
+		{name: "Last of the top 100K", rank: 100_000, weight: 0.1, reason: analyzer.ReasonTop100KEmailProvider},
+		{name: "First of the top 1M", rank: 100_001, weight: 0.05, reason: analyzer.ReasonTop1MEmailProvider},
+		{name: "Last of the top 1M", rank: 1_000_000, weight: 0.05, reason: analyzer.ReasonTop1MEmailProvider},
+		{name: "Beyond the top 1M", rank: 1_000_001, weight: 0.05, reason: analyzer.ReasonWellKnownEmailProvider},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := &emailchecker.EmailCheckResult{
+				WellKnown:     emailchecker.SubCheckResult[bool]{Checked: true, Value: !tc.unknown},
+				WellKnownRank: tc.rank,
+				// A single implicit MX without SPF and DMARC scores 0.5, so
+				// that the weight is not hidden by the floor of the score.
+				DNS: emailchecker.SubCheckResult[emailchecker.DNSValidationResult]{
+					Checked: true,
+					Value: emailchecker.DNSValidationResult{
+						ImplicitMX: true,
+						MXRecords:  []emailchecker.MXRecord{{Value: "example.com"}},
+					},
+				},
+			}
+
+			report := analyzer.New().Analyze(context.Background(), result)
+
+			assert.InDelta(t, 0.5-tc.weight, report.Score, 1e-9)
+			assert.Contains(t, report.Reasons, tc.reason)
+		})
+	}
+}
+
+func TestAnalyzer_Sector(t *testing.T) {
+	government := emailchecker.SectorCheckResult{Sector: emailchecker.SectorGovernment, MatchedRule: "gov.{cc}"}
+
+	cases := []struct {
+		name        string
+		sector      emailchecker.SectorCheckResult
+		educational bool
+		pattern     emailchecker.EmailPatternCheckResult
+		score       float64
+		reasons     []string
+		notReasons  []string
+	}{
+		{
+			name:    "Public sector",
+			sector:  government,
+			score:   0.3,
+			reasons: []string{analyzer.ReasonPublicSectorDomain},
+		},
+		{
+			name:       "Unclassified",
+			score:      0.5,
+			notReasons: []string{analyzer.ReasonPublicSectorDomain},
+		},
+		{
+			name:       "Staff ID on a public-sector domain",
+			sector:     government,
+			pattern:    emailchecker.EmailPatternCheckResult{TooManyConsecutiveNumbers: true, ShortLocalPart: true},
+			score:      0.3,
+			reasons:    []string{analyzer.ReasonStudentIDStaffIDPatternDetected},
+			notReasons: []string{analyzer.ReasonTooManyConsecutiveNumbers, analyzer.ReasonShortLocalPart},
+		},
+		{
+			name:    "Staff ID on an unclassified domain",
+			pattern: emailchecker.EmailPatternCheckResult{TooManyConsecutiveNumbers: true, ShortLocalPart: true},
+			score:   0.9,
+			reasons: []string{analyzer.ReasonTooManyConsecutiveNumbers, analyzer.ReasonShortLocalPart},
+		},
+		{
+			name:       "Random local part on a public-sector domain",
+			sector:     government,
+			pattern:    emailchecker.EmailPatternCheckResult{HasRandomPattern: true},
+			score:      0.3,
+			reasons:    []string{analyzer.ReasonPublicSectorDomain},
+			notReasons: []string{analyzer.ReasonSuspiciousEmailPatternDetected},
+		},
+		{
+			name:        "Educational and public sector",
+			sector:      government,
+			educational: true,
+			score:       0.3,
+			reasons:     []string{analyzer.ReasonEducationalInstitutionDomain},
+			notReasons:  []string{analyzer.ReasonPublicSectorDomain},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := &emailchecker.EmailCheckResult{
+				Sector:      emailchecker.SubCheckResult[emailchecker.SectorCheckResult]{Checked: true, Value: tc.sector},
+				Educational: emailchecker.SubCheckResult[emailchecker.EducationalCheckResult]{Checked: true, Value: emailchecker.EducationalCheckResult{Educational: tc.educational}},
+				Pattern:     emailchecker.SubCheckResult[emailchecker.EmailPatternCheckResult]{Checked: true, Value: tc.pattern},
+				// A single implicit MX without SPF and DMARC scores 0.5.
+				DNS: emailchecker.SubCheckResult[emailchecker.DNSValidationResult]{
+					Checked: true,
+					Value: emailchecker.DNSValidationResult{
+						ImplicitMX: true,
+						MXRecords:  []emailchecker.MXRecord{{Value: "example.com"}},
+					},
+				},
+			}
+
+			report := analyzer.New().Analyze(context.Background(), result)
+
+			assert.InDelta(t, tc.score, report.Score, 1e-9)
+
+			for _, reason := range tc.reasons {
+				assert.Contains(t, report.Reasons, reason)
+			}
+
+			for _, reason := range tc.notReasons {
+				assert.NotContains(t, report.Reasons, reason)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 type Server struct {
 	opsHandler   *handlers.OpsHandler
 	checkHandler *handlers.CheckHandler
+	adminHandler *handlers.AdminHandler
 
 	httpServer *httpext.HTTPServer
 	router     chi.Router
@@ -34,8 +35,9 @@ func NewServer(checker *emailchecker.EmailChecker, opts ...httpext.Option) *Serv
 	ans := Server{
 		router: chi.NewRouter(),
 
-		opsHandler:   handlers.NewOpsHandler(),
+		opsHandler:   handlers.NewOpsHandler(checker),
 		checkHandler: handlers.NewCheckHandler(checker),
+		adminHandler: handlers.NewAdminHandler(checker),
 	}
 
 	ans.setupRoutes()
@@ -53,8 +55,14 @@ func NewServer(checker *emailchecker.EmailChecker, opts ...httpext.Option) *Serv
 	return &ans
 }
 
+// Run serves until ctx ends, then waits for the background work started by
+// admin requests, so that it does not outlive the server.
 func (s *Server) Run(ctx context.Context) error {
-	return s.httpServer.Run(ctx)
+	err := s.httpServer.Run(ctx)
+
+	s.adminHandler.Shutdown()
+
+	return err
 }
 
 func (s *Server) setupRoutes() {
@@ -68,8 +76,33 @@ func (s *Server) setupRoutes() {
 	s.router.MethodNotAllowed(httpmiddleware.Handler(s.opsHandler.MethodNotAllowed))
 
 	s.router.Get("/health", httpmiddleware.Handler(s.opsHandler.Health))
+	s.router.Get("/health/ready", httpmiddleware.Handler(s.opsHandler.Ready))
+	s.router.Get("/version", httpmiddleware.Handler(s.opsHandler.Version))
 	s.router.Get("/check/{email}", httpmiddleware.Handler(s.checkHandler.CheckEmail))
 
+	s.router.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.AdminAuth)
+
+		r.Get("/sources", httpmiddleware.Handler(s.adminHandler.Sources))
+
+		r.Get("/dns/stats", httpmiddleware.Handler(s.adminHandler.DNSCacheStats))
+		r.Post("/dns/prewarm", httpmiddleware.Handler(s.adminHandler.PrewarmDNSCache))
+		r.Delete("/dns/{domain}", httpmiddleware.Handler(s.adminHandler.PurgeDNSCache))
+		r.Post("/dns/{domain}/refresh", httpmiddleware.Handler(s.adminHandler.RefreshDNS))
+
+		r.Get("/overrides", httpmiddleware.Handler(s.adminHandler.ListOverrides))
+		r.Post("/overrides", httpmiddleware.Handler(s.adminHandler.AddOverride))
+		r.Delete("/overrides/{list}/{domain}", httpmiddleware.Handler(s.adminHandler.RemoveOverride))
+
+		r.Get("/providers", httpmiddleware.Handler(s.adminHandler.ListProviders))
+		r.Post("/providers", httpmiddleware.Handler(s.adminHandler.SetProvider))
+		r.Delete("/providers/{domain}", httpmiddleware.Handler(s.adminHandler.RemoveProvider))
+
+		r.Get("/candidates", httpmiddleware.Handler(s.adminHandler.ListCandidates))
+		r.Post("/candidates/{domain}/promote", httpmiddleware.Handler(s.adminHandler.PromoteCandidate))
+		r.Post("/candidates/{domain}/reject", httpmiddleware.Handler(s.adminHandler.RejectCandidate))
+	})
+
 	staticFS, err := fs.Sub(static.StaticFiles, "src")
 	if err != nil {
 		panic(err)
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"emailchecker"
+
+	"github.com/go-chi/chi/v5"
+
+	"emailchecker/pkg/errorsext"
+	"emailchecker/pkg/httpext"
+	"emailchecker/pkg/log"
+)
+
+const (
+	maxPrewarmDomains     = 10000
+	maxPrewarmConcurrency = 50
+)
+
+type AdminHandler struct {
+	checker *emailchecker.EmailChecker
+
+	// Work that outlives its request, such as a prewarm, runs under ctx and
+	// is waited for by Shutdown.
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	closed bool
+	tasks  sync.WaitGroup
+}
+
+func NewAdminHandler(checker *emailchecker.EmailChecker) *AdminHandler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &AdminHandler{
+		checker: checker,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Shutdown cancels the background work started by requests and waits for
+// it to return. No work is started afterwards.
+func (h *AdminHandler) Shutdown() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	h.cancel()
+	h.tasks.Wait()
+}
+
+// goBackground runs fn in the background with the values of the request
+// context, until timeout or Shutdown. It reports false after Shutdown.
+func (h *AdminHandler) goBackground(r *http.Request, timeout time.Duration, fn func(ctx context.Context)) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), timeout)
+	stop := context.AfterFunc(h.ctx, cancel)
+
+	h.tasks.Add(1)
+
+	go func() {
+		defer h.tasks.Done()
+		defer stop()
+		defer cancel()
+
+		fn(ctx)
+	}()
+
+	return true
+}
+
+func (h *AdminHandler) DNSCacheStats(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	stats, err := h.checker.DNSCacheStats(r.Context())
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to get DNS cache stats", err)
+	}
+
+	return stats, nil
+}
+
+// Sources reports the data sources of every domain list and the outcome of
+// their last refreshes.
+func (h *AdminHandler) Sources(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	sources, err := h.checker.Sources(r.Context())
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to get data sources", err)
+	}
+
+	return map[string]any{"lists": sources}, nil
+}
+
+func (h *AdminHandler) PurgeDNSCache(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	domain, aerr := domainParam(r)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	includeSubdomains, _ := strconv.ParseBool(r.URL.Query().Get("suffix"))
+
+	deleted, err := h.checker.PurgeDNSCache(r.Context(), domain, includeSubdomains)
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to purge DNS cache", err)
+	}
+
+	return map[string]any{"domain": domain, "deleted": deleted}, nil
+}
+
+func (h *AdminHandler) RefreshDNS(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	domain, aerr := domainParam(r)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	result, err := h.checker.RefreshDNS(r.Context(), domain)
+	if err != nil {
+		return nil, errorsext.BadGateway("Failed to resolve domain: " + err.Error())
+	}
+
+	return result, nil
+}
+
+type prewarmRequest struct {
+	Domains     []string `json:"domains"`
+	Concurrency int      `json:"concurrency"`
+}
+
+// PrewarmDNSCache queues the domains for resolution and returns immediately,
+// since a large batch easily outlives the server write timeout.
+func (h *AdminHandler) PrewarmDNSCache(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	var req prewarmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorsext.BadRequest("Invalid request body")
+	}
+
+	var verrs errorsext.ValidationErrors
+	if len(req.Domains) == 0 {
+		verrs.AddError("domains", "at least one domain is required")
+	}
+
+	if len(req.Domains) > maxPrewarmDomains {
+		verrs.AddError("domains", "too many domains, max is "+strconv.Itoa(maxPrewarmDomains))
+	}
+
+	if req.Concurrency < 0 || req.Concurrency > maxPrewarmConcurrency {
+		verrs.AddError("concurrency", "must be between 0 and "+strconv.Itoa(maxPrewarmConcurrency))
+	}
+
+	if verrs.HasErrors() {
+		return nil, errorsext.UnprocessableEntity("Invalid prewarm request", &verrs)
+	}
+
+	started := h.goBackground(r, time.Hour, func(ctx context.Context) {
+		result := h.checker.PrewarmDNSCache(ctx, req.Domains, req.Concurrency)
+
+		log.Info(ctx, "DNS prewarm finished", "total", result.Total, "resolved", result.Resolved, "failed", result.Failed)
+	})
+	if !started {
+		return nil, errorsext.ServiceUnavailable("The server is shutting down")
+	}
+
+	httpext.SetStatusCode(r, http.StatusAccepted)
+
+	return map[string]any{"queued": len(req.Domains)}, nil
+}
+
+func (h *AdminHandler) ListOverrides(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	var list emailchecker.ListName
+
+	if v := r.URL.Query().Get("list"); v != "" {
+		var err error
+
+		list, err = emailchecker.ParseListName(v)
+		if err != nil {
+			return nil, errorsext.BadRequest(err.Error())
+		}
+	}
+
+	overrides, err := h.checker.ListOverrides(r.Context(), list)
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to list overrides", err)
+	}
+
+	return overrides, nil
+}
+
+func (h *AdminHandler) AddOverride(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	var req emailchecker.DomainOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorsext.BadRequest("Invalid request body")
+	}
+
+	o, err := h.checker.AddOverride(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, emailchecker.ErrInvalidOverride) {
+			return nil, errorsext.BadRequest(err.Error())
+		}
+
+		return nil, errorsext.InternalServerError("Failed to add override", err)
+	}
+
+	httpext.SetStatusCode(r, http.StatusCreated)
+
+	return o, nil
+}
+
+func (h *AdminHandler) RemoveOverride(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	list, err := emailchecker.ParseListName(chi.URLParam(r, "list"))
+	if err != nil {
+		return nil, errorsext.BadRequest(err.Error())
+	}
+
+	domain, aerr := domainParam(r)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	removed, err := h.checker.RemoveOverride(r.Context(), list, domain)
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to remove override", err)
+	}
+
+	if !removed {
+		return nil, errorsext.NotFound("override not found")
+	}
+
+	httpext.SetStatusCode(r, http.StatusNoContent)
+
+	return nil, nil
+}
+
+func (h *AdminHandler) ListProviders(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	var t emailchecker.ProviderType
+
+	if v := r.URL.Query().Get("type"); v != "" {
+		var err error
+
+		t, err = emailchecker.ParseProviderType(v)
+		if err != nil {
+			return nil, errorsext.BadRequest(err.Error())
+		}
+	}
+
+	providers, err := h.checker.ListProviders(r.Context(), t)
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to list providers", err)
+	}
+
+	return providers, nil
+}
+
+func (h *AdminHandler) SetProvider(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	var req emailchecker.EmailProvider
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorsext.BadRequest("Invalid request body")
+	}
+
+	p, err := h.checker.SetProvider(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, emailchecker.ErrInvalidProvider) {
+			return nil, errorsext.BadRequest(err.Error())
+		}
+
+		return nil, errorsext.InternalServerError("Failed to set provider", err)
+	}
+
+	return p, nil
+}
+
+func (h *AdminHandler) RemoveProvider(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	domain, aerr := domainParam(r)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	removed, err := h.checker.RemoveProvider(r.Context(), domain)
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to remove provider", err)
+	}
+
+	if !removed {
+		return nil, errorsext.NotFound("provider not found")
+	}
+
+	httpext.SetStatusCode(r, http.StatusNoContent)
+
+	return nil, nil
+}
+
+func (h *AdminHandler) ListCandidates(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	var status emailchecker.CandidateStatus
+
+	if v := r.URL.Query().Get("status"); v != "" {
+		var err error
+
+		status, err = emailchecker.ParseCandidateStatus(v)
+		if err != nil {
+			return nil, errorsext.BadRequest(err.Error())
+		}
+	}
+
+	candidates, err := h.checker.ListCandidates(r.Context(), status)
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to list candidates", err)
+	}
+
+	return candidates, nil
+}
+
+type reviewCandidateRequest struct {
+	Reviewer string `json:"reviewer"`
+}
+
+func (h *AdminHandler) PromoteCandidate(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	return h.reviewCandidate(r, h.checker.PromoteCandidate)
+}
+
+func (h *AdminHandler) RejectCandidate(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	return h.reviewCandidate(r, h.checker.RejectCandidate)
+}
+
+func (h *AdminHandler) reviewCandidate(r *http.Request, review func(ctx context.Context, domain, reviewer string) (*emailchecker.DisposableCandidate, error)) (any, *errorsext.APIError) {
+	domain, aerr := domainParam(r)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	var req reviewCandidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorsext.BadRequest("Invalid request body")
+	}
+
+	candidate, err := review(r.Context(), domain, req.Reviewer)
+	if err != nil {
+		if errors.Is(err, emailchecker.ErrInvalidReview) || errors.Is(err, emailchecker.ErrInvalidOverride) {
+			return nil, errorsext.BadRequest(err.Error())
+		}
+
+		return nil, errorsext.InternalServerError("Failed to review candidate", err)
+	}
+
+	if candidate == nil {
+		return nil, errorsext.NotFound("candidate not found")
+	}
+
+	return candidate, nil
+}
+
+func domainParam(r *http.Request) (string, *errorsext.APIError) {
+	domain, err := url.QueryUnescape(chi.URLParam(r, "domain"))
+	if err != nil || domain == "" {
+		return "", errorsext.BadRequest("Invalid domain parameter")
+	}
+
+	return domain, nil
+}
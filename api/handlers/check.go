@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"emailchecker"
 
@@ -27,8 +29,26 @@ func (h *CheckHandler) CheckEmail(_ http.ResponseWriter, r *http.Request) (any,
 		return nil, errorsext.BadRequest("Invalid email parameter: failed to decode URL")
 	}
 
+	query := r.URL.Query()
+	debug, _ := strconv.ParseBool(query.Get("debug"))
+	strict, _ := strconv.ParseBool(query.Get("strict"))
+
 	params := emailchecker.EmailCheckParams{
-		Email: email,
+		Email:            email,
+		Debug:            debug,
+		DisposableStrict: strict,
+	}
+
+	// Pattern thresholds are overridden with pattern.<name>=<value>.
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, "pattern.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if err := params.PatternOverrides.Set(name, values[0]); err != nil {
+			return nil, errorsext.BadRequest(err.Error())
+		}
 	}
 
 	result, err := h.checker.Check(r.Context(), params)
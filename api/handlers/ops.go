@@ -3,14 +3,19 @@ package handlers
 import (
 	"net/http"
 
+	"emailchecker"
 	"emailchecker/pkg/errorsext"
 	"emailchecker/pkg/httpext"
 )
 
-type OpsHandler struct{}
+type OpsHandler struct {
+	checker *emailchecker.EmailChecker
+}
 
-func NewOpsHandler() *OpsHandler {
-	return &OpsHandler{}
+func NewOpsHandler(checker *emailchecker.EmailChecker) *OpsHandler {
+	return &OpsHandler{
+		checker: checker,
+	}
 }
 
 func (h *OpsHandler) Health(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
@@ -19,6 +24,31 @@ func (h *OpsHandler) Health(_ http.ResponseWriter, r *http.Request) (any, *error
 	return nil, nil
 }
 
+// Ready reports the state of every domain list. It answers 503 until all
+// lists hold fresh data, so that load balancers skip the instance.
+func (h *OpsHandler) Ready(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	readiness, err := h.checker.Readiness(r.Context())
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to get readiness", err)
+	}
+
+	if !readiness.Ready {
+		httpext.SetStatusCode(r, http.StatusServiceUnavailable)
+	}
+
+	return readiness, nil
+}
+
+// Version reports the data version of every domain list.
+func (h *OpsHandler) Version(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
+	versions, err := h.checker.ListVersions(r.Context())
+	if err != nil {
+		return nil, errorsext.InternalServerError("Failed to get list versions", err)
+	}
+
+	return map[string]any{"lists": versions}, nil
+}
+
 func (h *OpsHandler) NotFound(_ http.ResponseWriter, r *http.Request) (any, *errorsext.APIError) {
 	httpext.SetStatusCode(r, http.StatusNotFound)
 
@@ -2,12 +2,17 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,13 +22,20 @@ import (
 	"emailchecker"
 	"emailchecker/analyzer"
 	"emailchecker/api"
+	"emailchecker/confusable"
 	"emailchecker/disposable"
 	"emailchecker/dns"
 	"emailchecker/edu"
 	"emailchecker/emailpattern"
+	"emailchecker/infra"
+	"emailchecker/listimport"
+	"emailchecker/namemodel"
 	"emailchecker/pkg/app"
 	"emailchecker/pkg/httpext"
 	"emailchecker/pkg/log"
+	"emailchecker/provider"
+	"emailchecker/rdap"
+	"emailchecker/sector"
 	"emailchecker/sqlite"
 	"emailchecker/wellknown"
 )
@@ -48,6 +60,14 @@ func main() {
 						Aliases: []string{"s"},
 						Usage:   "Read emails from stdin (one per line)",
 					},
+					&cli.BoolFlag{
+						Name:  "trace",
+						Usage: "Include a trace of every DNS query in the results",
+					},
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "Use strict disposable detection (disposable MX/NS hosts, recently registered domains)",
+					},
 				},
 				Action: checkEmails,
 			},
@@ -75,8 +95,291 @@ func main() {
 				Name:    "update",
 				Aliases: []string{"u"},
 				Usage:   "Update database ",
-				Flags:   []cli.Flag{},
-				Action:  updateDatabase,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Refresh the lists even when their data is not due",
+					},
+					&cli.StringFlag{
+						Name:  "only",
+						Usage: "Comma-separated lists to update: disposable, top or edu",
+					},
+				},
+				Action: updateDatabase,
+			},
+			{
+				Name:  "import",
+				Usage: "Load a domain list from a local file",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "list",
+						Usage:    "List to import: disposable, top, edu or parked",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Usage:    "File to import",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "File format: txt, csv or json (default: from the file extension)",
+					},
+					&cli.StringFlag{
+						Name:  "mode",
+						Usage: "replace the list or merge the file into it",
+						Value: string(emailchecker.ImportReplace),
+					},
+				},
+				Action: importList,
+			},
+			{
+				Name:   "sources",
+				Usage:  "Show the data sources of each list and the outcome of their last refreshes",
+				Action: listSources,
+			},
+			{
+				Name:  "train-name-model",
+				Usage: "Train the name model that scores how name-like local parts are",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "corpus",
+						Usage:    "Corpus of names; every run of letters is a name, lines starting with # are comments",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "out",
+						Aliases:  []string{"o"},
+						Usage:    "File to write the model to, loaded with NAME_MODEL_FILE",
+						Required: true,
+					},
+				},
+				Action: trainNameModel,
+			},
+			{
+				Name:  "overrides",
+				Usage: "Manage local overrides of the domain lists",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "add",
+						Usage:     "Force a domain onto (include) or off (exclude) a list",
+						ArgsUsage: "<domain>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "list",
+								Usage:    "List to override: disposable, top or edu",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "action",
+								Usage:    "include or exclude",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "reason",
+								Usage:    "Why the override is needed",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "author",
+								Usage:   "Who added the override",
+								EnvVars: []string{"USER"},
+							},
+						},
+						Action: addOverride,
+					},
+					{
+						Name:      "remove",
+						Usage:     "Remove the override of a domain",
+						ArgsUsage: "<domain>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "list",
+								Usage:    "List of the override: disposable, top or edu",
+								Required: true,
+							},
+						},
+						Action: removeOverride,
+					},
+					{
+						Name:  "list",
+						Usage: "List overrides",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "list",
+								Usage: "Only show overrides of this list",
+							},
+						},
+						Action: listOverrides,
+					},
+				},
+			},
+			{
+				Name:  "lists",
+				Usage: "Manage the domain lists",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "versions",
+						Usage:  "Show where the data of each list comes from and its date",
+						Action: listVersions,
+					},
+					{
+						Name:  "snapshots",
+						Usage: "List the snapshots kept before each list change",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "list",
+								Usage: "Only show snapshots of this list: disposable, top, edu or parked",
+							},
+						},
+						Action: listSnapshots,
+					},
+					{
+						Name:  "rollback",
+						Usage: "Restore a list from its latest snapshot",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "list",
+								Usage:    "List to roll back: disposable, top, edu or parked",
+								Required: true,
+							},
+						},
+						Action: rollbackList,
+					},
+				},
+			},
+			{
+				Name:  "candidates",
+				Usage: "Review domains that share infrastructure with disposable domains",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List candidates, highest confidence first",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "status",
+								Usage: "Only show candidates with this status: pending, promoted or rejected",
+							},
+						},
+						Action: listCandidates,
+					},
+					{
+						Name:      "promote",
+						Usage:     "Add a candidate to the disposable list as an override",
+						ArgsUsage: "<domain>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "author",
+								Usage:   "Who reviewed the candidate",
+								EnvVars: []string{"USER"},
+							},
+						},
+						Action: promoteCandidate,
+					},
+					{
+						Name:      "reject",
+						Usage:     "Mark a candidate as not disposable",
+						ArgsUsage: "<domain>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "author",
+								Usage:   "Who reviewed the candidate",
+								EnvVars: []string{"USER"},
+							},
+						},
+						Action: rejectCandidate,
+					},
+				},
+			},
+			{
+				Name:  "providers",
+				Usage: "Manage the email provider table",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List providers",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "type",
+								Usage: "Only show providers of this type: free_webmail, isp, privacy or corporate",
+							},
+						},
+						Action: listProviders,
+					},
+					{
+						Name:      "set",
+						Usage:     "Classify a domain, replacing the curated entry if any",
+						ArgsUsage: "<domain>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "type",
+								Usage:    "free_webmail, isp, privacy or corporate",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "author",
+								Usage:   "Who classified the domain",
+								EnvVars: []string{"USER"},
+							},
+						},
+						Action: setProvider,
+					},
+					{
+						Name:      "remove",
+						Usage:     "Remove the local entry of a domain",
+						ArgsUsage: "<domain>",
+						Action:    removeProvider,
+					},
+				},
+			},
+			{
+				Name:  "dns",
+				Usage: "Manage the DNS cache",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "stats",
+						Usage:  "Show DNS cache statistics",
+						Action: dnsCacheStats,
+					},
+					{
+						Name:      "purge",
+						Usage:     "Remove a domain from the DNS cache",
+						ArgsUsage: "<domain>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "suffix",
+								Usage: "Also remove every cached subdomain of the domain",
+							},
+						},
+						Action: dnsCachePurge,
+					},
+					{
+						Name:      "refresh",
+						Usage:     "Resolve a domain again, bypassing the DNS cache",
+						ArgsUsage: "<domain>",
+						Action:    dnsCacheRefresh,
+					},
+					{
+						Name:  "prewarm",
+						Usage: "Resolve domains from a file (one per line) into the DNS cache",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "file",
+								Aliases:  []string{"f"},
+								Usage:    "File with domains (one per line)",
+								Required: true,
+							},
+							&cli.IntFlag{
+								Name:    "concurrency",
+								Aliases: []string{"c"},
+								Value:   10,
+								Usage:   "Maximum number of concurrent lookups",
+							},
+						},
+						Action: dnsCachePrewarm,
+					},
+				},
 			},
 		},
 	}
@@ -114,14 +417,28 @@ func checkEmails(c *cli.Context) error {
 
 	ctx := context.Background()
 
+	// The CLI has no background updater, so bring the lists up to date
+	// first. Stored data is used when a list cannot be downloaded.
+	updateCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	if _, err := checker.UpdateDB(updateCtx, emailchecker.UpdateOptions{}); err != nil {
+		log.Warn(ctx, "Could not refresh all lists, using stored data", "error", err.Error())
+	}
+
+	base := emailchecker.EmailCheckParams{
+		Debug:            c.Bool("trace"),
+		DisposableStrict: c.Bool("strict"),
+	}
+
 	var results []emailchecker.EmailCheckResult
 	if c.String("file") != "" {
-		results, err = processEmailsConcurrently(ctx, checker, emails)
+		results, err = processEmailsConcurrently(ctx, checker, emails, base)
 		if err != nil {
 			return err
 		}
 	} else {
-		results, err = processEmailsSequentially(ctx, checker, emails)
+		results, err = processEmailsSequentially(ctx, checker, emails, base)
 		if err != nil {
 			return err
 		}
@@ -169,9 +486,8 @@ type dbUpdater struct {
 
 func (u *dbUpdater) Run(ctx context.Context) error {
 	log.Info(ctx, "Starting periodic database updater")
-	const interval = 13 * time.Hour
 
-	u.checker.PeriodicUpdate(ctx, interval)
+	u.checker.PeriodicUpdate(ctx)
 
 	log.Warn(ctx, "updater stopped")
 
@@ -188,9 +504,483 @@ func updateDatabase(c *cli.Context) error {
 	}
 	defer checker.Close() //nolint:errcheck
 
+	opts := emailchecker.UpdateOptions{
+		Force: c.Bool("force"),
+	}
+
+	if only := c.String("only"); only != "" {
+		for _, s := range strings.Split(only, ",") {
+			list, err := emailchecker.ParseListName(s)
+			if err != nil {
+				return err
+			}
+
+			opts.Lists = append(opts.Lists, list)
+		}
+	}
+
 	log.Info(ctx, "Starting database update")
 
-	return checker.UpdateDB(ctx)
+	results, err := checker.UpdateDB(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(results)
+}
+
+func dnsCacheStats(c *cli.Context) error {
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	stats, err := checker.DNSCacheStats(c.Context)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(stats)
+}
+
+func dnsCachePurge(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	deleted, err := checker.PurgeDNSCache(c.Context, c.Args().First(), c.Bool("suffix"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %d cached DNS record(s)\n", deleted)
+
+	return nil
+}
+
+func dnsCacheRefresh(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	result, err := checker.RefreshDNS(c.Context, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+func dnsCachePrewarm(c *cli.Context) error {
+	domains, err := readEmailsFromFile(c.String("file"))
+	if err != nil {
+		return fmt.Errorf("failed to read domains from file: %v", err)
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	result := checker.PrewarmDNSCache(c.Context, domains, c.Int("concurrency"))
+
+	return printJSON(result)
+}
+
+func addOverride(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	list, err := emailchecker.ParseListName(c.String("list"))
+	if err != nil {
+		return err
+	}
+
+	action, err := emailchecker.ParseOverrideAction(c.String("action"))
+	if err != nil {
+		return err
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	o, err := checker.AddOverride(c.Context, emailchecker.DomainOverride{
+		List:   list,
+		Domain: c.Args().First(),
+		Action: action,
+		Reason: c.String("reason"),
+		Author: c.String("author"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(o)
+}
+
+func removeOverride(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	list, err := emailchecker.ParseListName(c.String("list"))
+	if err != nil {
+		return err
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	removed, err := checker.RemoveOverride(c.Context, list, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return fmt.Errorf("no %s override found for %s", list, c.Args().First())
+	}
+
+	return nil
+}
+
+func listOverrides(c *cli.Context) error {
+	var list emailchecker.ListName
+
+	if c.String("list") != "" {
+		var err error
+
+		list, err = emailchecker.ParseListName(c.String("list"))
+		if err != nil {
+			return err
+		}
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	overrides, err := checker.ListOverrides(c.Context, list)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(overrides)
+}
+
+func listVersions(c *cli.Context) error {
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	versions, err := checker.ListVersions(c.Context)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(versions)
+}
+
+func listSources(c *cli.Context) error {
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	sources, err := checker.Sources(c.Context)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(sources)
+}
+
+func listSnapshots(c *cli.Context) error {
+	var list emailchecker.ListName
+
+	if c.String("list") != "" {
+		var err error
+
+		list, err = emailchecker.ParseListName(c.String("list"))
+		if err != nil {
+			return err
+		}
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	snapshots, err := checker.ListSnapshots(c.Context, list)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(snapshots)
+}
+
+func rollbackList(c *cli.Context) error {
+	list, err := emailchecker.ParseListName(c.String("list"))
+	if err != nil {
+		return err
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	result, err := checker.RollbackList(c.Context, list)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+func importList(c *cli.Context) error {
+	list, err := emailchecker.ParseListName(c.String("list"))
+	if err != nil {
+		return err
+	}
+
+	mode, err := emailchecker.ParseImportMode(c.String("mode"))
+	if err != nil {
+		return err
+	}
+
+	file := c.String("file")
+
+	format := emailchecker.ImportFormatOf(file)
+	if v := c.String("format"); v != "" {
+		if format, err = emailchecker.ParseImportFormat(v); err != nil {
+			return err
+		}
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read import file: %v", err)
+	}
+
+	data, err := listimport.Read(bytes.NewReader(raw), list, format)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	sum := sha256.Sum256(raw)
+
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+
+	result, err := checker.ImportList(c.Context, emailchecker.ListImport{
+		List:   list,
+		File:   file,
+		Format: format,
+		Mode:   mode,
+		SHA256: hex.EncodeToString(sum[:]),
+	}, data)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
+
+func trainNameModel(c *cli.Context) error {
+	corpus, err := os.Open(c.String("corpus"))
+	if err != nil {
+		return fmt.Errorf("failed to read corpus: %v", err)
+	}
+	defer corpus.Close() //nolint:errcheck
+
+	model, err := namemodel.Train(corpus)
+	if err != nil {
+		return fmt.Errorf("failed to train name model: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := model.Write(&buf); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.String("out"), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write name model: %v", err)
+	}
+
+	return printJSON(map[string]any{
+		"file":    c.String("out"),
+		"scripts": model.Scripts(),
+	})
+}
+
+func listCandidates(c *cli.Context) error {
+	var status emailchecker.CandidateStatus
+
+	if c.String("status") != "" {
+		var err error
+
+		status, err = emailchecker.ParseCandidateStatus(c.String("status"))
+		if err != nil {
+			return err
+		}
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	candidates, err := checker.ListCandidates(c.Context, status)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(candidates)
+}
+
+func promoteCandidate(c *cli.Context) error {
+	return reviewCandidate(c, (*emailchecker.EmailChecker).PromoteCandidate)
+}
+
+func rejectCandidate(c *cli.Context) error {
+	return reviewCandidate(c, (*emailchecker.EmailChecker).RejectCandidate)
+}
+
+func reviewCandidate(c *cli.Context, review func(*emailchecker.EmailChecker, context.Context, string, string) (*emailchecker.DisposableCandidate, error)) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	candidate, err := review(checker, c.Context, c.Args().First(), c.String("author"))
+	if err != nil {
+		return err
+	}
+
+	if candidate == nil {
+		return fmt.Errorf("no candidate found for %s", c.Args().First())
+	}
+
+	return printJSON(candidate)
+}
+
+func listProviders(c *cli.Context) error {
+	var t emailchecker.ProviderType
+
+	if c.String("type") != "" {
+		var err error
+
+		t, err = emailchecker.ParseProviderType(c.String("type"))
+		if err != nil {
+			return err
+		}
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	providers, err := checker.ListProviders(c.Context, t)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(providers)
+}
+
+func setProvider(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	t, err := emailchecker.ParseProviderType(c.String("type"))
+	if err != nil {
+		return err
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	p, err := checker.SetProvider(c.Context, emailchecker.EmailProvider{
+		Domain: c.Args().First(),
+		Type:   t,
+		Author: c.String("author"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(p)
+}
+
+func removeProvider(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("please provide exactly one domain")
+	}
+
+	checker, err := createChecker()
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %v", err)
+	}
+	defer checker.Close() //nolint:errcheck
+
+	removed, err := checker.RemoveProvider(c.Context, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return fmt.Errorf("no local provider entry found for %s", c.Args().First())
+	}
+
+	return nil
 }
 
 func createChecker() (*emailchecker.EmailChecker, error) {
@@ -199,7 +989,18 @@ func createChecker() (*emailchecker.EmailChecker, error) {
 		dbpath = "checker.db"
 	}
 
-	repo, err := sqlite.New(dbpath)
+	var repoOpts []sqlite.Option
+
+	if v := os.Getenv("LIST_SNAPSHOTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid LIST_SNAPSHOTS %q: expected a non-negative number", v)
+		}
+
+		repoOpts = append(repoOpts, sqlite.WithSnapshotRetention(n))
+	}
+
+	repo, err := sqlite.New(dbpath, repoOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -208,42 +1009,269 @@ func createChecker() (*emailchecker.EmailChecker, error) {
 		Timeout: 10 * time.Second,
 	}
 
-	disposableFetcher := disposable.NewGithubFetcher(netClient)
-	dnsChecker := dns.New(netClient)
+	disposableMode, err := matchModeFromEnv("DISPOSABLE_MATCH_MODE")
+	if err != nil {
+		return nil, err
+	}
+
+	wellKnownMode, err := matchModeFromEnv("WELLKNOWN_MATCH_MODE")
+	if err != nil {
+		return nil, err
+	}
+
+	eduMode, err := matchModeFromEnv("EDU_MATCH_MODE")
+	if err != nil {
+		return nil, err
+	}
+
+	providerMode, err := matchModeFromEnv("PROVIDER_MATCH_MODE")
+	if err != nil {
+		return nil, err
+	}
+
+	disposableSources, err := disposableSourcesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	disposableFetcher, err := disposable.NewSourceFetcher(netClient, repo.Validators(emailchecker.ListDisposable), disposableSources)
+	if err != nil {
+		return nil, err
+	}
+
+	dnsChecker := dns.New(netClient, repo)
 	dnsResolver := dns.NewResolver(dnsChecker, repo)
 
-	disposableSvc, err := disposable.New(repo, disposableFetcher)
+	disposableSvc, err := disposable.New(repo, disposableFetcher,
+		disposable.WithMatchMode(disposableMode),
+		disposable.WithGuard(disposable.GuardFor(disposableSources)),
+	)
 	if err != nil {
 		return nil, err
 	}
 
 	analyzerSvc := analyzer.New()
-	wellKnownFetcher := wellknown.NewTranco(netClient)
+	wellKnownFetcher, wellKnownGuard, err := topListFromEnv(netClient, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	welknownSvc, err := wellknown.New(repo, wellKnownFetcher,
+		wellknown.WithMatchMode(wellKnownMode), wellknown.WithGuard(wellKnownGuard))
+	if err != nil {
+		return nil, err
+	}
 
-	welknownSvc, err := wellknown.New(repo, wellKnownFetcher)
+	eduFetcher := edu.NewEduFetcher(netClient, repo.Validators(emailchecker.ListEducational))
+	eduChecker, err := edu.New(repo, eduFetcher, edu.WithMatchMode(eduMode))
 	if err != nil {
 		return nil, err
 	}
 
-	eduFetcher := edu.NewEduFetcher(netClient)
-	eduChecker, err := edu.New(repo, eduFetcher)
+	providerSvc, err := provider.New(context.Background(), repo, provider.WithMatchMode(providerMode))
 	if err != nil {
 		return nil, err
 	}
 
+	sectorRules := sector.DefaultRules()
+
+	if v := os.Getenv("SECTOR_RULES_FILE"); v != "" {
+		rules, err := sector.ReadRulesFile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SECTOR_RULES_FILE: %w", err)
+		}
+
+		sectorRules = append(sectorRules, rules...)
+	}
+
+	schedules, err := refreshSchedulesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	patternCfg, err := patternConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	confusables := confusable.DefaultTable()
+
+	if v := os.Getenv("CONFUSABLES_FILE"); v != "" {
+		confusables, err = confusable.ReadTableFile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CONFUSABLES_FILE: %w", err)
+		}
+	}
+
+	var patternOpts []emailpattern.Option
+
+	if v := os.Getenv("NAME_MODEL_FILE"); v != "" {
+		model, err := namemodel.Load(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NAME_MODEL_FILE: %w", err)
+		}
+
+		patternOpts = append(patternOpts, emailpattern.WithNameModel(model))
+	}
+
 	cfg := emailchecker.Config{
 		DisposableService:        disposableSvc,
 		DNSService:               dnsResolver,
 		AnalysisService:          analyzerSvc,
-		EmailPatternService:      emailpattern.New(),
+		EmailPatternService:      emailpattern.NewWithConfig(patternCfg, patternOpts...),
 		WellKnownService:         welknownSvc,
 		EducationalDomainService: eduChecker,
+		OverrideService:          repo,
+		DomainAgeService:         rdap.NewChecker(rdap.New(netClient), repo),
+		InfrastructureService:    infra.New(repo),
+		ListService:              repo,
+		ProviderService:          providerSvc,
+		SectorService:            sector.New(sectorRules),
+		ConfusableService:        confusable.New(confusables, welknownSvc),
+		RefreshSchedules:         schedules,
 	}
 
 	return emailchecker.New(&cfg)
 }
 
-func processEmailsSequentially(ctx context.Context, checker *emailchecker.EmailChecker, emails []string) ([]emailchecker.EmailCheckResult, error) {
+// disposableSourcesFromEnv reads the disposable blocklists from
+// DISPOSABLE_SOURCES and the allowlists from DISPOSABLE_ALLOWLISTS, both as
+// comma separated name=location pairs.
+func disposableSourcesFromEnv() ([]disposable.Source, error) {
+	sources := disposable.DefaultSources()
+
+	if v := os.Getenv("DISPOSABLE_SOURCES"); v != "" {
+		var err error
+
+		sources, err = disposable.ParseSources(v, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DISPOSABLE_SOURCES: %w", err)
+		}
+	}
+
+	allowlists, err := disposable.ParseSources(os.Getenv("DISPOSABLE_ALLOWLISTS"), true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DISPOSABLE_ALLOWLISTS: %w", err)
+	}
+
+	return append(sources, allowlists...), nil
+}
+
+// topListFromEnv reads the top sites list from TOP_LIST_SOURCE, a URL or a
+// local CSV file, or from Tranco when it is unset, together with the guard
+// of the source and size.
+func topListFromEnv(netClient *http.Client, repo *sqlite.Repository) (wellknown.Fetcher, emailchecker.ListGuard, error) {
+	size, err := positiveIntFromEnv("TOP_LIST_SIZE", wellknown.DefaultListSize)
+	if err != nil {
+		return nil, emailchecker.ListGuard{}, err
+	}
+
+	if location := os.Getenv("TOP_LIST_SOURCE"); location != "" {
+		fetcher := wellknown.NewCSVFetcher(netClient, repo.Validators(emailchecker.ListTop), location, size)
+
+		return fetcher, wellknown.CSVGuard(size), nil
+	}
+
+	fallbackDays, err := positiveIntFromEnv("TOP_LIST_FALLBACK_DAYS", wellknown.DefaultFallbackDays)
+	if err != nil {
+		return nil, emailchecker.ListGuard{}, err
+	}
+
+	tranco := wellknown.NewTranco(netClient, repo.Validators(emailchecker.ListTop),
+		wellknown.WithListSize(size), wellknown.WithFallbackDays(fallbackDays))
+
+	return tranco, wellknown.TrancoGuard(size), nil
+}
+
+// patternConfigFromEnv reads the pattern thresholds from the JSON file of
+// PATTERN_CONFIG_FILE, then from the PATTERN_<NAME> variables.
+func patternConfigFromEnv() (*emailpattern.Config, error) {
+	cfg := emailpattern.DefaultConfig()
+
+	if v := os.Getenv("PATTERN_CONFIG_FILE"); v != "" {
+		var err error
+		if cfg, err = emailpattern.LoadConfig(v); err != nil {
+			return nil, fmt.Errorf("invalid PATTERN_CONFIG_FILE: %w", err)
+		}
+	}
+
+	for _, name := range emailchecker.PatternConfigNames() {
+		key := "PATTERN_" + strings.ToUpper(name)
+		if v := os.Getenv(key); v != "" {
+			if err := cfg.Set(name, v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// refreshSchedulesFromEnv reads the <LIST>_REFRESH_INTERVAL and
+// <LIST>_REFRESH_JITTER durations of each list.
+func refreshSchedulesFromEnv() (map[emailchecker.ListName]emailchecker.RefreshSchedule, error) {
+	schedules := make(map[emailchecker.ListName]emailchecker.RefreshSchedule)
+
+	for _, list := range []emailchecker.ListName{emailchecker.ListDisposable, emailchecker.ListTop, emailchecker.ListEducational} {
+		prefix := strings.ToUpper(string(list))
+		schedule := emailchecker.DefaultRefreshSchedule
+
+		var err error
+
+		if schedule.Interval, err = durationFromEnv(prefix+"_REFRESH_INTERVAL", schedule.Interval); err != nil {
+			return nil, err
+		}
+
+		if schedule.Jitter, err = durationFromEnv(prefix+"_REFRESH_JITTER", schedule.Jitter); err != nil {
+			return nil, err
+		}
+
+		schedules[list] = schedule
+	}
+
+	return schedules, nil
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a duration such as 6h", key, v)
+	}
+
+	return d, nil
+}
+
+func positiveIntFromEnv(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a positive number", key, v)
+	}
+
+	return n, nil
+}
+
+func matchModeFromEnv(key string) (emailchecker.MatchMode, error) {
+	value := os.Getenv(key)
+
+	mode, ok := emailchecker.ParseMatchMode(value)
+	if !ok {
+		return mode, fmt.Errorf("invalid %s %q: expected suffix, organizational or exact", key, value)
+	}
+
+	return mode, nil
+}
+
+func processEmailsSequentially(ctx context.Context, checker *emailchecker.EmailChecker, emails []string, base emailchecker.EmailCheckParams) ([]emailchecker.EmailCheckResult, error) {
 	var results []emailchecker.EmailCheckResult
 
 	for _, email := range emails {
@@ -252,9 +1280,8 @@ func processEmailsSequentially(ctx context.Context, checker *emailchecker.EmailC
 			continue
 		}
 
-		params := emailchecker.EmailCheckParams{
-			Email: email,
-		}
+		params := base
+		params.Email = email
 
 		result, err := checker.Check(ctx, params)
 		if err != nil {
@@ -267,7 +1294,7 @@ func processEmailsSequentially(ctx context.Context, checker *emailchecker.EmailC
 	return results, nil
 }
 
-func processEmailsConcurrently(ctx context.Context, checker *emailchecker.EmailChecker, emails []string) ([]emailchecker.EmailCheckResult, error) {
+func processEmailsConcurrently(ctx context.Context, checker *emailchecker.EmailChecker, emails []string, base emailchecker.EmailCheckParams) ([]emailchecker.EmailCheckResult, error) {
 	const maxGoroutines = 100
 
 	var validEmails []string
@@ -301,9 +1328,8 @@ func processEmailsConcurrently(ctx context.Context, checker *emailchecker.EmailC
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			params := emailchecker.EmailCheckParams{
-				Email: emailAddr,
-			}
+			params := base
+			params.Email = emailAddr
 
 			result, err := checker.Check(ctx, params)
 
@@ -338,6 +1364,17 @@ func processEmailsConcurrently(ctx context.Context, checker *emailchecker.EmailC
 	return results, nil
 }
 
+func printJSON(v any) error {
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %v", err)
+	}
+
+	fmt.Println(string(output))
+
+	return nil
+}
+
 func readEmailsFromFile(filename string) ([]string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -0,0 +1,122 @@
+// Command listdata-gen downloads the reference lists and writes the snapshot
+// embedded by the listdata package.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"emailchecker/disposable"
+	"emailchecker/edu"
+	"emailchecker/listdata"
+	"emailchecker/pkg/httpext"
+	"emailchecker/wellknown"
+)
+
+// noValidators makes every download unconditional.
+type noValidators struct{}
+
+func (noValidators) GetValidators(context.Context, string) (httpext.Validators, error) {
+	return httpext.Validators{}, nil
+}
+
+func (noValidators) SetValidators(context.Context, string, httpext.Validators) error {
+	return nil
+}
+
+func main() {
+	out := flag.String("out", ".", "Directory to write the snapshot to")
+	topSize := flag.Int("top", 100000, "Number of top domains to keep")
+	flag.Parse()
+
+	if err := run(*out, *topSize); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, topSize int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client := &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+
+	disposableFetcher, err := disposable.NewSourceFetcher(client, noValidators{}, disposable.DefaultSources())
+	if err != nil {
+		return err
+	}
+
+	disposableDomains, err := disposableFetcher.FetchDisposableDomains(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch disposable domains: %w", err)
+	}
+
+	topDomains, err := wellknown.NewTranco(client, noValidators{}, wellknown.WithListSize(topSize)).GetTopList(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch top domains: %w", err)
+	}
+
+	eduDomains, err := edu.NewEduFetcher(client, noValidators{}).FetchEducationalDomains(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch educational domains: %w", err)
+	}
+
+	// An empty snapshot would leave a new database with empty lists.
+	if len(disposableDomains) == 0 || len(topDomains) == 0 || len(eduDomains) == 0 {
+		return fmt.Errorf("refusing to write an empty snapshot: %d disposable, %d top and %d educational domains",
+			len(disposableDomains), len(topDomains), len(eduDomains))
+	}
+
+	// The version is written last, so that a failed run never labels
+	// partial data with a new date.
+	var buf bytes.Buffer
+
+	if err := listdata.WriteDisposable(&buf, disposableDomains); err != nil {
+		return err
+	}
+
+	if err := writeFile(out, listdata.DisposableFile, &buf); err != nil {
+		return err
+	}
+
+	if err := listdata.WriteTop(&buf, topDomains); err != nil {
+		return err
+	}
+
+	if err := writeFile(out, listdata.TopFile, &buf); err != nil {
+		return err
+	}
+
+	if err := listdata.WriteEducational(&buf, eduDomains); err != nil {
+		return err
+	}
+
+	if err := writeFile(out, listdata.EduFile, &buf); err != nil {
+		return err
+	}
+
+	buf.WriteString(time.Now().UTC().Format(time.DateOnly) + "\n")
+
+	if err := writeFile(out, listdata.VersionFile, &buf); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d disposable, %d top and %d educational domains\n",
+		len(disposableDomains), len(topDomains), len(eduDomains))
+
+	return nil
+}
+
+func writeFile(dir, name string, buf *bytes.Buffer) error {
+	defer buf.Reset()
+
+	return os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644)
+}
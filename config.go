@@ -9,6 +9,16 @@ type Config struct {
 	EducationalDomainService EducationalDomainChecker
 	EmailPatternService      EmailPatternChecker
 	AnalysisService          Analyzer
+	OverrideService          OverrideStore
+	DomainAgeService         DomainAgeChecker
+	InfrastructureService    InfrastructureLearner
+	ListService              ListStore
+	ProviderService          ProviderClassifier
+	SectorService            SectorClassifier
+	ConfusableService        ConfusableChecker
+	// RefreshSchedules sets the refresh schedule of each list. Lists that are
+	// not set use DefaultRefreshSchedule.
+	RefreshSchedules map[ListName]RefreshSchedule
 }
 
 func (c *Config) Validate() error {
@@ -40,5 +50,47 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("%w: analysis service is required", ErrInvalidConfig)
 	}
 
+	if c.OverrideService == nil {
+		return fmt.Errorf("%w: override service is required", ErrInvalidConfig)
+	}
+
+	if c.DomainAgeService == nil {
+		return fmt.Errorf("%w: domain age service is required", ErrInvalidConfig)
+	}
+
+	if c.InfrastructureService == nil {
+		return fmt.Errorf("%w: infrastructure service is required", ErrInvalidConfig)
+	}
+
+	if c.ListService == nil {
+		return fmt.Errorf("%w: list service is required", ErrInvalidConfig)
+	}
+
+	if c.ProviderService == nil {
+		return fmt.Errorf("%w: provider service is required", ErrInvalidConfig)
+	}
+
+	if c.SectorService == nil {
+		return fmt.Errorf("%w: sector service is required", ErrInvalidConfig)
+	}
+
+	if c.ConfusableService == nil {
+		return fmt.Errorf("%w: confusable service is required", ErrInvalidConfig)
+	}
+
+	for list, schedule := range c.RefreshSchedules {
+		if _, err := ParseListName(string(list)); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+
+		if !list.downloaded() {
+			return fmt.Errorf("%w: the %s list is not downloaded and has no refresh schedule", ErrInvalidConfig, list)
+		}
+
+		if err := schedule.Validate(); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, list, err)
+		}
+	}
+
 	return nil
 }
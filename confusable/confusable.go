@@ -0,0 +1,377 @@
+// Package confusable detects addresses that imitate others with characters
+// that look alike, following Unicode Technical Standard #39: a domain whose
+// skeleton is the skeleton of a well-known domain imitates it, and a label or
+// local part that mixes scripts is suspicious.
+package confusable
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+
+	"emailchecker"
+	"emailchecker/pkg/unicodeext"
+)
+
+//go:embed confusables.txt
+var defaultTable []byte
+
+// maxASCIIImitatedRank is the lowest rank a plain ASCII name can imitate.
+const maxASCIIImitatedRank = 1_000
+
+// indexCheckInterval is how often the version of the well-known list is
+// checked for changes that require building the skeleton index again.
+const indexCheckInterval = time.Minute
+
+// scriptGroups are the scripts that are written together, so that a
+// Japanese or Korean name does not count as mixed.
+var scriptGroups = [][]string{
+	{"Han", "Hiragana", "Katakana"},
+	{"Han", "Hangul"},
+	{"Han", "Bopomofo"},
+}
+
+// Table maps characters to the prototype they are confusable with.
+type Table map[rune]string
+
+// DefaultTable returns the table shipped with the binary, a subset of the
+// Unicode confusables.
+func DefaultTable() Table {
+	t, err := ParseTable(bytes.NewReader(defaultTable))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded confusables: %v", err))
+	}
+
+	return t
+}
+
+// ParseTable reads a table in the format of the Unicode confusables.txt.
+func ParseTable(r io.Reader) (Table, error) {
+	t := make(Table)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimPrefix(strings.TrimSpace(line), "\ufeff")
+
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid confusable on line %d: %q", n, line)
+		}
+
+		source, err := parseCodePoints(fields[0])
+		if err != nil || len(source) != 1 {
+			return nil, fmt.Errorf("invalid confusable source on line %d: %q", n, fields[0])
+		}
+
+		target, err := parseCodePoints(fields[1])
+		if err != nil || len(target) == 0 {
+			return nil, fmt.Errorf("invalid confusable target on line %d: %q", n, fields[1])
+		}
+
+		t[source[0]] = string(target)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// ReadTableFile reads the table of a local file, such as the full Unicode
+// confusables.txt.
+func ReadTableFile(path string) (Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	t, err := ParseTable(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return t, nil
+}
+
+func parseCodePoints(s string) ([]rune, error) {
+	var ans []rune
+
+	for _, field := range strings.Fields(s) {
+		n, err := strconv.ParseUint(field, 16, 32)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, rune(n))
+	}
+
+	return ans, nil
+}
+
+// Skeleton returns the skeleton of s, lowercased: strings that look alike
+// share their skeleton.
+func (t Table) Skeleton(s string) string {
+	var b strings.Builder
+
+	for _, r := range norm.NFD.String(s) {
+		if prototype, ok := t[r]; ok {
+			b.WriteString(prototype)
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return strings.ToLower(norm.NFD.String(b.String()))
+}
+
+// spoofs tells whether s has a non-ASCII character that is confusable with
+// another.
+func (t Table) spoofs(s string) bool {
+	for _, r := range norm.NFD.String(s) {
+		if _, ok := t[r]; ok && r > unicode.MaxASCII {
+			return true
+		}
+	}
+
+	return false
+}
+
+type wellKnownChecker interface {
+	IsWellKnown(ctx context.Context, domain emailchecker.Domain) (bool, int, error)
+	// Domains returns the well-known domains in rank order.
+	Domains(ctx context.Context) ([]emailchecker.TopDomain, error)
+	// Version changes whenever the well-known list does.
+	Version(ctx context.Context) (string, error)
+}
+
+// skeletonIndex maps the skeletons of the well-known domains to the best
+// ranked domain that has them.
+type skeletonIndex struct {
+	version   string
+	checkedAt time.Time
+	domains   map[string]string
+}
+
+// Checker compares domains against the well-known list and looks for mixed
+// scripts.
+type Checker struct {
+	table     Table
+	wellKnown wellKnownChecker
+
+	mu      sync.Mutex
+	index   *skeletonIndex
+	loading sync.Mutex
+}
+
+func New(table Table, wellKnown wellKnownChecker) *Checker {
+	return &Checker{
+		table:     table,
+		wellKnown: wellKnown,
+	}
+}
+
+func (c *Checker) CheckConfusable(ctx context.Context, localPart string, domain emailchecker.Domain) (*emailchecker.ConfusableCheckResult, error) {
+	name := toUnicode(domain.Organizational)
+
+	ans := emailchecker.ConfusableCheckResult{
+		Skeleton: c.table.Skeleton(name),
+	}
+
+	for _, label := range strings.Split(toUnicode(domain.Name), ".") {
+		if _, mixed := scripts(label); mixed {
+			ans.MixedScriptDomain = true
+		}
+	}
+
+	ans.LocalPartScripts, ans.MixedScriptLocalPart = scripts(localPart)
+
+	imitated, rank, err := c.imitatedDomain(ctx, name, ans.Skeleton)
+	if err != nil {
+		return nil, err
+	}
+
+	ans.ImitatedDomain = imitated
+	ans.ImitatedRank = rank
+	ans.Confusable = imitated != "" || ans.MixedScriptDomain || ans.MixedScriptLocalPart
+
+	return &ans, nil
+}
+
+// imitatedDomain returns the well-known domain that shares the skeleton of
+// name, if name is not well-known itself. A plain ASCII name only imitates
+// the most visited domains, as in g00gle.com or rnicrosoft.com: short ASCII
+// names such as x1.com and xl.com often share their skeleton by chance.
+func (c *Checker) imitatedDomain(ctx context.Context, name, skeleton string) (string, int, error) {
+	index, err := c.skeletons(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	candidate, found := index[skeleton]
+	if !found || candidate == name {
+		return "", 0, nil
+	}
+
+	ok, _, err := c.wellKnown.IsWellKnown(ctx, emailchecker.NewDomain(name))
+	if err != nil || ok {
+		return "", 0, err
+	}
+
+	// The candidate may have been excluded by an override since the index
+	// was built.
+	ok, rank, err := c.wellKnown.IsWellKnown(ctx, emailchecker.NewDomain(candidate))
+	if err != nil || !ok {
+		return "", 0, err
+	}
+
+	if c.table.spoofs(name) || (rank > 0 && rank <= maxASCIIImitatedRank) {
+		return candidate, rank, nil
+	}
+
+	return "", 0, nil
+}
+
+// skeletons returns the skeleton index of the well-known list. The version
+// of the list is checked at most every indexCheckInterval and the index is
+// built again when it changed. Callers keep using the previous index while
+// it is built.
+func (c *Checker) skeletons(ctx context.Context) (map[string]string, error) {
+	c.mu.Lock()
+	index := c.index
+	c.mu.Unlock()
+
+	if index != nil && time.Since(index.checkedAt) < indexCheckInterval {
+		return index.domains, nil
+	}
+
+	if index == nil {
+		c.loading.Lock()
+	} else if !c.loading.TryLock() {
+		return index.domains, nil
+	}
+	defer c.loading.Unlock()
+
+	// Another caller may have built it while this one waited.
+	c.mu.Lock()
+	index = c.index
+	c.mu.Unlock()
+
+	if index != nil && time.Since(index.checkedAt) < indexCheckInterval {
+		return index.domains, nil
+	}
+
+	version, err := c.wellKnown.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the well-known list version: %w", err)
+	}
+
+	next := &skeletonIndex{version: version, checkedAt: time.Now()}
+
+	if index != nil && index.version == version {
+		next.domains = index.domains
+	} else {
+		domains, err := c.wellKnown.Domains(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the well-known list: %w", err)
+		}
+
+		next.domains = c.buildIndex(domains)
+	}
+
+	c.mu.Lock()
+	c.index = next
+	c.mu.Unlock()
+
+	return next.domains, nil
+}
+
+// buildIndex maps the skeleton of every domain to the first domain that has
+// it, domains being in rank order. Punycode domains are indexed by the
+// skeleton of their Unicode form, as names are looked up.
+func (c *Checker) buildIndex(domains []emailchecker.TopDomain) map[string]string {
+	index := make(map[string]string, len(domains))
+
+	for _, d := range domains {
+		skeleton := c.table.Skeleton(toUnicode(d.Domain))
+		if skeleton == d.Domain {
+			// Share the string with the domain.
+			skeleton = d.Domain
+		}
+
+		if _, ok := index[skeleton]; !ok {
+			index[skeleton] = d.Domain
+		}
+	}
+
+	return index
+}
+
+// scripts returns the scripts of the letters of s and whether they mix
+// scripts that are not written together.
+func scripts(s string) ([]string, bool) {
+	var ans []string
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		if script := unicodeext.Script(r); script != "Common" && !slices.Contains(ans, script) {
+			ans = append(ans, script)
+		}
+	}
+
+	slices.Sort(ans)
+
+	if len(ans) <= 1 {
+		return ans, false
+	}
+
+	for _, group := range scriptGroups {
+		if !slices.ContainsFunc(ans, func(script string) bool { return !slices.Contains(group, script) }) {
+			return ans, false
+		}
+	}
+
+	return ans, true
+}
+
+// toUnicode decodes the punycode labels of a domain.
+func toUnicode(domain string) string {
+	if s, err := idna.ToUnicode(domain); err == nil {
+		return s
+	}
+
+	return domain
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+
+	return true
+}
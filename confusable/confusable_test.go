@@ -0,0 +1,141 @@
+package confusable_test
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/confusable"
+)
+
+// wellKnown maps the well-known domains to their rank.
+type wellKnown map[string]int
+
+func (w wellKnown) IsWellKnown(_ context.Context, domain emailchecker.Domain) (bool, int, error) {
+	rank, ok := w[domain.Name]
+	return ok, rank, nil
+}
+
+func (w wellKnown) Domains(context.Context) ([]emailchecker.TopDomain, error) {
+	var ans []emailchecker.TopDomain
+	for domain, rank := range w {
+		ans = append(ans, emailchecker.TopDomain{Domain: domain, Rank: rank})
+	}
+
+	slices.SortFunc(ans, func(a, b emailchecker.TopDomain) int {
+		return cmp.Or(cmp.Compare(a.Rank, b.Rank), strings.Compare(a.Domain, b.Domain))
+	})
+
+	return ans, nil
+}
+
+func (w wellKnown) Version(context.Context) (string, error) {
+	return "v1", nil
+}
+
+// countingList counts the loads of the well-known list.
+type countingList struct {
+	wellKnown
+	loads int
+}
+
+func (l *countingList) Domains(ctx context.Context) ([]emailchecker.TopDomain, error) {
+	l.loads++
+	return l.wellKnown.Domains(ctx)
+}
+
+func TestTable_Skeleton(t *testing.T) {
+	table := confusable.DefaultTable()
+
+	assert.Equal(t, table.Skeleton("gmail.com"), table.Skeleton("gmaіl.com"), "Cyrillic і")
+	assert.Equal(t, "paypal.corn", table.Skeleton("раураl.com"), "Cyrillic р, а and у")
+	assert.Equal(t, "google.corn", table.Skeleton("g00gle.com"))
+	assert.Equal(t, "rnicrosoft.corn", table.Skeleton("microsoft.com"))
+	assert.Equal(t, table.Skeleton("microsoft.com"), table.Skeleton("rnicrosoft.com"))
+	assert.Equal(t, table.Skeleton("apple.com"), table.Skeleton("аpple.com"))
+	assert.NotEqual(t, table.Skeleton("gmail.com"), table.Skeleton("gmall.com"))
+}
+
+func TestParseTable(t *testing.T) {
+	table, err := confusable.ParseTable(strings.NewReader("# comment\n006D ;\t0072 006E ;\tMA\t# ( m → rn )\n"))
+	require.NoError(t, err)
+	assert.Equal(t, confusable.Table{'m': "rn"}, table)
+
+	_, err = confusable.ParseTable(strings.NewReader("006D\n"))
+	assert.Error(t, err)
+}
+
+func TestChecker_CheckConfusable(t *testing.T) {
+	c := confusable.New(confusable.DefaultTable(), wellKnown{
+		"gmail.com": 150, "google.com": 1, "яндекс.рф": 900, "xl.com": 5_000, "abcl.de": 50_000,
+		"microsoft.com": 30, "paypal.com": 200, "turnitin.com": 800, "bucher.de": 600, "modem.de": 40_000,
+	})
+	ctx := context.Background()
+
+	cases := []struct {
+		name        string
+		localPart   string
+		domain      string
+		imitated    string
+		mixedDomain bool
+		mixedLocal  bool
+	}{
+		{name: "Plain address", localPart: "john.doe", domain: "gmail.com"},
+		{name: "Cyrillic letter in domain", localPart: "john", domain: "gmaіl.com", imitated: "gmail.com", mixedDomain: true},
+		{name: "Punycode domain", localPart: "john", domain: "xn--gmal-n9d.com", imitated: "gmail.com", mixedDomain: true},
+		{name: "Digits for letters", localPart: "john", domain: "g00gle.com", imitated: "google.com"},
+		{name: "Digits of a plain name", localPart: "john", domain: "x1.com"},
+		{name: "Digits of a plain ccTLD name", localPart: "john", domain: "abc1.de"},
+		{name: "Cyrillic look-alike of a lower rank", localPart: "john", domain: "аbcl.de", imitated: "abcl.de", mixedDomain: true},
+		{name: "Subdomain of a look-alike", localPart: "john", domain: "mail.gmaіl.com", imitated: "gmail.com", mixedDomain: true},
+		{name: "rn for m", localPart: "john", domain: "rnicrosoft.com", imitated: "microsoft.com"},
+		{name: "m for rn", localPart: "john", domain: "tumitin.com", imitated: "turnitin.com"},
+		{name: "rn for m of a lower rank", localPart: "john", domain: "rnodern.de"},
+		{name: "Mixed-script look-alike", localPart: "john", domain: "раураl.com", imitated: "paypal.com", mixedDomain: true},
+		{name: "Punycode mixed-script look-alike", localPart: "john", domain: "xn--l-7sba6dbr.com", imitated: "paypal.com", mixedDomain: true},
+		{name: "Well-known IDN", localPart: "ivan", domain: "яндекс.рф"},
+		{name: "IDN with a diacritic", localPart: "hans", domain: "bücher.de"},
+		{name: "Cyrillic IDN", localPart: "ivan", domain: "почта.рф"},
+		{name: "Unknown look-alike", localPart: "john", domain: "exаmple.com", mixedDomain: true},
+		{name: "Mixed-script local part", localPart: "ivаn", domain: "gmail.com", mixedLocal: true},
+		{name: "Japanese local part", localPart: "山田たろう", domain: "example.jp"},
+		{name: "Cyrillic local part", localPart: "дмитрий", domain: "gmail.com"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := c.CheckConfusable(ctx, tc.localPart, emailchecker.NewDomain(tc.domain))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.imitated, res.ImitatedDomain)
+			assert.Equal(t, tc.mixedDomain, res.MixedScriptDomain)
+			assert.Equal(t, tc.mixedLocal, res.MixedScriptLocalPart)
+			assert.Equal(t, tc.imitated != "" || tc.mixedDomain || tc.mixedLocal, res.Confusable)
+		})
+	}
+
+	res, err := c.CheckConfusable(ctx, "ivаn", emailchecker.NewDomain("gmaіl.com"))
+	require.NoError(t, err)
+	assert.Equal(t, 150, res.ImitatedRank)
+	assert.Equal(t, []string{"Cyrillic", "Latin"}, res.LocalPartScripts)
+}
+
+func TestChecker_SkeletonIndex(t *testing.T) {
+	list := &countingList{wellKnown: wellKnown{"microsoft.com": 30}}
+	c := confusable.New(confusable.DefaultTable(), list)
+	ctx := context.Background()
+
+	for _, domain := range []string{"rnicrosoft.com", "rnicrosoft.com", "example.com"} {
+		_, err := c.CheckConfusable(ctx, "john", emailchecker.NewDomain(domain))
+		require.NoError(t, err)
+	}
+
+	// The index is built once, not on every check.
+	assert.Equal(t, 1, list.loads)
+}
@@ -2,58 +2,176 @@ package disposable
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
 )
 
 type repo interface {
-	IsDisposable(context.Context, string) (bool, error)
-	UpdateDomains(context.Context, []string) error
-	NeedsRefresh(context.Context) (bool, error)
+	GetDisposableDomain(context.Context, []string) (*emailchecker.DisposableDomain, error)
+	UpdateDomains(context.Context, []emailchecker.DisposableDomain, *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error)
+	NeedsRefresh(context.Context, time.Duration) (bool, error)
+	MarkRefreshed(context.Context) error
+	RecordRefresh(context.Context, emailchecker.ListName, error) error
 }
 
 type fetcher interface {
-	FetchDisposableDomains(ctx context.Context) ([]string, error)
+	// FetchDisposableDomains returns httpext.ErrNotModified when no source
+	// changed since the last committed fetch.
+	FetchDisposableDomains(ctx context.Context) ([]emailchecker.DisposableDomain, error)
+	// Commit persists the cache validators of the last fetch.
+	Commit(ctx context.Context) error
+	// Sources returns the locations the list is downloaded from.
+	Sources() []emailchecker.DataSource
+}
+
+// DefaultGuard rejects refreshes that would drop below a few thousand
+// domains, shrink the list by more than a third or miss a well-known
+// disposable provider.
+var DefaultGuard = emailchecker.ListGuard{
+	MinRows:   1000,
+	MaxShrink: 0.3,
+	Samples:   []string{"mailinator.com"},
+}
+
+// GuardFor returns the guard of a list downloaded from sources. DefaultGuard
+// only fits the default source: a curated list may be small and need not
+// have mailinator.com, so only its shrink is checked.
+func GuardFor(sources []Source) emailchecker.ListGuard {
+	for _, src := range sources {
+		if !src.Allowlist && src.Location == DefaultSourceURL {
+			return DefaultGuard
+		}
+	}
+
+	return emailchecker.ListGuard{MaxShrink: DefaultGuard.MaxShrink}
+}
+
+type Option func(*DisposableChecker)
+
+// WithMatchMode sets which names of a domain are matched against the list.
+func WithMatchMode(mode emailchecker.MatchMode) Option {
+	return func(d *DisposableChecker) {
+		d.matchMode = mode
+	}
+}
+
+// WithGuard replaces DefaultGuard.
+func WithGuard(guard emailchecker.ListGuard) Option {
+	return func(d *DisposableChecker) {
+		d.guard = guard
+	}
 }
 
 type DisposableChecker struct {
-	repo    repo
-	fetcher fetcher
+	repo      repo
+	fetcher   fetcher
+	matchMode emailchecker.MatchMode
+	guard     emailchecker.ListGuard
 }
 
-func New(repo repo, fetcher fetcher) (*DisposableChecker, error) {
+// New creates a checker that serves the stored list. The list is downloaded
+// by UpdateDisposableList.
+func New(repo repo, fetcher fetcher, opts ...Option) (*DisposableChecker, error) {
 	ans := DisposableChecker{
-		repo:    repo,
-		fetcher: fetcher,
+		repo:      repo,
+		fetcher:   fetcher,
+		matchMode: emailchecker.MatchSuffix,
+		guard:     DefaultGuard,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
 	}
 
-	refreshCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	return &ans, nil
+}
 
-	if err := ans.UpdateDisposableList(refreshCtx); err != nil {
+func (d *DisposableChecker) IsDisposable(ctx context.Context, domain emailchecker.Domain) (*emailchecker.DisposableCheckResult, error) {
+	match, err := d.repo.GetDisposableDomain(ctx, domain.Candidates(d.matchMode))
+	if err != nil {
 		return nil, err
 	}
 
-	return &ans, nil
+	if match == nil {
+		return &emailchecker.DisposableCheckResult{}, nil
+	}
+
+	if match.Excluded {
+		return &emailchecker.DisposableCheckResult{
+			Reason:        emailchecker.DisposableReasonOverride,
+			MatchedDomain: match.Domain,
+			Sources:       match.Sources,
+		}, nil
+	}
+
+	reason := emailchecker.DisposableReasonListed
+	if match.Override {
+		reason = emailchecker.DisposableReasonOverride
+	}
+
+	return &emailchecker.DisposableCheckResult{
+		Disposable:    true,
+		Reason:        reason,
+		MatchedDomain: match.Domain,
+		Sources:       match.Sources,
+	}, nil
 }
 
-func (d *DisposableChecker) IsDisposable(ctx context.Context, domain string) (bool, error) {
-	return d.repo.IsDisposable(ctx, domain)
+// UpdateDisposableList refreshes the list when its data is older than
+// maxAge; a zero maxAge forces the refresh.
+func (d *DisposableChecker) UpdateDisposableList(ctx context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	return emailchecker.RecordRefresh(ctx, d.repo, emailchecker.ListDisposable, func(ctx context.Context) (*emailchecker.ListRefreshResult, error) {
+		return d.refresh(ctx, maxAge)
+	})
 }
 
-func (d *DisposableChecker) UpdateDisposableList(ctx context.Context) error {
-	needRefresh, err := d.repo.NeedsRefresh(ctx)
+func (d *DisposableChecker) Sources() []emailchecker.DataSource {
+	return d.fetcher.Sources()
+}
+
+func (d *DisposableChecker) refresh(ctx context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	start := time.Now()
+
+	needRefresh, err := d.repo.NeedsRefresh(ctx, maxAge)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !needRefresh {
-		return nil
+		return &emailchecker.ListRefreshResult{List: emailchecker.ListDisposable, Skipped: true}, nil
 	}
 
 	domains, err := d.fetcher.FetchDisposableDomains(ctx)
+	if errors.Is(err, httpext.ErrNotModified) {
+		if err := d.repo.MarkRefreshed(ctx); err != nil {
+			return nil, err
+		}
+
+		return &emailchecker.ListRefreshResult{
+			List:        emailchecker.ListDisposable,
+			NotModified: true,
+			Elapsed:     time.Since(start),
+		}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := d.repo.UpdateDomains(ctx, domains, &d.guard)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return d.repo.UpdateDomains(ctx, domains)
+	if err := d.fetcher.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	result.List = emailchecker.ListDisposable
+	result.Elapsed = time.Since(start)
+
+	return result, nil
 }
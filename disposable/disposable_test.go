@@ -0,0 +1,202 @@
+package disposable_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/disposable"
+	"emailchecker/pkg/httpext"
+)
+
+func TestGuardFor(t *testing.T) {
+	custom := disposable.Source{Name: "curated", Location: "/etc/emailchecker/disposable.txt"}
+	allowlist := disposable.Source{Name: "allow", Location: disposable.DefaultSourceURL, Allowlist: true}
+
+	cases := []struct {
+		name    string
+		sources []disposable.Source
+		want    emailchecker.ListGuard
+	}{
+		{name: "Default source", sources: disposable.DefaultSources(), want: disposable.DefaultGuard},
+		{name: "Default and custom sources", sources: append(disposable.DefaultSources(), custom), want: disposable.DefaultGuard},
+		{name: "Custom source", sources: []disposable.Source{custom}, want: emailchecker.ListGuard{MaxShrink: 0.3}},
+		{name: "Default URL as an allowlist", sources: []disposable.Source{custom, allowlist}, want: emailchecker.ListGuard{MaxShrink: 0.3}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, disposable.GuardFor(tc.sources))
+		})
+	}
+}
+
+// repo serves the disposable list from a map of entries by domain.
+type repo map[string]emailchecker.DisposableDomain
+
+func (r repo) GetDisposableDomain(_ context.Context, domains []string) (*emailchecker.DisposableDomain, error) {
+	for _, domain := range domains {
+		if d, ok := r[domain]; ok {
+			return &d, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r repo) UpdateDomains(context.Context, []emailchecker.DisposableDomain, *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error) {
+	return &emailchecker.ListRefreshResult{}, nil
+}
+
+func (r repo) NeedsRefresh(context.Context, time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (r repo) MarkRefreshed(context.Context) error {
+	return nil
+}
+
+func (r repo) RecordRefresh(context.Context, emailchecker.ListName, error) error {
+	return nil
+}
+
+func TestDisposableChecker_IsDisposable(t *testing.T) {
+	r := repo{
+		"trash.example":    {Domain: "trash.example", Sources: []string{"a", "b"}},
+		"isp.example":      {Domain: "isp.example", Sources: []string{"override"}, Override: true},
+		"mail.isp.example": {Domain: "mail.isp.example", Sources: []string{"override"}, Override: true, Excluded: true},
+	}
+
+	cases := []struct {
+		name   string
+		domain string
+		opts   []disposable.Option
+		want   emailchecker.DisposableCheckResult
+	}{
+		{name: "Unlisted", domain: "example.com", want: emailchecker.DisposableCheckResult{}},
+		{
+			name:   "Listed",
+			domain: "trash.example",
+			want:   emailchecker.DisposableCheckResult{Disposable: true, Reason: emailchecker.DisposableReasonListed, MatchedDomain: "trash.example", Sources: []string{"a", "b"}},
+		},
+		{
+			name:   "Subdomain of a listed domain",
+			domain: "x.trash.example",
+			want:   emailchecker.DisposableCheckResult{Disposable: true, Reason: emailchecker.DisposableReasonListed, MatchedDomain: "trash.example", Sources: []string{"a", "b"}},
+		},
+		{name: "Subdomain in exact mode", domain: "x.trash.example", opts: []disposable.Option{disposable.WithMatchMode(emailchecker.MatchExact)}, want: emailchecker.DisposableCheckResult{}},
+		{
+			name:   "Included by an override",
+			domain: "isp.example",
+			want:   emailchecker.DisposableCheckResult{Disposable: true, Reason: emailchecker.DisposableReasonOverride, MatchedDomain: "isp.example", Sources: []string{"override"}},
+		},
+		{
+			name:   "Excluded by an override",
+			domain: "mail.isp.example",
+			want:   emailchecker.DisposableCheckResult{Reason: emailchecker.DisposableReasonOverride, MatchedDomain: "mail.isp.example", Sources: []string{"override"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := disposable.New(r, nil, tc.opts...)
+			require.NoError(t, err)
+
+			got, err := c.IsDisposable(context.Background(), emailchecker.NewDomain(tc.domain))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, *got)
+		})
+	}
+}
+
+// staleRepo is due for a refresh and records what the refresh did.
+type staleRepo struct {
+	repo
+	updated   []emailchecker.DisposableDomain
+	refreshed bool
+}
+
+func (r *staleRepo) NeedsRefresh(context.Context, time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (r *staleRepo) UpdateDomains(_ context.Context, domains []emailchecker.DisposableDomain, _ *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error) {
+	r.updated = domains
+	return &emailchecker.ListRefreshResult{Added: int64(len(domains)), Total: int64(len(domains))}, nil
+}
+
+func (r *staleRepo) MarkRefreshed(context.Context) error {
+	r.refreshed = true
+	return nil
+}
+
+type fetcher struct {
+	domains   []emailchecker.DisposableDomain
+	err       error
+	committed bool
+}
+
+func (f *fetcher) FetchDisposableDomains(context.Context) ([]emailchecker.DisposableDomain, error) {
+	return f.domains, f.err
+}
+
+func (f *fetcher) Commit(context.Context) error {
+	f.committed = true
+	return nil
+}
+
+func (f *fetcher) Sources() []emailchecker.DataSource {
+	return nil
+}
+
+func TestDisposableChecker_UpdateDisposableList(t *testing.T) {
+	domains := []emailchecker.DisposableDomain{{Domain: "trash.example", Sources: []string{"test"}}}
+
+	t.Run("Not modified", func(t *testing.T) {
+		r := &staleRepo{}
+		f := &fetcher{err: httpext.ErrNotModified}
+
+		c, err := disposable.New(r, f)
+		require.NoError(t, err)
+
+		res, err := c.UpdateDisposableList(context.Background(), 0)
+		require.NoError(t, err)
+		assert.True(t, res.NotModified)
+		assert.True(t, r.refreshed, "an unchanged list counts as refreshed")
+		assert.Nil(t, r.updated)
+		assert.False(t, f.committed)
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		r := &staleRepo{}
+		f := &fetcher{domains: domains}
+
+		c, err := disposable.New(r, f)
+		require.NoError(t, err)
+
+		res, err := c.UpdateDisposableList(context.Background(), 0)
+		require.NoError(t, err)
+		assert.False(t, res.NotModified)
+		assert.Equal(t, int64(1), res.Added)
+		assert.Equal(t, domains, r.updated)
+		assert.True(t, f.committed, "the validators are stored once the list is")
+	})
+
+	t.Run("Failed fetch", func(t *testing.T) {
+		r := &staleRepo{}
+		f := &fetcher{err: errors.New("connection refused")}
+
+		c, err := disposable.New(r, f)
+		require.NoError(t, err)
+
+		_, err = c.UpdateDisposableList(context.Background(), 0)
+		require.Error(t, err)
+		assert.False(t, r.refreshed)
+		assert.False(t, f.committed)
+	})
+}
@@ -0,0 +1,234 @@
+package disposable
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+)
+
+const DefaultSourceURL = "https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.txt"
+
+// Source is a list of domains, one per line, loaded from an HTTP(S) URL or a
+// local file. Lines starting with # are ignored.
+type Source struct {
+	Name     string
+	Location string
+	// Allowlist sources remove their domains from the combined blocklist.
+	Allowlist bool
+}
+
+func DefaultSources() []Source {
+	return []Source{
+		{Name: "disposable-email-domains", Location: DefaultSourceURL},
+	}
+}
+
+// ParseSources parses a comma separated list of name=location pairs.
+func ParseSources(s string, allowlist bool) ([]Source, error) {
+	var sources []Source
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, location, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		location = strings.TrimSpace(location)
+
+		if !ok || name == "" || location == "" {
+			return nil, fmt.Errorf("invalid source %q: expected name=location", item)
+		}
+
+		sources = append(sources, Source{
+			Name:      name,
+			Location:  location,
+			Allowlist: allowlist,
+		})
+	}
+
+	return sources, nil
+}
+
+type SourceFetcher struct {
+	client  *httpext.ConditionalClient
+	sources []Source
+}
+
+func NewSourceFetcher(client *http.Client, store httpext.ValidatorStore, sources []Source) (*SourceFetcher, error) {
+	blocklists := 0
+	seen := make(map[string]struct{}, len(sources))
+
+	for _, src := range sources {
+		if _, ok := seen[src.Name]; ok {
+			return nil, fmt.Errorf("duplicate disposable source name %q", src.Name)
+		}
+
+		seen[src.Name] = struct{}{}
+
+		if !src.Allowlist {
+			blocklists++
+		}
+	}
+
+	if blocklists == 0 {
+		return nil, fmt.Errorf("at least one disposable blocklist source is required")
+	}
+
+	return &SourceFetcher{
+		client:  httpext.NewConditionalClient(client, store),
+		sources: sources,
+	}, nil
+}
+
+// FetchDisposableDomains loads every source and returns the union of the
+// blocklists minus the allowlists. Any failing source fails the whole fetch,
+// so that a partial download never shrinks the list.
+//
+// HTTP sources are requested conditionally. When none of them changed and
+// there are no local files, httpext.ErrNotModified is returned. Otherwise the
+// unchanged sources are downloaded again, since the union needs all of them.
+func (f *SourceFetcher) FetchDisposableDomains(ctx context.Context) ([]emailchecker.DisposableDomain, error) {
+	f.client.Reset()
+
+	fetched := make([][]string, len(f.sources))
+	modified := false
+
+	for i, src := range f.sources {
+		domains, err := f.fetch(ctx, src, true)
+
+		switch {
+		case errors.Is(err, httpext.ErrNotModified):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("could not fetch disposable source %q: %w", src.Name, err)
+		}
+
+		fetched[i] = domains
+		modified = true
+	}
+
+	if !modified {
+		return nil, httpext.ErrNotModified
+	}
+
+	listed := make(map[string][]string)
+	allowed := make(map[string]struct{})
+
+	for i, src := range f.sources {
+		domains := fetched[i]
+		if domains == nil {
+			var err error
+
+			domains, err = f.fetch(ctx, src, false)
+			if err != nil {
+				return nil, fmt.Errorf("could not fetch disposable source %q: %w", src.Name, err)
+			}
+		}
+
+		for _, domain := range domains {
+			if src.Allowlist {
+				allowed[domain] = struct{}{}
+				continue
+			}
+
+			if !slices.Contains(listed[domain], src.Name) {
+				listed[domain] = append(listed[domain], src.Name)
+			}
+		}
+	}
+
+	ans := make([]emailchecker.DisposableDomain, 0, len(listed))
+	for domain, sources := range listed {
+		if _, ok := allowed[domain]; ok {
+			continue
+		}
+
+		ans = append(ans, emailchecker.DisposableDomain{
+			Domain:  domain,
+			Sources: sources,
+		})
+	}
+
+	if len(ans) == 0 {
+		return nil, fmt.Errorf("no domains found in disposable sources")
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].Domain < ans[j].Domain
+	})
+
+	return ans, nil
+}
+
+func (f *SourceFetcher) Commit(ctx context.Context) error {
+	return f.client.Commit(ctx)
+}
+
+func (f *SourceFetcher) Sources() []emailchecker.DataSource {
+	ans := make([]emailchecker.DataSource, 0, len(f.sources))
+	for _, src := range f.sources {
+		ans = append(ans, emailchecker.DataSource{
+			Name:      src.Name,
+			Location:  src.Location,
+			Allowlist: src.Allowlist,
+		})
+	}
+
+	return ans
+}
+
+func (f *SourceFetcher) fetch(ctx context.Context, src Source, conditional bool) ([]string, error) {
+	if !strings.HasPrefix(src.Location, "http://") && !strings.HasPrefix(src.Location, "https://") {
+		file, err := os.Open(src.Location)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close() //nolint:errcheck
+
+		return readDomains(file)
+	}
+
+	resp, err := f.client.Get(ctx, src.Location, conditional)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch disposable domains: %w", err)
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	return readDomains(resp.Body)
+}
+
+func readDomains(r io.Reader) ([]string, error) {
+	var domains []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domains = append(domains, strings.ToLower(strings.TrimSuffix(line, ".")))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	return domains, nil
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"emailchecker"
 
@@ -27,22 +29,66 @@ type Answer struct {
 	Data string `json:"data"`
 }
 
+const (
+	typeA    = 1
+	typeNS   = 2
+	typeMX   = 15
+	typeAAAA = 28
+)
+
 type Client struct {
 	httpClient  *http.Client
 	parkChecker *parkedDomainChecker
 }
 
-func New(netClient *http.Client) *Client {
-	pc := newParkedDomainChecker()
+// New returns a client resolving through netClient, which tells parked
+// domains from the parked list of store.
+func New(netClient *http.Client, store parkedStore) *Client {
 	return &Client{
 		httpClient:  netClient,
-		parkChecker: pc,
+		parkChecker: newParkedDomainChecker(store),
 	}
 }
 
+const cloudflareAPIEndpoint = "https://one.one.one.one/dns-query"
+
 func (c *Client) Lookup(ctx context.Context, domain, recordType string) (*CloudflareResponse, error) {
-	const cloudflareAPIEndpoint = "https://one.one.one.one/dns-query"
+	trace := emailchecker.DNSTraceFromContext(ctx)
+	if trace == nil {
+		return c.lookup(ctx, domain, recordType)
+	}
+
+	start := time.Now()
+	resp, err := c.lookup(ctx, domain, recordType)
+
+	q := emailchecker.DNSQueryTrace{
+		Name:     domain,
+		Type:     recordType,
+		Upstream: cloudflareAPIEndpoint,
+		Answers:  []emailchecker.DNSAnswerTrace{},
+		Latency:  time.Since(start),
+	}
+
+	if err != nil {
+		q.Error = err.Error()
+	} else {
+		q.RCode = resp.Status
+		for _, ans := range resp.Answer {
+			q.Answers = append(q.Answers, emailchecker.DNSAnswerTrace{
+				Name: ans.Name,
+				Type: ans.Type,
+				TTL:  ans.TTL,
+				Data: ans.Data,
+			})
+		}
+	}
+
+	trace.AddQuery(q)
 
+	return resp, err
+}
+
+func (c *Client) lookup(ctx context.Context, domain, recordType string) (*CloudflareResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cloudflareAPIEndpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create DoH request: %w", err)
@@ -81,10 +127,10 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 
 	var mu sync.Mutex
 
-	g, ctx := errgroup.WithContext(ctx)
+	g, gctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		reps, err := c.Lookup(ctx, domain, "A")
+		reps, err := c.Lookup(gctx, domain, "A")
 		if err != nil {
 			return err
 		}
@@ -93,11 +139,8 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 			mu.Lock()
 			defer mu.Unlock()
 			for _, ans := range reps.Answer {
-				if ans.Type == 1 {
+				if ans.Type == typeA {
 					result.ARecords = append(result.ARecords, ans.Data)
-					if !result.IsParked && c.parkChecker.IsParkedDomainIP(ans.Data) {
-						result.IsParked = true
-					}
 				}
 			}
 		}
@@ -106,7 +149,26 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 	})
 
 	g.Go(func() error {
-		resp, err := c.Lookup(ctx, domain, "NS")
+		resp, err := c.Lookup(gctx, domain, "AAAA")
+		if err != nil {
+			return err
+		}
+
+		if resp.Status == 0 && len(resp.Answer) > 0 {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, ans := range resp.Answer {
+				if ans.Type == typeAAAA {
+					result.AAAARecords = append(result.AAAARecords, ans.Data)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	g.Go(func() error {
+		resp, err := c.Lookup(gctx, domain, "NS")
 		if err != nil {
 			return err
 		}
@@ -115,11 +177,8 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 			mu.Lock()
 			defer mu.Unlock()
 			for _, ans := range resp.Answer {
-				if ans.Type == 2 {
+				if ans.Type == typeNS {
 					result.NSRecords = append(result.NSRecords, ans.Data)
-					if !result.IsParked && c.parkChecker.IsParkedDomainNS(ans.Data) {
-						result.IsParked = true
-					}
 				}
 			}
 		}
@@ -128,7 +187,7 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 	})
 
 	g.Go(func() error {
-		resp, err := c.Lookup(ctx, domain, "MX")
+		resp, err := c.Lookup(gctx, domain, "MX")
 		if err != nil {
 			return err
 		}
@@ -154,7 +213,7 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 	})
 
 	g.Go(func() error {
-		resp, err := c.Lookup(ctx, domain, "TXT")
+		resp, err := c.Lookup(gctx, domain, "TXT")
 		if err != nil {
 			return err
 		}
@@ -175,7 +234,7 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 
 	g.Go(func() error {
 		dmarcDomain := "_dmarc." + domain
-		resp, err := c.Lookup(ctx, dmarcDomain, "TXT")
+		resp, err := c.Lookup(gctx, dmarcDomain, "TXT")
 		if err != nil {
 			return err
 		}
@@ -198,5 +257,82 @@ func (c *Client) GetDNSValidation(ctx context.Context, domain string) (*emailche
 		return nil, err
 	}
 
+	parked, err := c.parkChecker.IsParked(ctx, slices.Concat(result.ARecords, result.AAAARecords), result.NSRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	result.IsParked = parked
+
+	// Without MX records mail is delivered to the domain's own address
+	// records (RFC 5321 section 5.1).
+	if !result.HasMX && (len(result.ARecords) > 0 || len(result.AAAARecords) > 0) {
+		result.ImplicitMX = true
+	}
+
+	c.resolveMXHosts(ctx, result.MXRecords)
+
 	return result, nil
 }
+
+// resolveMXHosts fills in the IPv4 and IPv6 addresses of every MX host.
+// Failures are not fatal: a host that cannot be resolved keeps no addresses.
+func (c *Client) resolveMXHosts(ctx context.Context, records []emailchecker.MXRecord) {
+	var wg sync.WaitGroup
+
+	for i := range records {
+		// A null MX (RFC 7505) explicitly announces that the domain
+		// accepts no mail, so there is nothing to resolve.
+		if records[i].Value == "." {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			records[i].IPs = c.lookupAddrs(ctx, records[i].Value)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (c *Client) lookupAddrs(ctx context.Context, host string) []string {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		addrs []string
+	)
+
+	for _, q := range []struct {
+		recordType string
+		answerType int
+	}{
+		{"A", typeA},
+		{"AAAA", typeAAAA},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := c.Lookup(ctx, host, q.recordType)
+			if err != nil || resp.Status != 0 {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, ans := range resp.Answer {
+				if ans.Type == q.answerType {
+					addrs = append(addrs, ans.Data)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return addrs
+}
@@ -3,21 +3,29 @@ package dns
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 
 	"emailchecker"
 )
 
+const cacheTTL = 24 * time.Hour
+
 type repo interface {
 	GetDNSRecord(ctx context.Context, domain string) (*emailchecker.DNSRecord, error)
 	UpsertDNSRecord(ctx context.Context, domain string, data []byte) error
+	DeleteDNSRecords(ctx context.Context, domain string, includeSubdomains bool) (int64, error)
+	GetDNSCacheStats(ctx context.Context, staleBefore time.Time) (*emailchecker.DNSCacheStats, error)
 }
 
 type inFlightRequest struct {
-	wg  sync.WaitGroup
-	res *emailchecker.DNSValidationResult
-	err error
+	done chan struct{}
+	res  *emailchecker.DNSValidationResult
+	err  error
+	// abandoned is set when the lookup ended because the context of the
+	// leader ended, rather than because of the upstream.
+	abandoned bool
 }
 
 type Resolver struct {
@@ -40,44 +48,111 @@ func NewResolver(dnsClient *Client, repo repo) *Resolver {
 func (r *Resolver) GetDNSValidationResult(ctx context.Context, domain string) (*emailchecker.DNSValidationResult, error) {
 	cachedRec, _ := r.repo.GetDNSRecord(ctx, domain)
 
-	if cachedRec != nil && time.Since(cachedRec.CreatedAt) < 24*time.Hour {
+	if cachedRec != nil && time.Since(cachedRec.CreatedAt) < cacheTTL {
 		var result emailchecker.DNSValidationResult
 		if err := json.Unmarshal(cachedRec.Data, &result); err == nil {
+			emailchecker.DNSTraceFromContext(ctx).SetCacheHit(cachedRec.CreatedAt)
+
 			return &result, nil
 		}
 	}
 
-	r.mu.Lock()
-	if req, ok := r.inflight[domain]; ok {
+	return r.resolve(ctx, domain)
+}
+
+// RefreshDNSValidationResult resolves the domain again, ignoring any cached
+// record, and stores the fresh result.
+func (r *Resolver) RefreshDNSValidationResult(ctx context.Context, domain string) (*emailchecker.DNSValidationResult, error) {
+	return r.resolve(ctx, normalizeDomain(domain))
+}
+
+// PurgeDNSCache removes the cached record of the domain. When
+// includeSubdomains is set every cached subdomain is removed as well.
+func (r *Resolver) PurgeDNSCache(ctx context.Context, domain string, includeSubdomains bool) (int64, error) {
+	return r.repo.DeleteDNSRecords(ctx, normalizeDomain(domain), includeSubdomains)
+}
+
+func (r *Resolver) DNSCacheStats(ctx context.Context) (*emailchecker.DNSCacheStats, error) {
+	return r.repo.GetDNSCacheStats(ctx, time.Now().UTC().Add(-cacheTTL))
+}
+
+func (r *Resolver) resolve(ctx context.Context, domain string) (*emailchecker.DNSValidationResult, error) {
+	// A traced lookup must issue its own queries, otherwise a caller joining
+	// an in-flight request would get a result without a trace.
+	if emailchecker.DNSTraceFromContext(ctx) != nil {
+		return r.lookup(ctx, domain)
+	}
+
+	for {
+		r.mu.Lock()
+		req, ok := r.inflight[domain]
+		if !ok {
+			req = &inFlightRequest{done: make(chan struct{})}
+			r.inflight[domain] = req
+			r.mu.Unlock()
+
+			return r.lead(ctx, domain, req)
+		}
 		r.mu.Unlock()
-		req.wg.Wait()
+
+		select {
+		case <-req.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// The leader gave up because its own context ended. That says nothing
+		// about this caller, so try again and possibly become the new leader.
+		// An upstream timeout is shared instead, so that a slow resolver is
+		// not queried again by every waiter in turn.
+		if req.abandoned && ctx.Err() == nil {
+			continue
+		}
+
 		return req.res, req.err
 	}
+}
 
-	r.sem <- struct{}{}
-	defer func() { <-r.sem }()
+// lead performs the lookup on behalf of every caller waiting on req and
+// publishes the outcome once it is known.
+func (r *Resolver) lead(ctx context.Context, domain string, req *inFlightRequest) (*emailchecker.DNSValidationResult, error) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.inflight, domain)
+		r.mu.Unlock()
 
-	req := &inFlightRequest{}
-	req.wg.Add(1)
-	r.inflight[domain] = req
-	r.mu.Unlock()
+		close(req.done)
+	}()
 
-	freshResult, fetchErr := r.dnsClient.GetDNSValidation(ctx, domain)
+	req.res, req.err = r.lookup(ctx, domain)
+	req.abandoned = req.err != nil && ctx.Err() != nil
 
-	req.res = freshResult
-	req.err = fetchErr
-	req.wg.Done()
+	return req.res, req.err
+}
 
-	r.mu.Lock()
-	delete(r.inflight, domain)
-	r.mu.Unlock()
+// lookup queries the upstream resolver, bounded by the semaphore, and caches
+// a successful result.
+func (r *Resolver) lookup(ctx context.Context, domain string) (*emailchecker.DNSValidationResult, error) {
+	select {
+	case r.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-r.sem }()
 
-	if fetchErr == nil {
-		jsonData, marshalErr := json.Marshal(freshResult)
-		if marshalErr == nil {
-			_ = r.repo.UpsertDNSRecord(ctx, domain, jsonData)
-		}
+	result, err := r.dnsClient.GetDNSValidation(ctx, domain)
+	if err != nil {
+		return nil, err
 	}
 
-	return freshResult, fetchErr
+	jsonData, marshalErr := json.Marshal(result)
+	if marshalErr == nil {
+		_ = r.repo.UpsertDNSRecord(ctx, domain, jsonData)
+	}
+
+	return result, nil
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
 }
@@ -0,0 +1,260 @@
+package dns_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/dns"
+)
+
+// upstream answers DoH queries with empty responses. While gate is open,
+// queries wait for it to be closed or for their own context to end.
+type upstream struct {
+	mu      sync.Mutex
+	queries map[string]int
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+	// err is returned instead of an answer once gate is closed.
+	err error
+}
+
+func newUpstream() *upstream {
+	return &upstream{
+		queries: make(map[string]int),
+		started: make(chan struct{}),
+		gate:    make(chan struct{}),
+	}
+}
+
+func (u *upstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+
+	u.mu.Lock()
+	u.queries[q.Get("name")+" "+q.Get("type")]++
+	u.mu.Unlock()
+
+	u.once.Do(func() { close(u.started) })
+
+	select {
+	case <-u.gate:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"Status":0}`)),
+		Request:    req,
+	}, nil
+}
+
+func (u *upstream) count(name, recordType string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.queries[name+" "+recordType]
+}
+
+// repo caches nothing, so that every lookup reaches the resolver.
+type repo struct{}
+
+func (repo) GetDNSRecord(context.Context, string) (*emailchecker.DNSRecord, error) {
+	return nil, nil
+}
+
+func (repo) UpsertDNSRecord(context.Context, string, []byte) error {
+	return nil
+}
+
+func (repo) DeleteDNSRecords(context.Context, string, bool) (int64, error) {
+	return 0, nil
+}
+
+func (repo) GetDNSCacheStats(context.Context, time.Time) (*emailchecker.DNSCacheStats, error) {
+	return &emailchecker.DNSCacheStats{}, nil
+}
+
+type lookup struct {
+	res *emailchecker.DNSValidationResult
+	err error
+}
+
+func newResolver(u *upstream) *dns.Resolver {
+	return dns.NewResolver(dns.New(&http.Client{Transport: u}, parkedList{}), repo{})
+}
+
+func resolve(ctx context.Context, r *dns.Resolver, domain string) <-chan lookup {
+	ch := make(chan lookup, 1)
+
+	go func() {
+		res, err := r.GetDNSValidationResult(ctx, domain)
+		ch <- lookup{res: res, err: err}
+	}()
+
+	return ch
+}
+
+func wait(t *testing.T, ch <-chan lookup) lookup {
+	t.Helper()
+
+	select {
+	case l := <-ch:
+		return l
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "lookup did not return")
+		return lookup{}
+	}
+}
+
+// join starts lookups that wait on the in-flight lookup of the leader.
+func join(ctx context.Context, r *dns.Resolver, domain string, n int) []<-chan lookup {
+	followers := make([]<-chan lookup, n)
+	for i := range followers {
+		followers[i] = resolve(ctx, r, domain)
+	}
+
+	// Followers have nothing to signal while they wait, so give them time
+	// to find the in-flight lookup.
+	time.Sleep(50 * time.Millisecond)
+
+	return followers
+}
+
+func TestResolver_SharesInFlightLookup(t *testing.T) {
+	u := newUpstream()
+	r := newResolver(u)
+
+	leader := resolve(context.Background(), r, "example.com")
+	<-u.started
+
+	followers := join(context.Background(), r, "example.com", 10)
+	close(u.gate)
+
+	want := wait(t, leader)
+	require.NoError(t, want.err)
+
+	for _, f := range followers {
+		got := wait(t, f)
+		require.NoError(t, got.err)
+		assert.Same(t, want.res, got.res)
+	}
+
+	assert.Equal(t, 1, u.count("example.com", "A"))
+}
+
+func TestResolver_CancelledFollowerDoesNotCancelLeader(t *testing.T) {
+	u := newUpstream()
+	r := newResolver(u)
+
+	leader := resolve(context.Background(), r, "example.com")
+	<-u.started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	follower := join(ctx, r, "example.com", 1)[0]
+	cancel()
+
+	assert.ErrorIs(t, wait(t, follower).err, context.Canceled)
+
+	select {
+	case <-leader:
+		require.FailNow(t, "leader returned before the upstream answered")
+	default:
+	}
+
+	close(u.gate)
+
+	got := wait(t, leader)
+	require.NoError(t, got.err)
+	assert.Equal(t, "example.com", got.res.Domain)
+	assert.Equal(t, 1, u.count("example.com", "A"))
+}
+
+func TestResolver_CancelledLeaderDoesNotFailFollowers(t *testing.T) {
+	u := newUpstream()
+	r := newResolver(u)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := resolve(ctx, r, "example.com")
+	<-u.started
+
+	followers := join(context.Background(), r, "example.com", 3)
+	cancel()
+
+	assert.ErrorIs(t, wait(t, leader).err, context.Canceled)
+
+	// Let the followers elect a new leader and join it.
+	require.Eventually(t, func() bool { return u.count("example.com", "A") == 2 }, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(u.gate)
+
+	for _, f := range followers {
+		got := wait(t, f)
+		require.NoError(t, got.err)
+		assert.Equal(t, "example.com", got.res.Domain)
+	}
+
+	// The followers retried together behind a single new leader.
+	assert.Equal(t, 2, u.count("example.com", "A"))
+}
+
+func TestResolver_TimedOutLeaderDoesNotFailFollowers(t *testing.T) {
+	u := newUpstream()
+	r := newResolver(u)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	leader := resolve(ctx, r, "example.com")
+	<-u.started
+
+	followers := join(context.Background(), r, "example.com", 3)
+
+	assert.ErrorIs(t, wait(t, leader).err, context.DeadlineExceeded)
+
+	require.Eventually(t, func() bool { return u.count("example.com", "A") == 2 }, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(u.gate)
+
+	for _, f := range followers {
+		got := wait(t, f)
+		require.NoError(t, got.err)
+	}
+
+	assert.Equal(t, 2, u.count("example.com", "A"))
+}
+
+func TestResolver_UpstreamTimeoutIsShared(t *testing.T) {
+	u := newUpstream()
+	u.err = context.DeadlineExceeded
+	r := newResolver(u)
+
+	leader := resolve(context.Background(), r, "example.com")
+	<-u.started
+
+	followers := join(context.Background(), r, "example.com", 3)
+	close(u.gate)
+
+	assert.ErrorIs(t, wait(t, leader).err, context.DeadlineExceeded)
+
+	// The followers share the failure instead of querying the slow
+	// upstream again one after another.
+	for _, f := range followers {
+		assert.ErrorIs(t, wait(t, f).err, context.DeadlineExceeded)
+	}
+
+	assert.Equal(t, 1, u.count("example.com", "A"))
+}
@@ -0,0 +1,122 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker/dns"
+)
+
+// parkedList is a parked list of nameservers and address ranges.
+type parkedList struct {
+	nameServers []string
+	ranges      []string
+	err         error
+}
+
+func (p parkedList) IsParkedNameServer(_ context.Context, domains []string) (bool, error) {
+	for _, domain := range domains {
+		if slices.Contains(p.nameServers, domain) {
+			return true, p.err
+		}
+	}
+
+	return false, p.err
+}
+
+func (p parkedList) IsParkedAddress(_ context.Context, ip net.IP) (bool, error) {
+	for _, cidr := range p.ranges {
+		if _, network, _ := net.ParseCIDR(cidr); network.Contains(ip) {
+			return true, p.err
+		}
+	}
+
+	return false, p.err
+}
+
+var parking = parkedList{
+	nameServers: []string{"sedoparking.com", "ns1.undeveloped.com"},
+	ranges:      []string{"185.53.176.0/22", "2606:4700:20::681a:625/128"},
+}
+
+// answers replies to A, AAAA and NS queries with the given records and to
+// every other query with an empty answer.
+type answers struct {
+	a, aaaa, ns string
+}
+
+func (a answers) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := dns.CloudflareResponse{}
+
+	name := req.URL.Query().Get("name")
+	switch req.URL.Query().Get("type") {
+	case "A":
+		if a.a != "" {
+			resp.Answer = []dns.Answer{{Name: name, Type: 1, TTL: 300, Data: a.a}}
+		}
+	case "AAAA":
+		if a.aaaa != "" {
+			resp.Answer = []dns.Answer{{Name: name, Type: 28, TTL: 300, Data: a.aaaa}}
+		}
+	case "NS":
+		if a.ns != "" {
+			resp.Answer = []dns.Answer{{Name: name, Type: 2, TTL: 300, Data: a.ns}}
+		}
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_Parked(t *testing.T) {
+	cases := []struct {
+		name    string
+		answers answers
+		parked  bool
+	}{
+		{name: "Parking address", answers: answers{a: "185.53.178.10"}, parked: true},
+		{name: "Parking page over IPv6", answers: answers{aaaa: "2606:4700:20::681a:625"}, parked: true},
+		{name: "Other Cloudflare host", answers: answers{aaaa: "2606:4700:20::681a:626"}},
+		{name: "Unrelated host", answers: answers{a: "192.0.2.1", aaaa: "2001:db8::1"}},
+		{name: "Parking nameserver", answers: answers{ns: "ns2.sedoparking.com."}, parked: true},
+		{name: "Listed nameserver", answers: answers{ns: "ns1.undeveloped.com."}, parked: true},
+		{name: "Sibling of a listed nameserver", answers: answers{ns: "ns2.undeveloped.com."}},
+		{name: "Unrelated nameserver", answers: answers{ns: "ns1.example.net."}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := dns.New(&http.Client{Transport: tc.answers}, parking)
+
+			res, err := c.GetDNSValidation(context.Background(), "example.com")
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.parked, res.IsParked)
+		})
+	}
+}
+
+func TestClient_ParkedListError(t *testing.T) {
+	c := dns.New(&http.Client{Transport: answers{a: "192.0.2.1"}}, parkedList{err: errors.New("database is locked")})
+
+	_, err := c.GetDNSValidation(context.Background(), "example.com")
+	assert.ErrorContains(t, err, "database is locked")
+}
@@ -0,0 +1,143 @@
+package dns_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/dns"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func respond(req *http.Request, resp dns.CloudflareResponse) (*http.Response, error) {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_LookupTrace(t *testing.T) {
+	cases := []struct {
+		name      string
+		transport roundTripFunc
+		want      emailchecker.DNSQueryTrace
+	}{
+		{
+			name: "Answer",
+			transport: func(req *http.Request) (*http.Response, error) {
+				return respond(req, dns.CloudflareResponse{Answer: []dns.Answer{{Name: "example.com", Type: 1, TTL: 300, Data: "192.0.2.1"}}})
+			},
+			want: emailchecker.DNSQueryTrace{
+				Answers: []emailchecker.DNSAnswerTrace{{Name: "example.com", Type: 1, TTL: 300, Data: "192.0.2.1"}},
+			},
+		},
+		{
+			name: "NXDOMAIN",
+			transport: func(req *http.Request) (*http.Response, error) {
+				return respond(req, dns.CloudflareResponse{Status: 3})
+			},
+			want: emailchecker.DNSQueryTrace{RCode: 3, Answers: []emailchecker.DNSAnswerTrace{}},
+		},
+		{
+			name: "Upstream error",
+			transport: func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+			want: emailchecker.DNSQueryTrace{Answers: []emailchecker.DNSAnswerTrace{}, Error: "connection refused"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := dns.New(&http.Client{Transport: tc.transport}, parkedList{})
+
+			ctx, trace := emailchecker.WithDNSTrace(context.Background())
+
+			_, _ = c.Lookup(ctx, "example.com", "A")
+
+			require.Len(t, trace.Queries, 1)
+
+			got := trace.Queries[0]
+			assert.Equal(t, "example.com", got.Name)
+			assert.Equal(t, "A", got.Type)
+			assert.Equal(t, "https://one.one.one.one/dns-query", got.Upstream)
+			assert.Equal(t, tc.want.RCode, got.RCode)
+			assert.Equal(t, tc.want.Answers, got.Answers)
+			assert.Contains(t, got.Error, tc.want.Error)
+			assert.False(t, trace.CacheHit)
+		})
+	}
+}
+
+// cachedRepo holds a single fresh record.
+type cachedRepo struct {
+	repo
+	record *emailchecker.DNSRecord
+}
+
+func (c cachedRepo) GetDNSRecord(context.Context, string) (*emailchecker.DNSRecord, error) {
+	return c.record, nil
+}
+
+func TestResolver_TraceCacheHit(t *testing.T) {
+	cachedAt := time.Now().UTC().Add(-time.Hour)
+
+	transport := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("the upstream must not be queried")
+	})
+
+	r := dns.NewResolver(dns.New(&http.Client{Transport: transport}, parkedList{}), cachedRepo{
+		record: &emailchecker.DNSRecord{Data: []byte(`{"domain":"example.com","has_mx":true}`), CreatedAt: cachedAt},
+	})
+
+	ctx, trace := emailchecker.WithDNSTrace(context.Background())
+
+	res, err := r.GetDNSValidationResult(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", res.Domain)
+
+	assert.True(t, trace.CacheHit)
+	require.NotNil(t, trace.CachedAt)
+	assert.Equal(t, cachedAt, *trace.CachedAt)
+	assert.Empty(t, trace.Queries)
+}
+
+func TestResolver_TracedLookupIssuesItsOwnQueries(t *testing.T) {
+	u := newUpstream()
+	r := newResolver(u)
+
+	leader := resolve(context.Background(), r, "example.com")
+	<-u.started
+
+	ctx, trace := emailchecker.WithDNSTrace(context.Background())
+	traced := join(ctx, r, "example.com", 1)[0]
+	close(u.gate)
+
+	require.NoError(t, wait(t, leader).err)
+	require.NoError(t, wait(t, traced).err)
+
+	// The traced lookup did not join the in-flight one.
+	assert.Equal(t, 2, u.count("example.com", "A"))
+	assert.NotEmpty(t, trace.Queries)
+	assert.False(t, trace.CacheHit)
+}
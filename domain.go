@@ -0,0 +1,107 @@
+package emailchecker
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// MatchMode controls which names of a domain are looked up in a domain list.
+type MatchMode int
+
+const (
+	// MatchSuffix matches the domain itself and every parent up to the
+	// organizational domain. This is the default for all lists.
+	MatchSuffix MatchMode = iota
+	// MatchOrganizational matches the domain itself and its organizational domain.
+	MatchOrganizational
+	// MatchExact matches the domain itself only.
+	MatchExact
+)
+
+func ParseMatchMode(s string) (MatchMode, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "suffix":
+		return MatchSuffix, true
+	case "organizational", "org":
+		return MatchOrganizational, true
+	case "exact":
+		return MatchExact, true
+	default:
+		return MatchSuffix, false
+	}
+}
+
+func (m MatchMode) String() string {
+	switch m {
+	case MatchOrganizational:
+		return "organizational"
+	case MatchExact:
+		return "exact"
+	default:
+		return "suffix"
+	}
+}
+
+// Domain is the normalized view of an email domain that is shared by all
+// checkers, so that every list is matched against the same names.
+type Domain struct {
+	// Name is the full domain, lowercased and without a trailing dot.
+	Name string `json:"name"`
+	// Organizational is the registrable domain (eTLD+1) of Name. It equals
+	// Name when Name is itself registrable or cannot be parsed.
+	Organizational string `json:"organizational"`
+	// PublicSuffix is the effective TLD of Name.
+	PublicSuffix string `json:"public_suffix"`
+	// Parents lists the parents of Name, nearest first, ending with the
+	// organizational domain. It is empty when Name is the organizational domain.
+	Parents []string `json:"parents,omitempty"`
+}
+
+func NewDomain(name string) Domain {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+
+	d := Domain{
+		Name:           name,
+		Organizational: name,
+	}
+
+	d.PublicSuffix, _ = publicsuffix.PublicSuffix(name)
+
+	org, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil || org == name {
+		return d
+	}
+
+	d.Organizational = org
+
+	rest := name
+	for rest != org {
+		idx := strings.Index(rest, ".")
+		if idx < 0 {
+			break
+		}
+
+		rest = rest[idx+1:]
+		d.Parents = append(d.Parents, rest)
+	}
+
+	return d
+}
+
+// Candidates returns the names that should be looked up for the given mode,
+// most specific first.
+func (d Domain) Candidates(mode MatchMode) []string {
+	switch mode {
+	case MatchExact:
+		return []string{d.Name}
+	case MatchOrganizational:
+		if d.Organizational == d.Name {
+			return []string{d.Name}
+		}
+
+		return []string{d.Name, d.Organizational}
+	default:
+		return append([]string{d.Name}, d.Parents...)
+	}
+}
@@ -0,0 +1,50 @@
+package emailchecker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emailchecker"
+)
+
+func TestNewDomain(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		domain         string
+		organizational string
+		publicSuffix   string
+		parents        []string
+	}{
+		{name: "Registrable domain", input: "gmail.com", domain: "gmail.com", organizational: "gmail.com", publicSuffix: "com"},
+		{name: "Subdomain", input: "cs.stanford.edu", domain: "cs.stanford.edu", organizational: "stanford.edu", publicSuffix: "edu", parents: []string{"stanford.edu"}},
+		{name: "Multi-label suffix", input: "mail.yahoo.co.jp", domain: "mail.yahoo.co.jp", organizational: "yahoo.co.jp", publicSuffix: "co.jp", parents: []string{"yahoo.co.jp"}},
+		{name: "Deep subdomain", input: "a.b.example.co.uk", domain: "a.b.example.co.uk", organizational: "example.co.uk", publicSuffix: "co.uk", parents: []string{"b.example.co.uk", "example.co.uk"}},
+		{name: "Normalized", input: " Mail.Example.COM. ", domain: "mail.example.com", organizational: "example.com", publicSuffix: "com", parents: []string{"example.com"}},
+		{name: "Public suffix only", input: "co.uk", domain: "co.uk", organizational: "co.uk", publicSuffix: "co.uk"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := emailchecker.NewDomain(tc.input)
+
+			assert.Equal(t, tc.domain, d.Name)
+			assert.Equal(t, tc.organizational, d.Organizational)
+			assert.Equal(t, tc.publicSuffix, d.PublicSuffix)
+			assert.Equal(t, tc.parents, d.Parents)
+		})
+	}
+}
+
+func TestDomain_Candidates(t *testing.T) {
+	d := emailchecker.NewDomain("a.b.example.co.uk")
+
+	assert.Equal(t, []string{"a.b.example.co.uk", "b.example.co.uk", "example.co.uk"}, d.Candidates(emailchecker.MatchSuffix))
+	assert.Equal(t, []string{"a.b.example.co.uk", "example.co.uk"}, d.Candidates(emailchecker.MatchOrganizational))
+	assert.Equal(t, []string{"a.b.example.co.uk"}, d.Candidates(emailchecker.MatchExact))
+
+	org := emailchecker.NewDomain("example.com")
+	assert.Equal(t, []string{"example.com"}, org.Candidates(emailchecker.MatchSuffix))
+	assert.Equal(t, []string{"example.com"}, org.Candidates(emailchecker.MatchOrganizational))
+}
@@ -2,54 +2,135 @@ package edu
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
 )
 
 type repo interface {
-	IsEducationalDomain(ctx context.Context, domain string) (bool, error)
-	UpdateEducationalDomains(ctx context.Context, domains []string) error
-	NeedsEduRefresh(ctx context.Context) (bool, error)
+	GetEducationalInstitution(ctx context.Context, domains []string) (*emailchecker.EducationalInstitution, error)
+	UpdateEducationalDomains(ctx context.Context, institutions []emailchecker.EducationalInstitution, guard *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error)
+	NeedsEduRefresh(ctx context.Context, maxAge time.Duration) (bool, error)
+	MarkEduRefreshed(ctx context.Context) error
+	RecordRefresh(ctx context.Context, list emailchecker.ListName, refreshErr error) error
 }
 
 type fetcher interface {
-	FetchEducationalDomains(ctx context.Context) ([]string, error)
+	// FetchEducationalDomains returns httpext.ErrNotModified when the list
+	// did not change since the last committed fetch.
+	FetchEducationalDomains(ctx context.Context) ([]emailchecker.EducationalInstitution, error)
+	// Commit persists the cache validators of the last fetch.
+	Commit(ctx context.Context) error
+	// Sources returns the locations the list is downloaded from.
+	Sources() []emailchecker.DataSource
+}
+
+// DefaultGuard expects the several thousand university domains of the
+// upstream list.
+var DefaultGuard = emailchecker.ListGuard{
+	MinRows:   1000,
+	MaxShrink: 0.3,
+	Samples:   []string{"mit.edu"},
+}
+
+type Option func(*EducationalDomainChecker)
+
+// WithMatchMode sets which names of a domain are matched against the list.
+func WithMatchMode(mode emailchecker.MatchMode) Option {
+	return func(e *EducationalDomainChecker) {
+		e.matchMode = mode
+	}
+}
+
+// WithGuard replaces DefaultGuard.
+func WithGuard(guard emailchecker.ListGuard) Option {
+	return func(e *EducationalDomainChecker) {
+		e.guard = guard
+	}
 }
 
 type EducationalDomainChecker struct {
-	repo    repo
-	fetcher fetcher
+	repo      repo
+	fetcher   fetcher
+	matchMode emailchecker.MatchMode
+	guard     emailchecker.ListGuard
 }
 
-func New(repo repo, fetcher fetcher) (*EducationalDomainChecker, error) {
+// New creates a checker that serves the stored list. The list is downloaded
+// by UpdateEducationalDomains.
+func New(repo repo, fetcher fetcher, opts ...Option) (*EducationalDomainChecker, error) {
 	ans := EducationalDomainChecker{
-		repo:    repo,
-		fetcher: fetcher,
+		repo:      repo,
+		fetcher:   fetcher,
+		matchMode: emailchecker.MatchSuffix,
+		guard:     DefaultGuard,
 	}
 
-	if err := ans.UpdateEducationalDomains(context.Background()); err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(&ans)
 	}
 
 	return &ans, nil
 }
 
-func (e *EducationalDomainChecker) IsEducationalDomain(ctx context.Context, domain string) (bool, error) {
-	return e.repo.IsEducationalDomain(ctx, domain)
+func (e *EducationalDomainChecker) GetEducationalInstitution(ctx context.Context, domain emailchecker.Domain) (*emailchecker.EducationalInstitution, error) {
+	return e.repo.GetEducationalInstitution(ctx, domain.Candidates(e.matchMode))
+}
+
+// UpdateEducationalDomains refreshes the list when its data is older than
+// maxAge; a zero maxAge forces the refresh.
+func (e *EducationalDomainChecker) UpdateEducationalDomains(ctx context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	return emailchecker.RecordRefresh(ctx, e.repo, emailchecker.ListEducational, func(ctx context.Context) (*emailchecker.ListRefreshResult, error) {
+		return e.refresh(ctx, maxAge)
+	})
+}
+
+func (e *EducationalDomainChecker) Sources() []emailchecker.DataSource {
+	return e.fetcher.Sources()
 }
 
-func (e *EducationalDomainChecker) UpdateEducationalDomains(ctx context.Context) error {
-	needsRefresh, err := e.repo.NeedsEduRefresh(ctx)
+func (e *EducationalDomainChecker) refresh(ctx context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	start := time.Now()
+
+	needsRefresh, err := e.repo.NeedsEduRefresh(ctx, maxAge)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !needsRefresh {
-		return nil
+		return &emailchecker.ListRefreshResult{List: emailchecker.ListEducational, Skipped: true}, nil
+	}
+
+	institutions, err := e.fetcher.FetchEducationalDomains(ctx)
+	if errors.Is(err, httpext.ErrNotModified) {
+		if err := e.repo.MarkEduRefreshed(ctx); err != nil {
+			return nil, err
+		}
+
+		return &emailchecker.ListRefreshResult{
+			List:        emailchecker.ListEducational,
+			NotModified: true,
+			Elapsed:     time.Since(start),
+		}, nil
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	domains, err := e.fetcher.FetchEducationalDomains(ctx)
+	result, err := e.repo.UpdateEducationalDomains(ctx, institutions, &e.guard)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return e.repo.UpdateEducationalDomains(ctx, domains)
+	if err := e.fetcher.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	result.List = emailchecker.ListEducational
+	result.Elapsed = time.Since(start)
+
+	return result, nil
 }
@@ -3,29 +3,32 @@ package edu
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
 )
 
+const listURL = "https://raw.githubusercontent.com/Hipo/university-domains-list/master/world_universities_and_domains.json"
+
 type EduFetcher struct {
-	client *http.Client
+	client *httpext.ConditionalClient
 }
 
-func NewEduFetcher(client *http.Client) *EduFetcher {
+func NewEduFetcher(client *http.Client, store httpext.ValidatorStore) *EduFetcher {
 	return &EduFetcher{
-		client: client,
+		client: httpext.NewConditionalClient(client, store),
 	}
 }
 
-func (f *EduFetcher) FetchEducationalDomains(ctx context.Context) ([]string, error) {
-	const u = "https://raw.githubusercontent.com/Hipo/university-domains-list/master/world_universities_and_domains.json"
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-	if err != nil {
-		return nil, err
-	}
+// FetchEducationalDomains returns an entry for each domain of each
+// institution.
+func (f *EduFetcher) FetchEducationalDomains(ctx context.Context) ([]emailchecker.EducationalInstitution, error) {
+	f.client.Reset()
 
-	resp, err := f.client.Do(req)
+	resp, err := f.client.Get(ctx, listURL, true)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +39,11 @@ func (f *EduFetcher) FetchEducationalDomains(ctx context.Context) ([]string, err
 	}()
 
 	type item struct {
-		Domains []string `json:"domains"`
+		Domains      []string `json:"domains"`
+		Name         string   `json:"name"`
+		Country      string   `json:"country"`
+		AlphaTwoCode string   `json:"alpha_two_code"`
+		WebPages     []string `json:"web_pages"`
 	}
 
 	var items []item
@@ -44,14 +51,30 @@ func (f *EduFetcher) FetchEducationalDomains(ctx context.Context) ([]string, err
 		return nil, err
 	}
 
-	ans := make([]string, 0, len(items))
+	ans := make([]emailchecker.EducationalInstitution, 0, len(items))
 	for _, i := range items {
-		ans = append(ans, i.Domains...)
+		for _, domain := range i.Domains {
+			ans = append(ans, emailchecker.EducationalInstitution{
+				Domain:       domain,
+				Name:         i.Name,
+				Country:      i.Country,
+				AlphaTwoCode: i.AlphaTwoCode,
+				WebPages:     i.WebPages,
+			})
+		}
 	}
 
 	if len(ans) == 0 {
-		return nil, nil
+		return nil, fmt.Errorf("no domains found in educational list")
 	}
 
 	return ans, nil
 }
+
+func (f *EduFetcher) Commit(ctx context.Context) error {
+	return f.client.Commit(ctx)
+}
+
+func (f *EduFetcher) Sources() []emailchecker.DataSource {
+	return []emailchecker.DataSource{{Name: "university-domains-list", Location: listURL}}
+}
@@ -0,0 +1,67 @@
+package edu_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/edu"
+	"emailchecker/pkg/httpext"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type noValidators struct{}
+
+func (noValidators) GetValidators(context.Context, string) (httpext.Validators, error) {
+	return httpext.Validators{}, nil
+}
+
+func (noValidators) SetValidators(context.Context, string, httpext.Validators) error {
+	return nil
+}
+
+func TestEduFetcher_FetchEducationalDomains(t *testing.T) {
+	const list = `[{
+		"name": "Massachusetts Institute of Technology",
+		"country": "United States",
+		"alpha_two_code": "US",
+		"domains": ["mit.edu", "alum.mit.edu"],
+		"web_pages": ["https://web.mit.edu/"],
+		"state-province": null
+	}]`
+
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(list)),
+			Request:    req,
+		}, nil
+	})}
+
+	got, err := edu.NewEduFetcher(client, noValidators{}).FetchEducationalDomains(context.Background())
+	require.NoError(t, err)
+
+	// Every domain of an institution carries its details.
+	institution := func(domain string) emailchecker.EducationalInstitution {
+		return emailchecker.EducationalInstitution{
+			Domain:       domain,
+			Name:         "Massachusetts Institute of Technology",
+			Country:      "United States",
+			AlphaTwoCode: "US",
+			WebPages:     []string{"https://web.mit.edu/"},
+		}
+	}
+
+	assert.Equal(t, []emailchecker.EducationalInstitution{institution("mit.edu"), institution("alum.mit.edu")}, got)
+}
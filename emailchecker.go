@@ -2,7 +2,9 @@ package emailchecker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -10,6 +12,14 @@ import (
 	"emailchecker/pkg/log"
 )
 
+const (
+	defaultDisposableMinDomainAge = 30 * 24 * time.Hour
+	domainAgeTimeout              = 2 * time.Second
+	// sharedInfraThreshold is the infrastructure match confidence above
+	// which strict mode flags a domain.
+	sharedInfraThreshold = 0.8
+)
+
 type EmailChecker struct {
 	disposableSvc   DisposableChecker
 	dnsSvc          DNSChecker
@@ -17,6 +27,15 @@ type EmailChecker struct {
 	educationalSvc  EducationalDomainChecker
 	emailPatternSvc EmailPatternChecker
 	analysisSvc     Analyzer
+	overrideSvc     OverrideStore
+	domainAgeSvc    DomainAgeChecker
+	infraSvc        InfrastructureLearner
+	listSvc         ListStore
+	providerSvc     ProviderClassifier
+	sectorSvc       SectorClassifier
+	confusableSvc   ConfusableChecker
+	schedules       map[ListName]RefreshSchedule
+	readiness       *readinessTracker
 }
 
 func New(cfg *Config) (*EmailChecker, error) {
@@ -31,13 +50,22 @@ func New(cfg *Config) (*EmailChecker, error) {
 		educationalSvc:  cfg.EducationalDomainService,
 		emailPatternSvc: cfg.EmailPatternService,
 		analysisSvc:     cfg.AnalysisService,
+		overrideSvc:     cfg.OverrideService,
+		domainAgeSvc:    cfg.DomainAgeService,
+		infraSvc:        cfg.InfrastructureService,
+		listSvc:         cfg.ListService,
+		providerSvc:     cfg.ProviderService,
+		sectorSvc:       cfg.SectorService,
+		confusableSvc:   cfg.ConfusableService,
+		schedules:       cfg.RefreshSchedules,
+		readiness:       newReadinessTracker(),
 	}
 
 	return &ans, nil
 }
 
 func (e *EmailChecker) Close() error {
-	return nil
+	return e.infraSvc.Close()
 }
 
 func (e *EmailChecker) Check(ctx context.Context, params EmailCheckParams) (EmailCheckResult, error) {
@@ -46,6 +74,10 @@ func (e *EmailChecker) Check(ctx context.Context, params EmailCheckParams) (Emai
 		params.DisposableTimeout = 200 * time.Millisecond
 	}
 
+	if params.DisposableMinDomainAge == 0 {
+		params.DisposableMinDomainAge = defaultDisposableMinDomainAge
+	}
+
 	result := EmailCheckResult{
 		Email: params.Email,
 	}
@@ -61,68 +93,473 @@ func (e *EmailChecker) Check(ctx context.Context, params EmailCheckParams) (Emai
 		return EmailCheckResult{}, fmt.Errorf("invalid email address: %s", email)
 	}
 
-	domain := email[idx+1:]
+	domain := NewDomain(email[idx+1:])
+	result.Domain = domain
 
 	e.performDNSCheck(ctx, params, &wg, &result, &mu, domain)
 	e.performDisposableCheck(ctx, params, &wg, &result, &mu, domain)
 	e.performWellKnownCheck(ctx, params, &wg, &result, &mu, domain)
 	e.performEducationalCheck(ctx, params, &wg, &result, &mu, domain)
+	e.performSectorCheck(ctx, params, &wg, &result, &mu, domain)
 	e.performEmailPatternCheck(ctx, params, &wg, &result, &mu, email)
+	e.performConfusableCheck(ctx, params, &wg, &result, &mu, email[:idx], domain)
+
+	var registeredAt *time.Time
+	e.performDomainAgeLookup(ctx, params, &wg, &registeredAt, domain)
+
+	var provider *EmailProvider
+	e.performProviderLookup(ctx, &wg, &provider, domain)
 
 	wg.Wait()
+
+	if params.DisposableStrict {
+		e.applyStrictDisposable(ctx, params, &result, registeredAt)
+	}
+
+	if provider != nil {
+		result.ProviderType = provider.Type
+	}
+
+	result.IsFreeEmail = result.ProviderType.IsFree()
 	result.Elapsed = time.Since(start)
 
+	e.infraSvc.Observe(&result)
+
+	readiness, err := e.Readiness(ctx)
+	if err != nil {
+		log.Warn(ctx, "Could not get readiness", "error", err.Error())
+	}
+
+	result.Readiness = readiness
+
 	result.Analysis = e.analysisSvc.Analyze(ctx, &result)
 
 	return result, nil
 }
 
-func (e *EmailChecker) UpdateDB(ctx context.Context) error {
-	t0 := time.Now().UTC()
+// listUpdate refreshes one domain list.
+type listUpdate struct {
+	list   ListName
+	update func(context.Context, time.Duration) (*ListRefreshResult, error)
+}
 
-	if err := e.educationalSvc.UpdateEducationalDomains(ctx); err != nil {
-		return err
+func (e *EmailChecker) listUpdates() []listUpdate {
+	return []listUpdate{
+		{ListEducational, e.educationalSvc.UpdateEducationalDomains},
+		{ListTop, e.wellKnownSvc.UpdateWellKnownList},
+		{ListDisposable, e.disposableSvc.UpdateDisposableList},
 	}
+}
 
-	t1 := time.Now().UTC()
+func (e *EmailChecker) schedule(list ListName) RefreshSchedule {
+	if s, ok := e.schedules[list]; ok {
+		return s
+	}
 
-	log.Info(ctx, "Educational domains updated", "elapsed", t1.Sub(t0).String())
+	return DefaultRefreshSchedule
+}
 
-	if err := e.wellKnownSvc.UpdateWellKnownList(ctx); err != nil {
-		return err
+// UpdateDB refreshes the educational, well-known and disposable lists, or
+// the lists of opts, and reports what each refresh changed. Lists whose data
+// is not due are skipped unless opts.Force is set. A failing list does not
+// stop the others; the errors are joined.
+func (e *EmailChecker) UpdateDB(ctx context.Context, opts UpdateOptions) ([]ListRefreshResult, error) {
+	for _, list := range opts.Lists {
+		if _, err := ParseListName(string(list)); err != nil {
+			return nil, err
+		}
+
+		if !list.downloaded() {
+			return nil, fmt.Errorf("the %s list is not downloaded, import it instead", list)
+		}
 	}
 
-	t2 := time.Now().UTC()
+	var (
+		results []ListRefreshResult
+		errs    []error
+	)
+
+	for _, u := range e.listUpdates() {
+		if len(opts.Lists) > 0 && !slices.Contains(opts.Lists, u.list) {
+			continue
+		}
 
-	log.Info(ctx, "Well-known domains updated", "elapsed", t2.Sub(t1).String())
+		maxAge := e.schedule(u.list).Interval
+		if opts.Force {
+			maxAge = 0
+		}
 
-	if err := e.disposableSvc.UpdateDisposableList(ctx); err != nil {
-		return err
+		result, err := e.updateList(ctx, u, maxAge)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u.list, err))
+
+			continue
+		}
+
+		results = append(results, *result)
 	}
 
-	t3 := time.Now().UTC()
-	log.Info(ctx, "Disposable domains updated", "elapsed", t3.Sub(t2).String())
+	return results, errors.Join(errs...)
+}
+
+func (e *EmailChecker) updateList(ctx context.Context, u listUpdate, maxAge time.Duration) (*ListRefreshResult, error) {
+	e.readiness.start(u.list)
+
+	result, err := u.update(ctx, maxAge)
+
+	e.readiness.finish(u.list, err)
+
+	if err != nil {
+		log.Warn(ctx, "Failed to refresh domain list", "list", u.list, "error", err.Error())
+
+		return nil, err
+	}
 
-	return nil
+	log.Info(ctx, "Domain list refreshed",
+		"list", result.List,
+		"skipped", result.Skipped,
+		"not_modified", result.NotModified,
+		"added", result.Added,
+		"removed", result.Removed,
+		"updated", result.Updated,
+		"elapsed", result.Elapsed.String(),
+	)
+
+	return result, nil
+}
+
+// Readiness reports the state of every domain list.
+func (e *EmailChecker) Readiness(ctx context.Context) (*Readiness, error) {
+	return e.readiness.readiness(ctx, e.listSvc, func(list ListName) time.Duration {
+		return e.schedule(list).maxAge()
+	})
+}
+
+func (e *EmailChecker) ListVersions(ctx context.Context) ([]ListVersion, error) {
+	return e.listSvc.ListVersions(ctx)
+}
+
+func (e *EmailChecker) ListSnapshots(ctx context.Context, list ListName) ([]ListSnapshot, error) {
+	return e.listSvc.ListSnapshots(ctx, list)
+}
+
+// Sources reports for every domain list where its data comes from, the
+// loaded version and the outcome of its last refreshes.
+func (e *EmailChecker) Sources(ctx context.Context) ([]SourceStatus, error) {
+	versions, err := e.listSvc.ListVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := map[ListName][]DataSource{
+		ListDisposable:  e.disposableSvc.Sources(),
+		ListTop:         e.wellKnownSvc.Sources(),
+		ListEducational: e.educationalSvc.Sources(),
+	}
+
+	ans := make([]SourceStatus, 0, len(versions))
+
+	for _, v := range versions {
+		status, err := e.listSvc.RefreshStatus(ctx, v.List)
+		if err != nil {
+			return nil, err
+		}
+
+		imp, err := e.listSvc.LastImport(ctx, v.List)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, SourceStatus{
+			ListVersion:   v,
+			RefreshStatus: *status,
+			Sources:       sources[v.List],
+			Import:        imp,
+		})
+	}
+
+	return ans, nil
 }
 
-func (e *EmailChecker) PeriodicUpdate(ctx context.Context, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// ImportList loads a list from a local file, for deployments that cannot
+// reach the upstream sources. The list counts as refreshed afterwards.
+func (e *EmailChecker) ImportList(ctx context.Context, imp ListImport, data ListData) (*ListRefreshResult, error) {
+	if err := imp.Validate(); err != nil {
+		return nil, err
+	}
+
+	if data.Len(imp.List) == 0 {
+		return nil, fmt.Errorf("%w: %s has no %s entries", ErrInvalidImport, imp.File, imp.List)
+	}
+
+	start := time.Now()
+
+	imp.Entries = data.Len(imp.List)
+	imp.ImportedAt = start.UTC()
+
+	result, err := e.listSvc.ImportList(ctx, &imp, data)
+	if err != nil {
+		return nil, err
+	}
+
+	e.readiness.invalidate()
+
+	result.List = imp.List
+	result.Elapsed = time.Since(start)
+
+	log.Info(ctx, "Domain list imported",
+		"list", imp.List,
+		"file", imp.File,
+		"mode", imp.Mode,
+		"added", result.Added,
+		"removed", result.Removed,
+		"updated", result.Updated,
+	)
+
+	return result, nil
+}
+
+// RollbackList replaces a list with its latest snapshot.
+func (e *EmailChecker) RollbackList(ctx context.Context, list ListName) (*ListRefreshResult, error) {
+	start := time.Now()
+
+	result, err := e.listSvc.RollbackList(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	e.readiness.invalidate()
+
+	result.List = list
+	result.Elapsed = time.Since(start)
+
+	log.Info(ctx, "Domain list rolled back",
+		"list", list,
+		"added", result.Added,
+		"removed", result.Removed,
+		"updated", result.Updated,
+	)
+
+	return result, nil
+}
+
+// PeriodicUpdate refreshes every list right away and then on its own
+// schedule, until ctx is done. Lists are refreshed independently, so a slow
+// or failing source does not delay the others.
+func (e *EmailChecker) PeriodicUpdate(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, u := range e.listUpdates() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			e.periodicListUpdate(ctx, u)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (e *EmailChecker) periodicListUpdate(ctx context.Context, u listUpdate) {
+	schedule := e.schedule(u.list)
 
 	for {
+		// Errors are logged by updateList; the list is retried on schedule.
+		_, _ = e.updateList(ctx, u, schedule.Interval)
+
+		timer := time.NewTimer(schedule.wait())
+
 		select {
-		case <-ticker.C:
-			if err := e.UpdateDB(ctx); err != nil {
-				log.ErrorWithMessage(ctx, "Failed to update email checker database", err)
-			}
+		case <-timer.C:
 		case <-ctx.Done():
+			timer.Stop()
+
 			return
 		}
 	}
 }
 
-func (e *EmailChecker) performDisposableCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain string) {
+func (e *EmailChecker) PurgeDNSCache(ctx context.Context, domain string, includeSubdomains bool) (int64, error) {
+	return e.dnsSvc.PurgeDNSCache(ctx, domain, includeSubdomains)
+}
+
+func (e *EmailChecker) RefreshDNS(ctx context.Context, domain string) (*DNSValidationResult, error) {
+	return e.dnsSvc.RefreshDNSValidationResult(ctx, domain)
+}
+
+func (e *EmailChecker) DNSCacheStats(ctx context.Context) (*DNSCacheStats, error) {
+	return e.dnsSvc.DNSCacheStats(ctx)
+}
+
+// PrewarmDNSCache resolves the given domains with at most concurrency lookups
+// in flight. Domains that are already cached are served from the cache.
+// Individual failures are counted and do not stop the run.
+func (e *EmailChecker) PrewarmDNSCache(ctx context.Context, domains []string, concurrency int) *DNSPrewarmResult {
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	result := &DNSPrewarmResult{}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, concurrency)
+	)
+
+	seen := make(map[string]struct{}, len(domains))
+
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+		if domain == "" {
+			continue
+		}
+
+		if _, ok := seen[domain]; ok {
+			continue
+		}
+
+		seen[domain] = struct{}{}
+		result.Total++
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			result.Failed++
+			result.FailedDomains = append(result.FailedDomains, domain)
+
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := e.dnsSvc.GetDNSValidationResult(ctx, domain)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				log.Warn(ctx, "DNS prewarm failed", "domain", domain, "error", err.Error())
+				result.Failed++
+				result.FailedDomains = append(result.FailedDomains, domain)
+
+				return
+			}
+
+			result.Resolved++
+		}()
+	}
+
+	wg.Wait()
+
+	return result
+}
+
+// AddOverride creates or replaces the override of a domain on a list.
+func (e *EmailChecker) AddOverride(ctx context.Context, o DomainOverride) (*DomainOverride, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	o.Domain = NewDomain(o.Domain).Name
+	o.CreatedAt = time.Now().UTC()
+
+	if err := e.overrideSvc.UpsertOverride(ctx, o); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (e *EmailChecker) RemoveOverride(ctx context.Context, list ListName, domain string) (bool, error) {
+	return e.overrideSvc.DeleteOverride(ctx, list, NewDomain(domain).Name)
+}
+
+func (e *EmailChecker) ListOverrides(ctx context.Context, list ListName) ([]DomainOverride, error) {
+	return e.overrideSvc.ListOverrides(ctx, list)
+}
+
+// SetProvider creates or replaces the local provider entry of a domain.
+func (e *EmailChecker) SetProvider(ctx context.Context, p EmailProvider) (*EmailProvider, error) {
+	p.Domain = NewDomain(p.Domain).Name
+	p.Source = ProviderSourceLocal
+	p.UpdatedAt = time.Now().UTC()
+
+	if err := e.providerSvc.SetProvider(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (e *EmailChecker) RemoveProvider(ctx context.Context, domain string) (bool, error) {
+	return e.providerSvc.RemoveProvider(ctx, NewDomain(domain).Name)
+}
+
+func (e *EmailChecker) ListProviders(ctx context.Context, t ProviderType) ([]EmailProvider, error) {
+	return e.providerSvc.ListProviders(ctx, t)
+}
+
+func (e *EmailChecker) ListCandidates(ctx context.Context, status CandidateStatus) ([]DisposableCandidate, error) {
+	return e.infraSvc.ListCandidates(ctx, status)
+}
+
+// PromoteCandidate adds a candidate to the disposable list as a local
+// override and marks it promoted. It returns nil when there is no such
+// candidate.
+func (e *EmailChecker) PromoteCandidate(ctx context.Context, domain, reviewer string) (*DisposableCandidate, error) {
+	if strings.TrimSpace(reviewer) == "" {
+		return nil, fmt.Errorf("%w: reviewer is required", ErrInvalidReview)
+	}
+
+	domain = NewDomain(domain).Name
+
+	candidate, err := e.infraSvc.GetCandidate(ctx, domain)
+	if err != nil || candidate == nil {
+		return nil, err
+	}
+
+	_, err = e.AddOverride(ctx, DomainOverride{
+		List:   ListDisposable,
+		Domain: domain,
+		Action: OverrideInclude,
+		Reason: fmt.Sprintf("promoted infrastructure candidate (confidence %.2f)", candidate.Confidence),
+		Author: reviewer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := e.infraSvc.SetCandidateStatus(ctx, domain, CandidatePromoted, reviewer); err != nil {
+		return nil, err
+	}
+
+	return e.infraSvc.GetCandidate(ctx, domain)
+}
+
+// RejectCandidate marks a candidate as not disposable. Rejected candidates
+// keep their status when they are seen again.
+func (e *EmailChecker) RejectCandidate(ctx context.Context, domain, reviewer string) (*DisposableCandidate, error) {
+	if strings.TrimSpace(reviewer) == "" {
+		return nil, fmt.Errorf("%w: reviewer is required", ErrInvalidReview)
+	}
+
+	domain = NewDomain(domain).Name
+
+	ok, err := e.infraSvc.SetCandidateStatus(ctx, domain, CandidateRejected, reviewer)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return e.infraSvc.GetCandidate(ctx, domain)
+}
+
+func (e *EmailChecker) performDisposableCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain Domain) {
 	if params.SkipDisposable {
 		return
 	}
@@ -135,7 +572,7 @@ func (e *EmailChecker) performDisposableCheck(ctx context.Context, params EmailC
 		disposableCtx, disposableCancel := context.WithTimeout(ctx, params.DisposableTimeout)
 		defer disposableCancel()
 
-		isDisposable, err := e.disposableSvc.IsDisposable(disposableCtx, domain)
+		disposable, err := e.disposableSvc.IsDisposable(disposableCtx, domain)
 
 		elapsed := time.Since(start)
 
@@ -147,12 +584,176 @@ func (e *EmailChecker) performDisposableCheck(ctx context.Context, params EmailC
 		if err != nil {
 			result.Disposable.Err = err
 		} else {
-			result.Disposable.Value = isDisposable
+			result.Disposable.Value = *disposable
+		}
+	}()
+}
+
+// performDomainAgeLookup fetches the registration date of the organizational
+// domain, which only strict mode uses. Lookup failures leave it unknown.
+func (e *EmailChecker) performDomainAgeLookup(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, registeredAt **time.Time, domain Domain) {
+	if !params.DisposableStrict || params.SkipDisposable {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ageCtx, cancel := context.WithTimeout(ctx, domainAgeTimeout)
+		defer cancel()
+
+		date, err := e.domainAgeSvc.GetRegistrationDate(ageCtx, domain.Organizational)
+		if err != nil {
+			log.Debug(ctx, "Could not get domain registration date", "domain", domain.Organizational, "error", err.Error())
+			return
+		}
+
+		*registeredAt = date
+	}()
+}
+
+func (e *EmailChecker) performSectorCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain Domain) {
+	if params.SkipSector {
+		return
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		start := time.Now()
+		sector, err := e.sectorSvc.ClassifySector(ctx, domain)
+
+		elapsed := time.Since(start)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		result.Sector.Checked = true
+		result.Sector.Elapsed = elapsed
+
+		if err != nil {
+			result.Sector.Err = err
+		} else {
+			result.Sector.Value = sector
+		}
+	}()
+}
+
+func (e *EmailChecker) performConfusableCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, localPart string, domain Domain) {
+	if params.SkipConfusable {
+		return
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		start := time.Now()
+		confusable, err := e.confusableSvc.CheckConfusable(ctx, localPart, domain)
+
+		elapsed := time.Since(start)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		result.Confusable.Checked = true
+		result.Confusable.Elapsed = elapsed
+
+		if err != nil {
+			result.Confusable.Err = err
+		} else {
+			result.Confusable.Value = *confusable
 		}
 	}()
 }
 
-func (e *EmailChecker) performDNSCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain string) {
+func (e *EmailChecker) performProviderLookup(ctx context.Context, wg *sync.WaitGroup, provider **EmailProvider, domain Domain) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		p, err := e.providerSvc.GetProvider(ctx, domain)
+		if err != nil {
+			log.Warn(ctx, "Could not get email provider", "domain", domain.Name, "error", err.Error())
+			return
+		}
+
+		*provider = p
+	}()
+}
+
+// applyStrictDisposable looks for disposable signals beyond list membership
+// once all checks are done, since it needs the DNS result.
+func (e *EmailChecker) applyStrictDisposable(ctx context.Context, params EmailCheckParams, result *EmailCheckResult, registeredAt *time.Time) {
+	res := &result.Disposable
+	if !res.Checked || res.Err != nil || res.Value.Disposable || res.Value.Reason == DisposableReasonOverride {
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		res.Elapsed += time.Since(start)
+	}()
+
+	if result.DNS.Checked && result.DNS.Err == nil {
+		for _, mx := range result.DNS.Value.MXRecords {
+			if mx.Disposable {
+				res.Value = DisposableCheckResult{
+					Disposable:    true,
+					Reason:        DisposableReasonDisposableMX,
+					MatchedDomain: mx.Value,
+				}
+
+				return
+			}
+		}
+
+		for _, ns := range result.DNS.Value.NSRecords {
+			disposable, err := e.disposableSvc.IsDisposable(ctx, NewDomain(ns))
+			if err != nil || !disposable.Disposable {
+				continue
+			}
+
+			res.Value = DisposableCheckResult{
+				Disposable:    true,
+				Reason:        DisposableReasonDisposableNS,
+				MatchedDomain: ns,
+				Sources:       disposable.Sources,
+			}
+
+			return
+		}
+	}
+
+	if result.DNS.Checked && result.DNS.Err == nil {
+		match, err := e.infraSvc.Match(ctx, &result.DNS.Value, result.Domain.Name)
+		if err != nil {
+			log.Debug(ctx, "Could not match domain infrastructure", "domain", result.Domain.Name, "error", err.Error())
+		} else if match.Confidence >= sharedInfraThreshold {
+			res.Value = DisposableCheckResult{
+				Disposable: true,
+				Reason:     DisposableReasonSharedInfra,
+				Confidence: match.Confidence,
+			}
+
+			return
+		}
+	}
+
+	if registeredAt != nil && time.Since(*registeredAt) < params.DisposableMinDomainAge {
+		res.Value = DisposableCheckResult{
+			Disposable:   true,
+			Reason:       DisposableReasonRecentlyRegistered,
+			RegisteredAt: registeredAt,
+		}
+	}
+}
+
+func (e *EmailChecker) performDNSCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain Domain) {
 	if params.SkipDNS {
 		return
 	}
@@ -161,13 +762,20 @@ func (e *EmailChecker) performDNSCheck(ctx context.Context, params EmailCheckPar
 	go func() {
 		defer wg.Done()
 
+		dnsCtx := ctx
+
+		var trace *DNSTrace
+		if params.Debug {
+			dnsCtx, trace = WithDNSTrace(ctx)
+		}
+
 		start := time.Now()
-		raw, err := e.dnsSvc.GetDNSValidationResult(ctx, domain)
+		raw, err := e.dnsSvc.GetDNSValidationResult(dnsCtx, domain.Name)
 		if err == nil && raw != nil {
 			for i := range raw.MXRecords {
-				isDisposable, err := e.disposableSvc.IsDisposable(ctx, raw.MXRecords[i].Value)
+				disposable, err := e.disposableSvc.IsDisposable(ctx, NewDomain(raw.MXRecords[i].Value))
 				if err == nil {
-					raw.MXRecords[i].Disposable = isDisposable
+					raw.MXRecords[i].Disposable = disposable.Disposable
 				}
 			}
 		}
@@ -184,11 +792,13 @@ func (e *EmailChecker) performDNSCheck(ctx context.Context, params EmailCheckPar
 			result.DNS.Value = *raw
 		}
 
+		result.DNS.Value.Trace = trace
+
 		result.DNS.Elapsed = elapsed
 	}()
 }
 
-func (e *EmailChecker) performWellKnownCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain string) {
+func (e *EmailChecker) performWellKnownCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain Domain) {
 	if params.SkipWellKnown {
 		return
 	}
@@ -198,7 +808,7 @@ func (e *EmailChecker) performWellKnownCheck(ctx context.Context, params EmailCh
 		defer wg.Done()
 
 		start := time.Now()
-		isWellKnown, err := e.wellKnownSvc.IsWellKnown(ctx, domain)
+		isWellKnown, rank, err := e.wellKnownSvc.IsWellKnown(ctx, domain)
 
 		elapsed := time.Since(start)
 
@@ -212,6 +822,7 @@ func (e *EmailChecker) performWellKnownCheck(ctx context.Context, params EmailCh
 			result.WellKnown.Err = err
 		} else {
 			result.WellKnown.Value = isWellKnown
+			result.WellKnownRank = rank
 		}
 	}()
 }
@@ -227,7 +838,7 @@ func (e *EmailChecker) performEmailPatternCheck(ctx context.Context, params Emai
 		defer wg.Done()
 
 		start := time.Now()
-		patternResult, err := e.emailPatternSvc.Check(ctx, email)
+		patternResult, err := e.checkPattern(ctx, email, params.PatternOverrides)
 
 		elapsed := time.Since(start)
 
@@ -245,7 +856,36 @@ func (e *EmailChecker) performEmailPatternCheck(ctx context.Context, params Emai
 	}()
 }
 
-func (e *EmailChecker) performEducationalCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain string) {
+// checkPattern checks email with the pattern thresholds of overrides, which
+// the pattern checker must support when any is set.
+func (e *EmailChecker) checkPattern(ctx context.Context, email string, overrides PatternConfig) (*EmailPatternCheckResult, error) {
+	if overrides.IsZero() {
+		return e.emailPatternSvc.Check(ctx, email)
+	}
+
+	checker, ok := e.emailPatternSvc.(PatternOverrideChecker)
+	if !ok {
+		return nil, errors.New("the email pattern checker does not support threshold overrides")
+	}
+
+	return checker.CheckWithOverrides(ctx, email, overrides)
+}
+
+func educationalResult(institution *EducationalInstitution) EducationalCheckResult {
+	if institution == nil {
+		return EducationalCheckResult{}
+	}
+
+	return EducationalCheckResult{
+		Educational:   true,
+		MatchedDomain: institution.Domain,
+		Name:          institution.Name,
+		Country:       institution.Country,
+		AlphaTwoCode:  institution.AlphaTwoCode,
+	}
+}
+
+func (e *EmailChecker) performEducationalCheck(ctx context.Context, params EmailCheckParams, wg *sync.WaitGroup, result *EmailCheckResult, mu *sync.Mutex, domain Domain) {
 	if params.SkipEducationalDomains {
 		return
 	}
@@ -256,7 +896,7 @@ func (e *EmailChecker) performEducationalCheck(ctx context.Context, params Email
 		defer wg.Done()
 
 		start := time.Now()
-		isEducational, err := e.educationalSvc.IsEducationalDomain(ctx, domain)
+		institution, err := e.educationalSvc.GetEducationalInstitution(ctx, domain)
 
 		elapsed := time.Since(start)
 
@@ -269,7 +909,7 @@ func (e *EmailChecker) performEducationalCheck(ctx context.Context, params Email
 		if err != nil {
 			result.Educational.Err = err
 		} else {
-			result.Educational.Value = isEducational
+			result.Educational.Value = educationalResult(institution)
 		}
 	}()
 }
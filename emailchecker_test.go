@@ -0,0 +1,343 @@
+package emailchecker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+// stub serves every service of the checker from its fields. The embedded
+// services are nil; a check that calls them fails the test with a panic.
+type stub struct {
+	emailchecker.DisposableChecker
+	emailchecker.DNSChecker
+	emailchecker.WellKnownChecker
+	emailchecker.EducationalDomainChecker
+	emailchecker.OverrideStore
+	emailchecker.InfrastructureLearner
+	emailchecker.ListStore
+	emailchecker.ProviderClassifier
+
+	// disposable holds the disposable domains, by name.
+	disposable   map[string]*emailchecker.DisposableCheckResult
+	dns          *emailchecker.DNSValidationResult
+	registeredAt *time.Time
+	infraMatch   emailchecker.InfrastructureMatch
+	providers    map[string]*emailchecker.EmailProvider
+	institutions map[string]*emailchecker.EducationalInstitution
+	sectors      map[string]emailchecker.SectorCheckResult
+	versions     func(context.Context) ([]emailchecker.ListVersion, error)
+	// update refreshes a list; nil refreshes nothing.
+	update func(list emailchecker.ListName, maxAge time.Duration) (*emailchecker.ListRefreshResult, error)
+}
+
+func (s *stub) IsDisposable(_ context.Context, domain emailchecker.Domain) (*emailchecker.DisposableCheckResult, error) {
+	if res, ok := s.disposable[domain.Name]; ok {
+		return res, nil
+	}
+
+	return &emailchecker.DisposableCheckResult{}, nil
+}
+
+func (s *stub) Sources() []emailchecker.DataSource {
+	return nil
+}
+
+func (s *stub) refresh(list emailchecker.ListName, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	if s.update == nil {
+		return &emailchecker.ListRefreshResult{List: list}, nil
+	}
+
+	return s.update(list, maxAge)
+}
+
+func (s *stub) UpdateDisposableList(_ context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	return s.refresh(emailchecker.ListDisposable, maxAge)
+}
+
+func (s *stub) UpdateWellKnownList(_ context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	return s.refresh(emailchecker.ListTop, maxAge)
+}
+
+func (s *stub) UpdateEducationalDomains(_ context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	return s.refresh(emailchecker.ListEducational, maxAge)
+}
+
+func (s *stub) GetDNSValidationResult(_ context.Context, domain string) (*emailchecker.DNSValidationResult, error) {
+	if s.dns == nil {
+		return &emailchecker.DNSValidationResult{Domain: domain, HasMX: true}, nil
+	}
+
+	return s.dns, nil
+}
+
+func (s *stub) GetRegistrationDate(context.Context, string) (*time.Time, error) {
+	return s.registeredAt, nil
+}
+
+func (s *stub) IsWellKnown(context.Context, emailchecker.Domain) (bool, int, error) {
+	return false, 0, nil
+}
+
+func (s *stub) GetEducationalInstitution(_ context.Context, domain emailchecker.Domain) (*emailchecker.EducationalInstitution, error) {
+	return s.institutions[domain.Name], nil
+}
+
+func (s *stub) ClassifySector(_ context.Context, domain emailchecker.Domain) (emailchecker.SectorCheckResult, error) {
+	return s.sectors[domain.Name], nil
+}
+
+func (s *stub) CheckConfusable(context.Context, string, emailchecker.Domain) (*emailchecker.ConfusableCheckResult, error) {
+	return &emailchecker.ConfusableCheckResult{}, nil
+}
+
+func (s *stub) GetProvider(_ context.Context, domain emailchecker.Domain) (*emailchecker.EmailProvider, error) {
+	return s.providers[domain.Name], nil
+}
+
+func (s *stub) Observe(*emailchecker.EmailCheckResult) {}
+
+func (s *stub) Match(context.Context, *emailchecker.DNSValidationResult, string) (*emailchecker.InfrastructureMatch, error) {
+	return &s.infraMatch, nil
+}
+
+func (s *stub) ListVersions(ctx context.Context) ([]emailchecker.ListVersion, error) {
+	if s.versions == nil {
+		return nil, nil
+	}
+
+	return s.versions(ctx)
+}
+
+func (s *stub) RollbackList(context.Context, emailchecker.ListName) (*emailchecker.ListRefreshResult, error) {
+	return &emailchecker.ListRefreshResult{}, nil
+}
+
+func (s *stub) RefreshStatus(context.Context, emailchecker.ListName) (*emailchecker.RefreshStatus, error) {
+	return &emailchecker.RefreshStatus{}, nil
+}
+
+func (s *stub) Check(context.Context, string) (*emailchecker.EmailPatternCheckResult, error) {
+	return &emailchecker.EmailPatternCheckResult{}, nil
+}
+
+func (s *stub) Analyze(context.Context, *emailchecker.EmailCheckResult) *emailchecker.AnalysisReport {
+	return &emailchecker.AnalysisReport{}
+}
+
+func newChecker(t *testing.T, s *stub) *emailchecker.EmailChecker {
+	t.Helper()
+
+	return newScheduledChecker(t, s, nil)
+}
+
+func newScheduledChecker(t *testing.T, s *stub, schedules map[emailchecker.ListName]emailchecker.RefreshSchedule) *emailchecker.EmailChecker {
+	t.Helper()
+
+	c, err := emailchecker.New(config(s, schedules))
+	require.NoError(t, err)
+
+	return c
+}
+
+// config serves every service from s.
+func config(s *stub, schedules map[emailchecker.ListName]emailchecker.RefreshSchedule) *emailchecker.Config {
+	return &emailchecker.Config{
+		RefreshSchedules:         schedules,
+		DisposableService:        s,
+		DNSService:               s,
+		WellKnownService:         s,
+		EducationalDomainService: s,
+		EmailPatternService:      s,
+		AnalysisService:          s,
+		OverrideService:          s,
+		DomainAgeService:         s,
+		InfrastructureService:    s,
+		ListService:              s,
+		ProviderService:          s,
+		SectorService:            s,
+		ConfusableService:        s,
+	}
+}
+
+func TestEmailChecker_DisposableStrict(t *testing.T) {
+	const domain = "fresh.example"
+
+	recent := time.Now().Add(-24 * time.Hour)
+	old := time.Now().Add(-365 * 24 * time.Hour)
+
+	// The MX host is checked against the disposable list.
+	disposableMX := func(listed map[string]*emailchecker.DisposableCheckResult) stub {
+		if listed == nil {
+			listed = make(map[string]*emailchecker.DisposableCheckResult)
+		}
+
+		listed["mx.trash.example"] = &emailchecker.DisposableCheckResult{Disposable: true}
+
+		return stub{
+			dns: &emailchecker.DNSValidationResult{
+				Domain:    domain,
+				HasMX:     true,
+				MXRecords: []emailchecker.MXRecord{{Value: "mx.trash.example"}},
+			},
+			disposable: listed,
+		}
+	}
+
+	cases := []struct {
+		name    string
+		strict  bool
+		stub    stub
+		reason  emailchecker.DisposableReason
+		matched string
+	}{
+		{
+			name:    "Listed",
+			stub:    stub{disposable: map[string]*emailchecker.DisposableCheckResult{domain: {Disposable: true, Reason: emailchecker.DisposableReasonListed, MatchedDomain: domain}}},
+			reason:  emailchecker.DisposableReasonListed,
+			matched: domain,
+		},
+		{name: "Disposable MX without strict mode", stub: disposableMX(nil)},
+		{
+			name:    "Disposable MX",
+			strict:  true,
+			stub:    disposableMX(nil),
+			reason:  emailchecker.DisposableReasonDisposableMX,
+			matched: "mx.trash.example",
+		},
+		{
+			name:   "Disposable NS",
+			strict: true,
+			stub: stub{
+				dns:        &emailchecker.DNSValidationResult{Domain: domain, HasMX: true, NSRecords: []string{"ns1.trash.example"}},
+				disposable: map[string]*emailchecker.DisposableCheckResult{"ns1.trash.example": {Disposable: true, Sources: []string{"test"}}},
+			},
+			reason:  emailchecker.DisposableReasonDisposableNS,
+			matched: "ns1.trash.example",
+		},
+		{
+			name:   "Shared infrastructure",
+			strict: true,
+			stub:   stub{infraMatch: emailchecker.InfrastructureMatch{Confidence: 0.9}},
+			reason: emailchecker.DisposableReasonSharedInfra,
+		},
+		{name: "Weakly shared infrastructure", strict: true, stub: stub{infraMatch: emailchecker.InfrastructureMatch{Confidence: 0.5}}},
+		{
+			name:   "Recently registered",
+			strict: true,
+			stub:   stub{registeredAt: &recent},
+			reason: emailchecker.DisposableReasonRecentlyRegistered,
+		},
+		{name: "Old domain", strict: true, stub: stub{registeredAt: &old}},
+		{
+			name:   "Excluded by an override",
+			strict: true,
+			stub: func() stub {
+				s := disposableMX(map[string]*emailchecker.DisposableCheckResult{
+					domain: {Reason: emailchecker.DisposableReasonOverride, MatchedDomain: domain},
+				})
+				s.registeredAt = &recent
+
+				return s
+			}(),
+			reason:  emailchecker.DisposableReasonOverride,
+			matched: domain,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newChecker(t, &tc.stub)
+
+			res, err := c.Check(context.Background(), emailchecker.EmailCheckParams{
+				Email:            "john@" + domain,
+				DisposableStrict: tc.strict,
+			})
+			require.NoError(t, err)
+
+			got := res.Disposable.Value
+			assert.Equal(t, tc.reason, got.Reason)
+			assert.Equal(t, tc.matched, got.MatchedDomain)
+			assert.Equal(t, tc.reason != "" && tc.reason != emailchecker.DisposableReasonOverride, got.Disposable)
+		})
+	}
+}
+
+func TestEmailChecker_Provider(t *testing.T) {
+	cases := []struct {
+		name     string
+		provider *emailchecker.EmailProvider
+		want     emailchecker.ProviderType
+		free     bool
+	}{
+		{name: "Not listed"},
+		{name: "Free webmail", provider: &emailchecker.EmailProvider{Type: emailchecker.ProviderFreeWebmail}, want: emailchecker.ProviderFreeWebmail, free: true},
+		{name: "ISP", provider: &emailchecker.EmailProvider{Type: emailchecker.ProviderISP}, want: emailchecker.ProviderISP, free: true},
+		{name: "Corporate", provider: &emailchecker.EmailProvider{Type: emailchecker.ProviderCorporate}, want: emailchecker.ProviderCorporate},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &stub{providers: map[string]*emailchecker.EmailProvider{}}
+			if tc.provider != nil {
+				s.providers["mail.example"] = tc.provider
+			}
+
+			res, err := newChecker(t, s).Check(context.Background(), emailchecker.EmailCheckParams{Email: "john@mail.example"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res.ProviderType)
+			assert.Equal(t, tc.free, res.IsFreeEmail)
+		})
+	}
+}
+
+func TestEmailChecker_Educational(t *testing.T) {
+	cases := []struct {
+		name        string
+		institution *emailchecker.EducationalInstitution
+		want        emailchecker.EducationalCheckResult
+	}{
+		{name: "Not listed"},
+		{
+			name: "Listed",
+			institution: &emailchecker.EducationalInstitution{
+				Domain:       "uni.example",
+				Name:         "Example University",
+				Country:      "Canada",
+				AlphaTwoCode: "CA",
+				WebPages:     []string{"https://uni.example/"},
+			},
+			want: emailchecker.EducationalCheckResult{
+				Educational:   true,
+				MatchedDomain: "uni.example",
+				Name:          "Example University",
+				Country:       "Canada",
+				AlphaTwoCode:  "CA",
+			},
+		},
+		{
+			name:        "Included by an override",
+			institution: &emailchecker.EducationalInstitution{Domain: "uni.example"},
+			want:        emailchecker.EducationalCheckResult{Educational: true, MatchedDomain: "uni.example"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &stub{institutions: map[string]*emailchecker.EducationalInstitution{}}
+			if tc.institution != nil {
+				s.institutions["cs.uni.example"] = tc.institution
+			}
+
+			res, err := newChecker(t, s).Check(context.Background(), emailchecker.EmailCheckParams{Email: "john@cs.uni.example"})
+			require.NoError(t, err)
+			assert.True(t, res.Educational.Checked)
+			assert.Equal(t, tc.want, res.Educational.Value)
+		})
+	}
+}
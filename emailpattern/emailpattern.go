@@ -1,22 +1,38 @@
 package emailpattern
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
+	"os"
 	"regexp"
 	"strings"
 	"unicode"
 
 	"emailchecker"
+	"emailchecker/namemodel"
 )
 
 const (
-	defaultMinLocalPartLength    = 3
-	defaultMaxConsecutiveNumbers = 5
-	defaultMaxSpecialCharRatio   = 0.3
-	highEntropyThreshold         = 3.5
-	minKeyboardSeqLength         = 4
+	defaultMinLocalPartLength     = 3
+	defaultMaxConsecutiveNumbers  = 5
+	defaultMaxSpecialCharRatio    = 0.3
+	defaultHighEntropyThreshold   = 3.5
+	defaultMinEntropyLength       = 6
+	defaultMinKeyboardSeqLength   = 4
+	defaultMinCaseSwitchLength    = 6
+	defaultMaxCaseSwitchRatio     = 1.0 / 3
+	defaultMinRandomNameLength    = 8
+	defaultMaxLetterDigitSwitches = 2
+	defaultMinDigitRatioLength    = 7
+	defaultMaxNameDigitRatio      = 0.4
+	defaultMaxTrailingDigits      = 4
+	defaultMinScriptLetterRatio   = 0.6
+	defaultMaxGibberishBits       = 5.5
+	defaultMinGibberishLength     = 6
 )
 
 var (
@@ -28,33 +44,106 @@ var (
 	}
 )
 
-type Config struct {
-	MinLocalPartLength    int
-	MaxConsecutiveNumbers int
-	MaxSpecialCharRatio   float64
-}
+type Config = emailchecker.PatternConfig
 
 func DefaultConfig() *Config {
 	return &Config{
-		MinLocalPartLength:    defaultMinLocalPartLength,
-		MaxConsecutiveNumbers: defaultMaxConsecutiveNumbers,
-		MaxSpecialCharRatio:   defaultMaxSpecialCharRatio,
+		MinLocalPartLength:     defaultMinLocalPartLength,
+		MaxConsecutiveNumbers:  defaultMaxConsecutiveNumbers,
+		MaxSpecialCharRatio:    defaultMaxSpecialCharRatio,
+		HighEntropyThreshold:   defaultHighEntropyThreshold,
+		MinEntropyLength:       defaultMinEntropyLength,
+		MinKeyboardSeqLength:   defaultMinKeyboardSeqLength,
+		MinCaseSwitchLength:    defaultMinCaseSwitchLength,
+		MaxCaseSwitchRatio:     defaultMaxCaseSwitchRatio,
+		MinRandomNameLength:    defaultMinRandomNameLength,
+		MaxLetterDigitSwitches: defaultMaxLetterDigitSwitches,
+		MinDigitRatioLength:    defaultMinDigitRatioLength,
+		MaxNameDigitRatio:      defaultMaxNameDigitRatio,
+		MaxTrailingDigits:      defaultMaxTrailingDigits,
+		MinScriptLetterRatio:   defaultMinScriptLetterRatio,
+		MaxGibberishBits:       defaultMaxGibberishBits,
+		MinGibberishLength:     defaultMinGibberishLength,
+	}
+}
+
+// LoadConfig reads a JSON file of thresholds. Thresholds missing from the
+// file keep their default.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+
+	merged := DefaultConfig().Merge(cfg)
+
+	return &merged, nil
 }
 
 type EmailPatternChecker struct {
 	config *Config
+	model  *namemodel.Model
 }
 
-func New() *EmailPatternChecker {
-	return NewWithConfig(DefaultConfig())
+type Option func(*EmailPatternChecker)
+
+// WithNameModel scores local parts with m instead of the embedded name
+// model.
+func WithNameModel(m *namemodel.Model) Option {
+	return func(c *EmailPatternChecker) {
+		c.model = m
+	}
 }
 
-func NewWithConfig(cfg *Config) *EmailPatternChecker {
-	return &EmailPatternChecker{config: cfg}
+func New(opts ...Option) *EmailPatternChecker {
+	return NewWithConfig(DefaultConfig(), opts...)
 }
 
-func (c *EmailPatternChecker) Check(_ context.Context, email string) (*emailchecker.EmailPatternCheckResult, error) {
+// NewWithConfig creates a checker with the thresholds of cfg. Unset
+// thresholds keep their default.
+func NewWithConfig(cfg *Config, opts ...Option) *EmailPatternChecker {
+	merged := DefaultConfig().Merge(*cfg)
+
+	c := &EmailPatternChecker{config: &merged}
+	for _, o := range opts {
+		o(c)
+	}
+
+	if c.model == nil {
+		c.model = namemodel.Default()
+	}
+
+	return c
+}
+
+// Config returns the thresholds of the checker.
+func (c *EmailPatternChecker) Config() Config {
+	return *c.config
+}
+
+// Check checks email with the configured thresholds.
+func (c *EmailPatternChecker) Check(ctx context.Context, email string) (*emailchecker.EmailPatternCheckResult, error) {
+	return c.CheckWithOverrides(ctx, email, Config{})
+}
+
+// CheckWithOverrides checks email with the set thresholds of overrides in
+// place of the configured ones.
+func (c *EmailPatternChecker) CheckWithOverrides(_ context.Context, email string, overrides Config) (*emailchecker.EmailPatternCheckResult, error) {
+	cfg := c.config.Merge(overrides)
+
 	atCount := strings.Count(email, "@")
 	if atCount != 1 {
 		return nil, errors.New("invalid email format")
@@ -68,51 +157,98 @@ func (c *EmailPatternChecker) Check(_ context.Context, email string) (*emailchec
 	local := email[:at]
 	runes := []rune(local)
 
+	features := measure(local, &cfg, c.model)
+
 	res := &emailchecker.EmailPatternCheckResult{
-		ShortLocalPart:            len(runes) < c.config.MinLocalPartLength,
-		TooManyConsecutiveNumbers: c.hasConsecutiveNumbers(local),
+		ShortLocalPart:            len(runes) < cfg.MinLocalPartLength,
+		TooManyConsecutiveNumbers: features.ConsecutiveNumbers.Triggered,
+		TooManySpecialChars:       features.SpecialChars.Triggered,
+		Features:                  features,
+	}
+
+	notHumanName := features.NameLikeness.Triggered
+
+	// Entropy misjudges long names such as xiaoqing; the name model has the
+	// final say when it scored enough letters.
+	nameModelLike := features.GibberishScript != "" && !features.Gibberish.Triggered &&
+		len(runes) >= cfg.MinGibberishLength
+
+	if features.KeyboardSequence.Triggered ||
+		features.ConsecutiveNumbers.Triggered ||
+		features.SpecialChars.Triggered ||
+		features.CaseSwitches.Triggered ||
+		features.ScriptLetters.Triggered ||
+		features.Gibberish.Triggered ||
+		(notHumanName && len(runes) >= cfg.MinRandomNameLength) ||
+		(notHumanName && features.Entropy.Triggered && !nameModelLike) {
+		res.HasRandomPattern = true
 	}
 
-	specialChars := 0
-	for _, r := range runes {
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) {
-			specialChars++
-		}
+	return res, nil
+}
+
+// measure computes the features of a local part against the thresholds of
+// cfg, scoring its letters with model.
+func measure(local string, cfg *Config, model *namemodel.Model) emailchecker.PatternFeatures {
+	runes := []rune(local)
+
+	var f emailchecker.PatternFeatures
+
+	entropy := calcEntropy(runes)
+	f.Entropy = emailchecker.PatternFeature{
+		Value:     entropy,
+		Triggered: len(runes) >= cfg.MinEntropyLength && entropy > cfg.HighEntropyThreshold,
 	}
 
-	if len(runes) > 0 && float64(specialChars)/float64(len(runes)) > c.config.MaxSpecialCharRatio {
-		res.TooManySpecialChars = true
+	keyboard := longestKeyboardSeq(local)
+	f.KeyboardSequence = emailchecker.PatternFeature{
+		Value:     float64(keyboard),
+		Triggered: keyboard >= cfg.MinKeyboardSeqLength,
 	}
 
-	isHighEntropy := len(runes) >= 6 && calcEntropy(runes) > 3.5
-	hasKeyboard := hasKeyboardSeq(local)
-	manyCases := manyCaseSwitch(local)
-	notHumanPattern := !humanPattern.MatchString(local)
-	notHumanName := !looksLikeHumanName(local)
+	digits := longestDigitRun(local)
+	f.ConsecutiveNumbers = emailchecker.PatternFeature{
+		Value:     float64(digits),
+		Triggered: digits > cfg.MaxConsecutiveNumbers,
+	}
 
-	if hasKeyboard ||
-		(isHighEntropy && len(runes) >= 12 && notHumanName) ||
-		(manyCases && len(runes) >= 6) ||
-		(notHumanPattern && !isValidUnicodeScript(local)) ||
-		res.TooManyConsecutiveNumbers ||
-		res.TooManySpecialChars {
-		res.HasRandomPattern = true
+	special := specialCharRatio(runes)
+	f.SpecialChars = emailchecker.PatternFeature{
+		Value:     special,
+		Triggered: special > cfg.MaxSpecialCharRatio,
 	}
 
-	if hasKeyboard ||
-		res.TooManyConsecutiveNumbers ||
-		res.TooManySpecialChars ||
-		(manyCases && len(runes) >= 6) ||
-		(notHumanPattern && !isValidUnicodeScript(local)) ||
-		(notHumanName && len(runes) >= 8) ||
-		(notHumanName && isHighEntropy && len(runes) >= 6) {
-		res.HasRandomPattern = true
+	switches := caseSwitchRatio(runes)
+	f.CaseSwitches = emailchecker.PatternFeature{
+		Value:     switches,
+		Triggered: len(runes) >= cfg.MinCaseSwitchLength && switches > cfg.MaxCaseSwitchRatio,
 	}
 
-	return res, nil
+	likeness := nameLikeness(local, cfg)
+	f.NameLikeness = emailchecker.PatternFeature{
+		Value:     likeness,
+		Triggered: likeness < 1,
+	}
+
+	letters := letterRatio(runes)
+	f.ScriptLetters = emailchecker.PatternFeature{
+		Value:     letters,
+		Triggered: !humanPattern.MatchString(local) && letters <= cfg.MinScriptLetterRatio,
+	}
+
+	score := model.Score(local)
+	f.Gibberish = emailchecker.PatternFeature{
+		Value:     score.Bits,
+		Triggered: score.Letters >= cfg.MinGibberishLength && score.Bits > cfg.MaxGibberishBits,
+	}
+	f.GibberishScript = score.Script
+
+	return f
 }
 
-func looksLikeHumanName(s string) bool {
+// nameLikeness returns the share of the parts of s that look like a name
+// part. Separators split parts, and trailing numbers are parts of their own.
+func nameLikeness(s string, cfg *Config) float64 {
 	s = strings.ToLower(s)
 
 	parts := strings.FieldsFunc(s, func(c rune) bool {
@@ -125,13 +261,18 @@ func looksLikeHumanName(s string) bool {
 		allParts = append(allParts, subParts...)
 	}
 
+	if len(allParts) == 0 {
+		return 1
+	}
+
+	valid := 0
 	for _, part := range allParts {
-		if !isValidNamePart(part) {
-			return false
+		if isValidNamePart(part, cfg) {
+			valid++
 		}
 	}
 
-	return true
+	return float64(valid) / float64(len(allParts))
 }
 
 func splitLettersAndTrailingNumbers(s string) []string {
@@ -172,7 +313,7 @@ func splitLettersAndTrailingNumbers(s string) []string {
 	return []string{s}
 }
 
-func isValidNamePart(part string) bool {
+func isValidNamePart(part string, cfg *Config) bool {
 	if len(part) == 0 {
 		return false
 	}
@@ -185,16 +326,16 @@ func isValidNamePart(part string) bool {
 		return true
 	}
 
-	if hasNameWithTrailingNumbers(part) {
+	if hasNameWithTrailingNumbers(part, cfg.MaxTrailingDigits) {
 		return true
 	}
 
 	letterDigitSwitches := countLetterDigitSwitches(part)
-	if letterDigitSwitches > 2 {
+	if letterDigitSwitches > cfg.MaxLetterDigitSwitches {
 		return false // Too many switches = likely random
 	}
 
-	if len(part) > 6 {
+	if len(part) >= cfg.MinDigitRatioLength {
 		digitCount := 0
 		for _, r := range part {
 			if unicode.IsDigit(r) {
@@ -202,7 +343,7 @@ func isValidNamePart(part string) bool {
 			}
 		}
 		digitRatio := float64(digitCount) / float64(len([]rune(part)))
-		if digitRatio > 0.4 { // More lenient threshold
+		if digitRatio > cfg.MaxNameDigitRatio {
 			return false
 		}
 	}
@@ -231,7 +372,7 @@ func isAllLetters(s string) bool {
 	return len(s) > 0
 }
 
-func hasNameWithTrailingNumbers(s string) bool {
+func hasNameWithTrailingNumbers(s string, maxDigits int) bool {
 	// Pattern: letters followed by numbers (john123, mary2000)
 	letterCount := 0
 	digitCount := 0
@@ -254,7 +395,7 @@ func hasNameWithTrailingNumbers(s string) bool {
 		}
 	}
 
-	return letterCount >= 2 && digitCount >= 1 && digitCount <= 4
+	return letterCount >= 2 && digitCount >= 1 && digitCount <= maxDigits
 }
 
 func countLetterDigitSwitches(s string) int {
@@ -279,10 +420,10 @@ func countLetterDigitSwitches(s string) int {
 	return switches
 }
 
-func isValidUnicodeScript(s string) bool {
-	runes := []rune(s)
+// letterRatio returns the share of letters and marks of runes.
+func letterRatio(runes []rune) float64 {
 	if len(runes) == 0 {
-		return false
+		return 0
 	}
 
 	letters := 0
@@ -292,10 +433,27 @@ func isValidUnicodeScript(s string) bool {
 		}
 	}
 
-	return float64(letters)/float64(len(runes)) > 0.6
+	return float64(letters) / float64(len(runes))
+}
+
+// specialCharRatio returns the share of runes that are neither letters nor
+// digits.
+func specialCharRatio(runes []rune) float64 {
+	if len(runes) == 0 {
+		return 0
+	}
+
+	special := 0
+	for _, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) {
+			special++
+		}
+	}
+
+	return float64(special) / float64(len(runes))
 }
 
-func (c *EmailPatternChecker) hasConsecutiveNumbers(s string) bool {
+func longestDigitRun(s string) int {
 	count := 0
 	maxCount := 0
 
@@ -310,7 +468,7 @@ func (c *EmailPatternChecker) hasConsecutiveNumbers(s string) bool {
 		}
 	}
 
-	return maxCount > c.config.MaxConsecutiveNumbers
+	return maxCount
 }
 
 func calcEntropy(runes []rune) float64 {
@@ -333,30 +491,25 @@ func calcEntropy(runes []rune) float64 {
 	return ent
 }
 
-func hasKeyboardSeq(s string) bool {
-	if len(s) < minKeyboardSeqLength {
-		return false
-	}
-
+// longestKeyboardSeq returns the length of the longest part of s that is a
+// walk along a keyboard row, in either direction.
+func longestKeyboardSeq(s string) int {
 	s = strings.ToLower(s)
+	longest := 0
 
 	for _, row := range keyboardRows {
-		if containsSequence(row, s, minKeyboardSeqLength) ||
-			containsSequence(reverse(row), s, minKeyboardSeqLength) {
-			return true
+		for _, r := range []string{row, reverse(row)} {
+			for i := range len(s) {
+				n := longest + 1
+				for i+n <= len(s) && strings.Contains(r, s[i:i+n]) {
+					longest = n
+					n++
+				}
+			}
 		}
 	}
-	return false
-}
 
-func containsSequence(row, s string, minLen int) bool {
-	for i := 0; i <= len(s)-minLen; i++ {
-		substr := s[i : i+minLen]
-		if strings.Contains(row, substr) {
-			return true
-		}
-	}
-	return false
+	return longest
 }
 
 func reverse(s string) string {
@@ -367,15 +520,17 @@ func reverse(s string) string {
 	return string(r)
 }
 
-func manyCaseSwitch(s string) bool {
-	if len(s) < 6 {
-		return false
+// caseSwitchRatio returns the number of switches between lower and upper
+// case per rune.
+func caseSwitchRatio(runes []rune) float64 {
+	if len(runes) == 0 {
+		return 0
 	}
 
 	switches := 0
 	lastCase := -1 // -1: unknown, 0: lower, 1: upper
 
-	for _, r := range s {
+	for _, r := range runes {
 		currentCase := -1
 		if unicode.IsUpper(r) {
 			currentCase = 1
@@ -392,5 +547,5 @@ func manyCaseSwitch(s string) bool {
 		}
 	}
 
-	return switches > len(s)/3
+	return float64(switches) / float64(len(runes))
 }
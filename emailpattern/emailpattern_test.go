@@ -2,9 +2,12 @@ package emailpattern_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"emailchecker/emailpattern"
 )
@@ -182,6 +185,101 @@ func TestEmailPatternCheck_Config(t *testing.T) {
 	})
 }
 
+func TestEmailPatternCheck_Overrides(t *testing.T) {
+	c := emailpattern.New()
+
+	res, err := c.Check(context.Background(), "user123456@domain.com")
+	assert.NoError(t, err)
+	assert.True(t, res.TooManyConsecutiveNumbers)
+
+	res, err = c.CheckWithOverrides(context.Background(), "user123456@domain.com", emailpattern.Config{MaxConsecutiveNumbers: 8})
+	assert.NoError(t, err)
+	assert.False(t, res.TooManyConsecutiveNumbers, "override should raise the limit for this check only")
+	assert.Equal(t, 5, c.Config().MaxConsecutiveNumbers)
+
+	res, err = c.CheckWithOverrides(context.Background(), "john@domain.com", emailpattern.Config{MinKeyboardSeqLength: 3})
+	assert.NoError(t, err)
+	assert.False(t, res.HasRandomPattern)
+
+	res, err = c.CheckWithOverrides(context.Background(), "johnasd@domain.com", emailpattern.Config{MinKeyboardSeqLength: 3})
+	assert.NoError(t, err)
+	assert.True(t, res.HasRandomPattern, "asd is a keyboard walk of length 3")
+}
+
+func TestEmailPatternCheck_ZeroThresholds(t *testing.T) {
+	var overrides emailpattern.Config
+	require.NoError(t, overrides.Set("max_consecutive_numbers", "0"))
+
+	c := emailpattern.New()
+
+	res, err := c.CheckWithOverrides(context.Background(), "user1@domain.com", overrides)
+	assert.NoError(t, err)
+	assert.True(t, res.TooManyConsecutiveNumbers, "a zero override forbids any digit")
+
+	path := filepath.Join(t.TempDir(), "pattern.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"max_letter_digit_switches": 0}`), 0o600))
+
+	cfg, err := emailpattern.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.MaxLetterDigitSwitches)
+	assert.Equal(t, 5, cfg.MaxConsecutiveNumbers, "missing thresholds keep their default")
+
+	assert.Equal(t, 0, emailpattern.NewWithConfig(cfg).Config().MaxLetterDigitSwitches)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"max_digits": 3}`), 0o600))
+
+	_, err = emailpattern.LoadConfig(path)
+	assert.ErrorContains(t, err, "unknown pattern threshold")
+}
+
+func TestEmailPatternCheck_Features(t *testing.T) {
+	c := emailpattern.New()
+
+	res, err := c.Check(context.Background(), "john.smith@domain.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, res.Features.NameLikeness.Value)
+	assert.False(t, res.Features.NameLikeness.Triggered)
+	assert.InDelta(t, 0.1, res.Features.SpecialChars.Value, 0.001)
+	assert.False(t, res.Features.KeyboardSequence.Triggered)
+
+	res, err = c.Check(context.Background(), "johnqwerty@domain.com")
+	assert.NoError(t, err)
+	assert.True(t, res.HasRandomPattern)
+	assert.Equal(t, 6.0, res.Features.KeyboardSequence.Value)
+	assert.True(t, res.Features.KeyboardSequence.Triggered)
+
+	res, err = c.Check(context.Background(), "aBcDeFgHiJkL@domain.com")
+	assert.NoError(t, err)
+	assert.InDelta(t, 11.0/12, res.Features.CaseSwitches.Value, 0.001)
+	assert.True(t, res.Features.CaseSwitches.Triggered)
+
+	res, err = c.Check(context.Background(), "user123456@domain.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, res.Features.ConsecutiveNumbers.Value)
+	assert.True(t, res.Features.ConsecutiveNumbers.Triggered)
+}
+
+func TestEmailPatternCheck_Gibberish(t *testing.T) {
+	c := emailpattern.New()
+
+	for _, email := range []string{"xiaoqing@domain.com", "wojciechowski@domain.com", "chukwuemeka@domain.com", "Дмитрий@почта.рф"} {
+		res, err := c.Check(context.Background(), email)
+		assert.NoError(t, err)
+		assert.False(t, res.Features.Gibberish.Triggered, email)
+		assert.False(t, res.HasRandomPattern, email)
+	}
+
+	res, err := c.Check(context.Background(), "wjmsfvlb@domain.com")
+	assert.NoError(t, err)
+	assert.True(t, res.Features.Gibberish.Triggered)
+	assert.Equal(t, "Latin", res.Features.GibberishScript)
+	assert.True(t, res.HasRandomPattern)
+
+	res, err = c.CheckWithOverrides(context.Background(), "wjmsfvlb@domain.com", emailpattern.Config{MaxGibberishBits: 9})
+	assert.NoError(t, err)
+	assert.False(t, res.Features.Gibberish.Triggered)
+}
+
 func TestEmailPatternCheck_EdgeCases(t *testing.T) {
 	c := emailpattern.New()
 
@@ -2,4 +2,12 @@ package emailchecker
 
 import "errors"
 
-var ErrInvalidConfig = errors.New("invalid config")
+var (
+	ErrInvalidConfig   = errors.New("invalid config")
+	ErrInvalidOverride = errors.New("invalid override")
+	ErrInvalidReview   = errors.New("invalid review")
+	ErrUnsafeListData  = errors.New("unsafe list data")
+	ErrNoSnapshot      = errors.New("no snapshot")
+	ErrInvalidProvider = errors.New("invalid provider")
+	ErrInvalidImport   = errors.New("invalid import")
+)
@@ -0,0 +1,8 @@
+package emailchecker
+
+import "time"
+
+// Wait exposes the delay between the refreshes of a list to the tests.
+func (s RefreshSchedule) Wait() time.Duration {
+	return s.wait()
+}
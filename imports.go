@@ -0,0 +1,122 @@
+package emailchecker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImportFormat is the file format of an imported list.
+type ImportFormat string
+
+const (
+	// ImportText has one entry per line; lines starting with # are ignored.
+	ImportText ImportFormat = "txt"
+	ImportCSV  ImportFormat = "csv"
+	ImportJSON ImportFormat = "json"
+)
+
+func ParseImportFormat(s string) (ImportFormat, error) {
+	switch format := ImportFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case ImportText, ImportCSV, ImportJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown import format %q: expected txt, csv or json", s)
+	}
+}
+
+// ImportFormatOf guesses the format of a file from its extension. Unknown
+// extensions are read as text.
+func ImportFormatOf(path string) ImportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ImportCSV
+	case ".json":
+		return ImportJSON
+	default:
+		return ImportText
+	}
+}
+
+// ImportMode tells how imported entries are combined with the live list.
+type ImportMode string
+
+const (
+	// ImportReplace replaces the list with the imported entries.
+	ImportReplace ImportMode = "replace"
+	// ImportMerge adds the imported entries to the list and updates the
+	// entries it already has.
+	ImportMerge ImportMode = "merge"
+)
+
+func ParseImportMode(s string) (ImportMode, error) {
+	switch mode := ImportMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ImportReplace, ImportMerge:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown import mode %q: expected replace or merge", s)
+	}
+}
+
+// ListData holds the entries of a domain list. Only the field matching the
+// list is used.
+type ListData struct {
+	Disposable  []DisposableDomain
+	Top         []TopDomain
+	Educational []EducationalInstitution
+	Parked      []ParkedEntry
+}
+
+// Len returns the number of entries of list.
+func (d ListData) Len(list ListName) int {
+	switch list {
+	case ListDisposable:
+		return len(d.Disposable)
+	case ListTop:
+		return len(d.Top)
+	case ListEducational:
+		return len(d.Educational)
+	case ListParked:
+		return len(d.Parked)
+	default:
+		return 0
+	}
+}
+
+// ListImport records where the data of an imported list came from.
+type ListImport struct {
+	List   ListName     `json:"list"`
+	File   string       `json:"file"`
+	Format ImportFormat `json:"format"`
+	Mode   ImportMode   `json:"mode"`
+	// SHA256 is the checksum of the imported file.
+	SHA256 string `json:"sha256"`
+	// Entries is the number of entries read from the file.
+	Entries    int       `json:"entries"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+func (i *ListImport) Validate() error {
+	if i == nil {
+		return fmt.Errorf("%w: import cannot be nil", ErrInvalidImport)
+	}
+
+	if _, err := ParseListName(string(i.List)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	if _, err := ParseImportFormat(string(i.Format)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	if _, err := ParseImportMode(string(i.Mode)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	if strings.TrimSpace(i.File) == "" {
+		return fmt.Errorf("%w: file is required", ErrInvalidImport)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package emailchecker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+func TestListImport_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		list  emailchecker.ListName
+		valid bool
+	}{
+		{name: "Top", list: "top", valid: true},
+		{name: "Parked", list: "parked", valid: true},
+		{name: "Unknown list", list: "spam"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			imp := emailchecker.ListImport{List: tc.list, File: "list.txt", Format: emailchecker.ImportText, Mode: emailchecker.ImportReplace}
+
+			err := imp.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, emailchecker.ErrInvalidImport)
+		})
+	}
+}
+
+func TestParseParkedEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  emailchecker.ParkedEntry
+		err   bool
+	}{
+		{name: "Nameserver", input: " NS1.SedoParking.com. ", want: emailchecker.ParkedEntry{Value: "ns1.sedoparking.com", Kind: emailchecker.ParkedNameServer}},
+		{name: "IPv4 range", input: "91.195.240.85/28", want: emailchecker.ParkedEntry{Value: "91.195.240.80/28", Kind: emailchecker.ParkedAddress}},
+		{name: "IPv4 address", input: "34.102.136.180", want: emailchecker.ParkedEntry{Value: "34.102.136.180/32", Kind: emailchecker.ParkedAddress}},
+		{name: "IPv6 address", input: "2606:4700:20::681a:625", want: emailchecker.ParkedEntry{Value: "2606:4700:20::681a:625/128", Kind: emailchecker.ParkedAddress}},
+		{name: "Invalid range", input: "10.0.0.0/33", err: true},
+		{name: "Empty", input: " ", err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := emailchecker.ParseParkedEntry(tc.input)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
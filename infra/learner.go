@@ -0,0 +1,235 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"emailchecker"
+	"emailchecker/pkg/log"
+)
+
+const (
+	queueSize = 1024
+
+	// candidateThreshold is the confidence above which an unlisted domain is
+	// queued for review.
+	candidateThreshold = 0.5
+)
+
+// weights tells how much sharing a feature says about a domain. A dedicated
+// MX host is a stronger signal than an address, which may be shared hosting,
+// and name servers are the weakest since registrars host many zones.
+var weights = map[emailchecker.InfraKind]float64{
+	emailchecker.InfraMXHost:     0.9,
+	emailchecker.InfraMXIP:       0.7,
+	emailchecker.InfraNameServer: 0.5,
+}
+
+type repo interface {
+	RecordInfrastructure(ctx context.Context, domain string, disposable bool, features []emailchecker.InfraFeature) error
+	GetInfrastructureStats(ctx context.Context, excludeDomain string, features []emailchecker.InfraFeature) ([]emailchecker.InfraFeatureStats, error)
+	UpsertCandidate(ctx context.Context, c emailchecker.DisposableCandidate) error
+	GetCandidate(ctx context.Context, domain string) (*emailchecker.DisposableCandidate, error)
+	ListCandidates(ctx context.Context, status emailchecker.CandidateStatus) ([]emailchecker.DisposableCandidate, error)
+	SetCandidateStatus(ctx context.Context, domain string, status emailchecker.CandidateStatus, reviewer string) (bool, error)
+}
+
+type observation struct {
+	domain   string
+	features []emailchecker.InfraFeature
+	// disposable is set for listed domains and trusted for well-known or
+	// educational ones. Other domains are only scored.
+	disposable bool
+	trusted    bool
+}
+
+// Learner records the mail infrastructure of checked domains in the
+// background and queues unlisted domains that share it with disposable
+// ones as candidates for review.
+type Learner struct {
+	repo  repo
+	queue chan observation
+	wg    sync.WaitGroup
+
+	// mu guards closed, so that Observe never sends on the closed queue.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func New(repo repo) *Learner {
+	ans := Learner{
+		repo:  repo,
+		queue: make(chan observation, queueSize),
+	}
+
+	ans.wg.Add(1)
+	go ans.run()
+
+	return &ans
+}
+
+// Observe queues a check result for learning. It never blocks: results are
+// dropped when the queue is full or the learner is closed.
+func (l *Learner) Observe(result *emailchecker.EmailCheckResult) {
+	if !result.DNS.Checked || result.DNS.Err != nil || !result.Disposable.Checked || result.Disposable.Err != nil {
+		return
+	}
+
+	features := emailchecker.InfraFeatures(&result.DNS.Value)
+	if len(features) == 0 {
+		return
+	}
+
+	obs := observation{
+		domain:   result.Domain.Name,
+		features: features,
+	}
+
+	disposable := result.Disposable.Value
+	switch {
+	case !disposable.Disposable && disposable.Reason == emailchecker.DisposableReasonOverride:
+		// Excluded by a local override.
+		obs.trusted = true
+	case disposable.Disposable:
+		// Only list membership is ground truth. Learning from the strict
+		// rules would let the learner feed on its own guesses.
+		if disposable.Reason != emailchecker.DisposableReasonListed && disposable.Reason != emailchecker.DisposableReasonOverride {
+			return
+		}
+
+		obs.disposable = true
+	default:
+		obs.trusted = (result.WellKnown.Checked && result.WellKnown.Value) ||
+			(result.Educational.Checked && result.Educational.Value.Educational) ||
+			(result.Sector.Checked && result.Sector.Value.Sector != "")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return
+	}
+
+	select {
+	case l.queue <- obs:
+	default:
+		log.Debug(context.Background(), "Infrastructure learner queue is full", "domain", obs.domain)
+	}
+}
+
+// Match scores how much the infrastructure of a domain overlaps with that of
+// known disposable domains. Each shared feature scores its weight times its
+// purity, the share of disposable domains among those seen with it, times
+// a support factor that grows with the number of disposable domains. The
+// scores are combined as independent evidence. A domain a reviewer rejected
+// matches nothing.
+func (l *Learner) Match(ctx context.Context, dns *emailchecker.DNSValidationResult, domain string) (*emailchecker.InfrastructureMatch, error) {
+	c, err := l.repo.GetCandidate(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil && c.Status == emailchecker.CandidateRejected {
+		return &emailchecker.InfrastructureMatch{Evidence: []emailchecker.InfraEvidence{}}, nil
+	}
+
+	return l.match(ctx, domain, emailchecker.InfraFeatures(dns))
+}
+
+func (l *Learner) ListCandidates(ctx context.Context, status emailchecker.CandidateStatus) ([]emailchecker.DisposableCandidate, error) {
+	return l.repo.ListCandidates(ctx, status)
+}
+
+func (l *Learner) GetCandidate(ctx context.Context, domain string) (*emailchecker.DisposableCandidate, error) {
+	return l.repo.GetCandidate(ctx, domain)
+}
+
+func (l *Learner) SetCandidateStatus(ctx context.Context, domain string, status emailchecker.CandidateStatus, reviewer string) (bool, error) {
+	return l.repo.SetCandidateStatus(ctx, domain, status, reviewer)
+}
+
+// Close stops accepting observations and waits for the queued ones.
+func (l *Learner) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+
+	return nil
+}
+
+func (l *Learner) run() {
+	defer l.wg.Done()
+
+	for obs := range l.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+		if err := l.learn(ctx, obs); err != nil {
+			log.Warn(ctx, "Could not learn infrastructure", "domain", obs.domain, "error", err.Error())
+		}
+
+		cancel()
+	}
+}
+
+func (l *Learner) learn(ctx context.Context, obs observation) error {
+	if obs.disposable || obs.trusted {
+		return l.repo.RecordInfrastructure(ctx, obs.domain, obs.disposable, obs.features)
+	}
+
+	m, err := l.match(ctx, obs.domain, obs.features)
+	if err != nil {
+		return err
+	}
+
+	if m.Confidence < candidateThreshold {
+		return nil
+	}
+
+	return l.repo.UpsertCandidate(ctx, emailchecker.DisposableCandidate{
+		Domain:     obs.domain,
+		Confidence: m.Confidence,
+		Evidence:   m.Evidence,
+		LastSeenAt: time.Now().UTC(),
+	})
+}
+
+func (l *Learner) match(ctx context.Context, domain string, features []emailchecker.InfraFeature) (*emailchecker.InfrastructureMatch, error) {
+	ans := emailchecker.InfrastructureMatch{
+		Evidence: []emailchecker.InfraEvidence{},
+	}
+
+	stats, err := l.repo.GetInfrastructureStats(ctx, domain, features)
+	if err != nil {
+		return nil, err
+	}
+
+	miss := 1.0
+	for _, s := range stats {
+		if s.DisposableDomains == 0 {
+			continue
+		}
+
+		d, c := float64(s.DisposableDomains), float64(s.CleanDomains)
+		purity := d / (d + c)
+		support := 1 - 1/(1+d)
+		score := weights[s.Kind] * purity * support
+
+		ans.Evidence = append(ans.Evidence, emailchecker.InfraEvidence{
+			InfraFeatureStats: s,
+			Score:             score,
+		})
+
+		miss *= 1 - score
+	}
+
+	ans.Confidence = 1 - miss
+
+	return &ans, nil
+}
@@ -0,0 +1,313 @@
+package infra_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/infra"
+)
+
+// memRepo keeps observations and candidates in memory.
+type memRepo struct {
+	mu         sync.Mutex
+	seen       map[emailchecker.InfraFeature]map[string]bool
+	candidates map[string]emailchecker.DisposableCandidate
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{
+		seen:       make(map[emailchecker.InfraFeature]map[string]bool),
+		candidates: make(map[string]emailchecker.DisposableCandidate),
+	}
+}
+
+func (r *memRepo) RecordInfrastructure(_ context.Context, domain string, disposable bool, features []emailchecker.InfraFeature) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range features {
+		if r.seen[f] == nil {
+			r.seen[f] = make(map[string]bool)
+		}
+
+		r.seen[f][domain] = disposable
+	}
+
+	return nil
+}
+
+func (r *memRepo) GetInfrastructureStats(_ context.Context, excludeDomain string, features []emailchecker.InfraFeature) ([]emailchecker.InfraFeatureStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ans []emailchecker.InfraFeatureStats
+	for _, f := range features {
+		s := emailchecker.InfraFeatureStats{InfraFeature: f}
+
+		for domain, disposable := range r.seen[f] {
+			switch {
+			case domain == excludeDomain:
+			case disposable:
+				s.DisposableDomains++
+			default:
+				s.CleanDomains++
+			}
+		}
+
+		if s.DisposableDomains+s.CleanDomains > 0 {
+			ans = append(ans, s)
+		}
+	}
+
+	return ans, nil
+}
+
+func (r *memRepo) UpsertCandidate(_ context.Context, c emailchecker.DisposableCandidate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.candidates[c.Domain]
+	if !ok {
+		c.Status = emailchecker.CandidatePending
+		r.candidates[c.Domain] = c
+
+		return nil
+	}
+
+	old.Confidence, old.Evidence, old.LastSeenAt = c.Confidence, c.Evidence, c.LastSeenAt
+	r.candidates[c.Domain] = old
+
+	return nil
+}
+
+func (r *memRepo) GetCandidate(_ context.Context, domain string) (*emailchecker.DisposableCandidate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.candidates[domain]
+	if !ok {
+		return nil, nil
+	}
+
+	return &c, nil
+}
+
+func (r *memRepo) ListCandidates(_ context.Context, status emailchecker.CandidateStatus) ([]emailchecker.DisposableCandidate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ans := []emailchecker.DisposableCandidate{}
+	for _, c := range r.candidates {
+		if status == "" || c.Status == status {
+			ans = append(ans, c)
+		}
+	}
+
+	return ans, nil
+}
+
+func (r *memRepo) SetCandidateStatus(_ context.Context, domain string, status emailchecker.CandidateStatus, reviewer string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.candidates[domain]
+	if !ok {
+		return false, nil
+	}
+
+	c.Status = status
+	c.ReviewedBy = reviewer
+	r.candidates[domain] = c
+
+	return true, nil
+}
+
+func (r *memRepo) observed(domain string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, domains := range r.seen {
+		if _, ok := domains[domain]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mx(host string) *emailchecker.DNSValidationResult {
+	return &emailchecker.DNSValidationResult{
+		HasMX:     true,
+		MXRecords: []emailchecker.MXRecord{{Value: host, Priority: 10}},
+	}
+}
+
+// checked returns the result of a check of domain with the MX host, listed
+// as disposable or not.
+func checked(domain, host string, listed bool) *emailchecker.EmailCheckResult {
+	res := &emailchecker.EmailCheckResult{Domain: emailchecker.NewDomain(domain)}
+
+	res.DNS.Checked = true
+	res.DNS.Value = *mx(host)
+
+	res.Disposable.Checked = true
+	if listed {
+		res.Disposable.Value = emailchecker.DisposableCheckResult{Disposable: true, Reason: emailchecker.DisposableReasonListed}
+	}
+
+	return res
+}
+
+func record(t *testing.T, repo *memRepo, host string, disposable, clean int) {
+	t.Helper()
+
+	features := emailchecker.InfraFeatures(mx(host))
+
+	for i := range disposable {
+		require.NoError(t, repo.RecordInfrastructure(context.Background(), fmt.Sprintf("trash%d.example", i), true, features))
+	}
+
+	for i := range clean {
+		require.NoError(t, repo.RecordInfrastructure(context.Background(), fmt.Sprintf("clean%d.example", i), false, features))
+	}
+}
+
+func TestLearner_Match(t *testing.T) {
+	cases := []struct {
+		name       string
+		disposable int
+		clean      int
+		confidence float64
+	}{
+		{name: "Unknown infrastructure", confidence: 0},
+		{name: "Clean infrastructure", clean: 3, confidence: 0},
+		{name: "Single disposable domain", disposable: 1, confidence: 0.45},
+		{name: "Several disposable domains", disposable: 3, confidence: 0.675},
+		{name: "Mixed infrastructure", disposable: 3, clean: 3, confidence: 0.3375},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newMemRepo()
+			record(t, repo, "mx.trash.example", tc.disposable, tc.clean)
+
+			l := infra.New(repo)
+			defer l.Close() //nolint:errcheck
+
+			m, err := l.Match(context.Background(), mx("mx.trash.example"), "fresh.example")
+			require.NoError(t, err)
+
+			assert.InDelta(t, tc.confidence, m.Confidence, 1e-9)
+			assert.Len(t, m.Evidence, min(tc.disposable, 1))
+		})
+	}
+}
+
+func TestLearner_QueuesCandidatesAboveThreshold(t *testing.T) {
+	repo := newMemRepo()
+	record(t, repo, "mx.trash.example", 3, 0)
+	record(t, repo, "mx.other.example", 1, 0)
+
+	l := infra.New(repo)
+	l.Observe(checked("fresh.example", "mx.trash.example", false))
+	l.Observe(checked("small.example", "mx.other.example", false))
+	require.NoError(t, l.Close())
+
+	c, err := l.GetCandidate(context.Background(), "fresh.example")
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, emailchecker.CandidatePending, c.Status)
+	assert.InDelta(t, 0.675, c.Confidence, 1e-9)
+
+	c, err = l.GetCandidate(context.Background(), "small.example")
+	require.NoError(t, err)
+	assert.Nil(t, c, "0.45 is below the candidate threshold")
+}
+
+func TestLearner_ReviewStatus(t *testing.T) {
+	cases := []struct {
+		status  emailchecker.CandidateStatus
+		matches bool
+	}{
+		{status: emailchecker.CandidatePending, matches: true},
+		{status: emailchecker.CandidatePromoted, matches: true},
+		{status: emailchecker.CandidateRejected, matches: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.status), func(t *testing.T) {
+			repo := newMemRepo()
+			record(t, repo, "mx.trash.example", 3, 0)
+
+			l := infra.New(repo)
+			defer l.Close() //nolint:errcheck
+
+			ctx := context.Background()
+			require.NoError(t, repo.UpsertCandidate(ctx, emailchecker.DisposableCandidate{Domain: "fresh.example"}))
+
+			ok, err := l.SetCandidateStatus(ctx, "fresh.example", tc.status, "jane")
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			m, err := l.Match(ctx, mx("mx.trash.example"), "fresh.example")
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, m.Confidence > 0)
+
+			// Seeing the domain again keeps the review.
+			l.Observe(checked("fresh.example", "mx.trash.example", false))
+			require.NoError(t, l.Close())
+
+			c, err := l.GetCandidate(ctx, "fresh.example")
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, c.Status)
+			assert.Equal(t, "jane", c.ReviewedBy)
+		})
+	}
+}
+
+func TestLearner_CloseDrainsQueue(t *testing.T) {
+	repo := newMemRepo()
+	l := infra.New(repo)
+
+	for i := range 10 {
+		l.Observe(checked(fmt.Sprintf("trash%d.example", i), "mx.trash.example", true))
+	}
+
+	require.NoError(t, l.Close())
+
+	for i := range 10 {
+		assert.True(t, repo.observed(fmt.Sprintf("trash%d.example", i)))
+	}
+}
+
+func TestLearner_ObserveAfterClose(t *testing.T) {
+	repo := newMemRepo()
+	l := infra.New(repo)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range 100 {
+				l.Observe(checked(fmt.Sprintf("trash%d-%d.example", i, j), "mx.trash.example", true))
+			}
+		}()
+	}
+
+	require.NoError(t, l.Close())
+	wg.Wait()
+
+	assert.NotPanics(t, func() {
+		l.Observe(checked("late.example", "mx.trash.example", true))
+	})
+	assert.False(t, repo.observed("late.example"))
+	assert.NoError(t, l.Close())
+}
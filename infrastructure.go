@@ -0,0 +1,110 @@
+package emailchecker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InfraKind is the kind of mail infrastructure shared between domains.
+type InfraKind string
+
+const (
+	InfraMXHost     InfraKind = "mx_host"
+	InfraMXIP       InfraKind = "mx_ip"
+	InfraNameServer InfraKind = "ns"
+)
+
+type InfraFeature struct {
+	Kind  InfraKind `json:"kind"`
+	Value string    `json:"value"`
+}
+
+// InfraFeatures returns the MX hosts, MX addresses and name servers of a DNS
+// result, normalized and without duplicates.
+func InfraFeatures(dns *DNSValidationResult) []InfraFeature {
+	if dns == nil {
+		return nil
+	}
+
+	var features []InfraFeature
+
+	seen := make(map[InfraFeature]struct{})
+	add := func(kind InfraKind, value string) {
+		value = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(value), "."))
+		if value == "" {
+			return
+		}
+
+		f := InfraFeature{Kind: kind, Value: value}
+		if _, ok := seen[f]; ok {
+			return
+		}
+
+		seen[f] = struct{}{}
+		features = append(features, f)
+	}
+
+	for _, mx := range dns.MXRecords {
+		add(InfraMXHost, mx.Value)
+
+		for _, ip := range mx.IPs {
+			add(InfraMXIP, ip)
+		}
+	}
+
+	for _, ns := range dns.NSRecords {
+		add(InfraNameServer, ns)
+	}
+
+	return features
+}
+
+// InfraFeatureStats counts the distinct domains observed with a feature.
+type InfraFeatureStats struct {
+	InfraFeature
+	DisposableDomains int `json:"disposable_domains"`
+	CleanDomains      int `json:"clean_domains"`
+}
+
+type InfraEvidence struct {
+	InfraFeatureStats
+	Score float64 `json:"score"`
+}
+
+// InfrastructureMatch tells how strongly a domain shares infrastructure with
+// known disposable domains.
+type InfrastructureMatch struct {
+	Confidence float64         `json:"confidence"`
+	Evidence   []InfraEvidence `json:"evidence"`
+}
+
+type CandidateStatus string
+
+const (
+	CandidatePending  CandidateStatus = "pending"
+	CandidatePromoted CandidateStatus = "promoted"
+	CandidateRejected CandidateStatus = "rejected"
+)
+
+func ParseCandidateStatus(s string) (CandidateStatus, error) {
+	switch status := CandidateStatus(strings.ToLower(strings.TrimSpace(s))); status {
+	case CandidatePending, CandidatePromoted, CandidateRejected:
+		return status, nil
+	default:
+		return "", fmt.Errorf("unknown candidate status %q: expected pending, promoted or rejected", s)
+	}
+}
+
+// DisposableCandidate is a domain that is not on the disposable list but
+// shares infrastructure with domains that are. Candidates wait for review.
+type DisposableCandidate struct {
+	Domain      string          `json:"domain"`
+	Confidence  float64         `json:"confidence"`
+	Evidence    []InfraEvidence `json:"evidence"`
+	Status      CandidateStatus `json:"status"`
+	FirstSeenAt time.Time       `json:"first_seen_at"`
+	LastSeenAt  time.Time       `json:"last_seen_at"`
+	ReviewedBy  string          `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty"`
+}
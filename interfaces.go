@@ -1,30 +1,114 @@
 package emailchecker
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type DisposableChecker interface {
-	IsDisposable(ctx context.Context, domain string) (bool, error)
-	UpdateDisposableList(ctx context.Context) error
+	IsDisposable(ctx context.Context, domain Domain) (*DisposableCheckResult, error)
+	// UpdateDisposableList refreshes the list when its data is older than maxAge.
+	// A zero maxAge forces the refresh.
+	UpdateDisposableList(ctx context.Context, maxAge time.Duration) (*ListRefreshResult, error)
+	Sources() []DataSource
 }
 
 type DNSChecker interface {
 	GetDNSValidationResult(ctx context.Context, domain string) (*DNSValidationResult, error)
+	RefreshDNSValidationResult(ctx context.Context, domain string) (*DNSValidationResult, error)
+	PurgeDNSCache(ctx context.Context, domain string, includeSubdomains bool) (int64, error)
+	DNSCacheStats(ctx context.Context) (*DNSCacheStats, error)
+}
+
+type DomainAgeChecker interface {
+	// GetRegistrationDate returns nil when the registration date is unknown.
+	GetRegistrationDate(ctx context.Context, domain string) (*time.Time, error)
 }
 
 type WellKnownChecker interface {
-	IsWellKnown(ctx context.Context, domain string) (bool, error)
-	UpdateWellKnownList(ctx context.Context) error
+	// IsWellKnown also returns the rank of the matched domain, which is
+	// zero when the domain is well-known by an override.
+	IsWellKnown(ctx context.Context, domain Domain) (bool, int, error)
+	// UpdateWellKnownList refreshes the list when its data is older than maxAge.
+	// A zero maxAge forces the refresh.
+	UpdateWellKnownList(ctx context.Context, maxAge time.Duration) (*ListRefreshResult, error)
+	Sources() []DataSource
 }
 
 type EducationalDomainChecker interface {
-	IsEducationalDomain(ctx context.Context, domain string) (bool, error)
-	UpdateEducationalDomains(ctx context.Context) error
+	// GetEducationalInstitution returns nil when the domain is not
+	// educational.
+	GetEducationalInstitution(ctx context.Context, domain Domain) (*EducationalInstitution, error)
+	// UpdateEducationalDomains refreshes the list when its data is older than maxAge.
+	// A zero maxAge forces the refresh.
+	UpdateEducationalDomains(ctx context.Context, maxAge time.Duration) (*ListRefreshResult, error)
+	Sources() []DataSource
+}
+
+type SectorClassifier interface {
+	ClassifySector(ctx context.Context, domain Domain) (SectorCheckResult, error)
+}
+
+// ConfusableChecker looks for domains and local parts written with
+// characters that imitate others.
+type ConfusableChecker interface {
+	CheckConfusable(ctx context.Context, localPart string, domain Domain) (*ConfusableCheckResult, error)
+}
+
+// ProviderClassifier looks domains up in the provider table.
+type ProviderClassifier interface {
+	// GetProvider returns nil when the domain is not in the table.
+	GetProvider(ctx context.Context, domain Domain) (*EmailProvider, error)
+	SetProvider(ctx context.Context, p EmailProvider) error
+	// RemoveProvider removes a local entry, which restores the curated
+	// entry of the domain, if any.
+	RemoveProvider(ctx context.Context, domain string) (bool, error)
+	ListProviders(ctx context.Context, t ProviderType) ([]EmailProvider, error)
+}
+
+type OverrideStore interface {
+	UpsertOverride(ctx context.Context, o DomainOverride) error
+	DeleteOverride(ctx context.Context, list ListName, domain string) (bool, error)
+	ListOverrides(ctx context.Context, list ListName) ([]DomainOverride, error)
+}
+
+// InfrastructureLearner learns the mail infrastructure of disposable domains
+// from checked emails and keeps the domains sharing it as review candidates.
+type InfrastructureLearner interface {
+	// Observe must not block the caller.
+	Observe(result *EmailCheckResult)
+	// Match must not match domains whose candidate was rejected.
+	Match(ctx context.Context, dns *DNSValidationResult, domain string) (*InfrastructureMatch, error)
+	GetCandidate(ctx context.Context, domain string) (*DisposableCandidate, error)
+	ListCandidates(ctx context.Context, status CandidateStatus) ([]DisposableCandidate, error)
+	SetCandidateStatus(ctx context.Context, domain string, status CandidateStatus, reviewer string) (bool, error)
+	Close() error
+}
+
+type ListStore interface {
+	ListVersions(ctx context.Context) ([]ListVersion, error)
+	ListSnapshots(ctx context.Context, list ListName) ([]ListSnapshot, error)
+	// RollbackList restores the latest snapshot of a list and removes it,
+	// so that repeated rollbacks walk further back.
+	RollbackList(ctx context.Context, list ListName) (*ListRefreshResult, error)
+	RefreshStatus(ctx context.Context, list ListName) (*RefreshStatus, error)
+	// ImportList replaces or merges a list with entries read from a file.
+	ImportList(ctx context.Context, imp *ListImport, data ListData) (*ListRefreshResult, error)
+	LastImport(ctx context.Context, list ListName) (*ListImport, error)
 }
 
 type EmailPatternChecker interface {
 	Check(ctx context.Context, email string) (*EmailPatternCheckResult, error)
 }
 
+// PatternOverrideChecker is implemented by pattern checkers that accept
+// per-request thresholds.
+type PatternOverrideChecker interface {
+	// CheckWithOverrides checks email with the set thresholds of overrides
+	// in place of the configured ones.
+	CheckWithOverrides(ctx context.Context, email string, overrides PatternConfig) (*EmailPatternCheckResult, error)
+}
+
 type Analyzer interface {
 	Analyze(ctx context.Context, result *EmailCheckResult) *AnalysisReport
 }
@@ -0,0 +1,207 @@
+// Package listdata embeds a snapshot of the disposable, top and educational
+// domain lists, used to seed an empty database when the upstream lists cannot
+// be downloaded. Run go generate to refresh it. It also embeds the parked
+// list, which has no upstream source and is edited by hand.
+package listdata
+
+import (
+	"bufio"
+	"compress/gzip"
+	"embed"
+	"fmt"
+	"io"
+	"strings"
+
+	"emailchecker"
+)
+
+//go:generate go run ../cmd/listdata-gen -out .
+
+//go:embed version.txt disposable.txt.gz top.txt.gz edu.txt.gz parked.txt
+var files embed.FS
+
+const (
+	VersionFile    = "version.txt"
+	DisposableFile = "disposable.txt.gz"
+	TopFile        = "top.txt.gz"
+	EduFile        = "edu.txt.gz"
+	ParkedFile     = "parked.txt"
+)
+
+// Version returns the date the snapshot was generated, or an empty string
+// when it was never generated.
+func Version() string {
+	data, err := files.ReadFile(VersionFile)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// Disposable returns the disposable domains with the sources that listed them.
+func Disposable() ([]emailchecker.DisposableDomain, error) {
+	lines, err := readLines(DisposableFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]emailchecker.DisposableDomain, 0, len(lines))
+	for _, line := range lines {
+		domain, sources, _ := strings.Cut(line, "\t")
+
+		d := emailchecker.DisposableDomain{Domain: domain}
+		if sources != "" {
+			d.Sources = strings.Split(sources, ",")
+		}
+
+		ans = append(ans, d)
+	}
+
+	return ans, nil
+}
+
+// Top returns the top sites in rank order, the rank being the line number.
+func Top() ([]emailchecker.TopDomain, error) {
+	lines, err := readLines(TopFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]emailchecker.TopDomain, 0, len(lines))
+	for i, line := range lines {
+		ans = append(ans, emailchecker.TopDomain{Domain: line, Rank: i + 1})
+	}
+
+	return ans, nil
+}
+
+// Educational returns the educational domains with their institution.
+func Educational() ([]emailchecker.EducationalInstitution, error) {
+	lines, err := readLines(EduFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]emailchecker.EducationalInstitution, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+
+		e := emailchecker.EducationalInstitution{Domain: fields[0]}
+		if len(fields) == 5 {
+			e.Name, e.Country, e.AlphaTwoCode = fields[1], fields[2], fields[3]
+			e.WebPages = strings.Fields(fields[4])
+		}
+
+		ans = append(ans, e)
+	}
+
+	return ans, nil
+}
+
+// Parked returns the nameservers and address ranges of parking services.
+// Unlike the snapshot files, the parked file is plain text with comments.
+func Parked() ([]emailchecker.ParkedEntry, error) {
+	data, err := files.ReadFile(ParkedFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var ans []emailchecker.ParkedEntry
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry, err := emailchecker.ParseParkedEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", ParkedFile, i+1, err)
+		}
+
+		ans = append(ans, entry)
+	}
+
+	return ans, nil
+}
+
+// WriteDisposable writes domains in the format read by Disposable.
+func WriteDisposable(w io.Writer, domains []emailchecker.DisposableDomain) error {
+	lines := make([]string, 0, len(domains))
+	for _, d := range domains {
+		lines = append(lines, d.Domain+"\t"+strings.Join(d.Sources, ","))
+	}
+
+	return WriteLines(w, lines)
+}
+
+// WriteEducational writes institutions in the format read by Educational.
+func WriteEducational(w io.Writer, institutions []emailchecker.EducationalInstitution) error {
+	lines := make([]string, 0, len(institutions))
+	for _, e := range institutions {
+		lines = append(lines, strings.Join([]string{
+			e.Domain, e.Name, e.Country, e.AlphaTwoCode, strings.Join(e.WebPages, " "),
+		}, "\t"))
+	}
+
+	return WriteLines(w, lines)
+}
+
+// WriteTop writes domains in the format read by Top. The domains must be in
+// rank order.
+func WriteTop(w io.Writer, domains []emailchecker.TopDomain) error {
+	lines := make([]string, 0, len(domains))
+	for _, d := range domains {
+		lines = append(lines, d.Domain)
+	}
+
+	return WriteLines(w, lines)
+}
+
+// WriteLines writes gzip compressed lines, one entry per line.
+func WriteLines(w io.Writer, lines []string) error {
+	zw := gzip.NewWriter(w)
+
+	bw := bufio.NewWriter(zw)
+	for _, line := range lines {
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func readLines(name string) ([]string, error) {
+	f, err := files.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", name, err)
+	}
+	defer zr.Close() //nolint:errcheck
+
+	var lines []string
+
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", name, err)
+	}
+
+	return lines, nil
+}
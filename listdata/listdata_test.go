@@ -0,0 +1,39 @@
+package listdata_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/listdata"
+)
+
+// TestSnapshot fails on a snapshot that was never generated, which would
+// leave a new air-gapped deployment with empty lists. Run go generate
+// ./listdata to fix it.
+func TestSnapshot(t *testing.T) {
+	assert.NotEmpty(t, listdata.Version(), "the snapshot has no version")
+
+	disposable, err := listdata.Disposable()
+	require.NoError(t, err)
+	assert.NotEmpty(t, disposable, "the disposable snapshot is empty")
+
+	top, err := listdata.Top()
+	require.NoError(t, err)
+	assert.NotEmpty(t, top, "the top snapshot is empty")
+
+	edu, err := listdata.Educational()
+	require.NoError(t, err)
+	assert.NotEmpty(t, edu, "the educational snapshot is empty")
+}
+
+func TestParked(t *testing.T) {
+	parked, err := listdata.Parked()
+	require.NoError(t, err)
+
+	assert.Contains(t, parked, emailchecker.ParkedEntry{Value: "sedoparking.com", Kind: emailchecker.ParkedNameServer})
+	assert.Contains(t, parked, emailchecker.ParkedEntry{Value: "185.53.176.0/22", Kind: emailchecker.ParkedAddress})
+	assert.Contains(t, parked, emailchecker.ParkedEntry{Value: "2606:4700:20::681a:625/128", Kind: emailchecker.ParkedAddress})
+}
@@ -0,0 +1,274 @@
+// Package listimport reads domain lists from local files, for deployments
+// that cannot reach the upstream sources.
+//
+// Text files hold one domain per line. CSV files hold one entry per record,
+// either with a header naming the columns (domain, rank, name, country,
+// alpha_two_code, web_pages) or with the columns of the upstream lists:
+// domain for disposable, rank,domain for top and
+// domain,name,country,alpha_two_code,web_pages for edu. JSON files hold an
+// array of domains or of objects with the same fields; edu objects may list
+// several domains, as the university-domains-list does.
+//
+// The parked list takes nameservers and address ranges, in CIDR notation or
+// as single addresses, in place of domains.
+package listimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"emailchecker"
+)
+
+// Source is the disposable source name of imported domains.
+const Source = "import"
+
+type entry struct {
+	Domain       string   `json:"domain"`
+	Domains      []string `json:"domains"`
+	Rank         int      `json:"rank"`
+	Name         string   `json:"name"`
+	Country      string   `json:"country"`
+	AlphaTwoCode string   `json:"alpha_two_code"`
+	WebPages     []string `json:"web_pages"`
+}
+
+// Read reads the entries of list from r.
+func Read(r io.Reader, list emailchecker.ListName, format emailchecker.ImportFormat) (emailchecker.ListData, error) {
+	var (
+		entries []entry
+		err     error
+	)
+
+	switch format {
+	case emailchecker.ImportText:
+		entries, err = readText(r)
+	case emailchecker.ImportCSV:
+		entries, err = readCSV(r, list)
+	case emailchecker.ImportJSON:
+		entries, err = readJSON(r)
+	default:
+		err = fmt.Errorf("unknown import format %q", format)
+	}
+
+	if err != nil {
+		return emailchecker.ListData{}, err
+	}
+
+	return listData(list, entries)
+}
+
+func readText(r io.Reader) ([]entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, entry{Domain: line})
+	}
+
+	return entries, nil
+}
+
+func readCSV(r io.Reader, list emailchecker.ListName) ([]entry, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns, header := headerColumns(records[0])
+	if header {
+		records = records[1:]
+	} else {
+		columns = defaultColumns(list, records[0])
+	}
+
+	entries := make([]entry, 0, len(records))
+
+	for i, record := range records {
+		var e entry
+
+		for j, value := range record {
+			if j >= len(columns) {
+				break
+			}
+
+			value = strings.TrimSpace(value)
+
+			switch columns[j] {
+			case "domain":
+				e.Domain = value
+			case "rank":
+				if value == "" {
+					continue
+				}
+
+				if e.Rank, err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("record %d: invalid rank %q", i+1, value)
+				}
+			case "name":
+				e.Name = value
+			case "country":
+				e.Country = value
+			case "alpha_two_code":
+				e.AlphaTwoCode = value
+			case "web_pages":
+				e.WebPages = strings.Fields(value)
+			}
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// headerColumns returns the columns named by record when it is a header.
+func headerColumns(record []string) ([]string, bool) {
+	columns := make([]string, 0, len(record))
+	header := false
+
+	for _, field := range record {
+		column := strings.ToLower(strings.TrimSpace(field))
+		if column == "domain" {
+			header = true
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, header
+}
+
+// defaultColumns returns the columns of the upstream format of list.
+func defaultColumns(list emailchecker.ListName, first []string) []string {
+	switch list {
+	case emailchecker.ListTop:
+		// Tranco lists are rank,domain; a single column is a domain in
+		// rank order.
+		if len(first) > 1 {
+			return []string{"rank", "domain"}
+		}
+
+		return []string{"domain"}
+	case emailchecker.ListEducational:
+		return []string{"domain", "name", "country", "alpha_two_code", "web_pages"}
+	default:
+		return []string{"domain"}
+	}
+}
+
+func readJSON(r io.Reader) ([]entry, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("could not decode JSON: expected an array: %w", err)
+	}
+
+	entries := make([]entry, 0, len(items))
+
+	for i, item := range items {
+		var e entry
+
+		item = bytes.TrimSpace(item)
+
+		switch {
+		case len(item) > 0 && item[0] == '"':
+			if err := json.Unmarshal(item, &e.Domain); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+		case len(item) > 0 && item[0] == '{':
+			if err := json.Unmarshal(item, &e); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+		default:
+			return nil, fmt.Errorf("item %d: expected a domain or an object", i+1)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func listData(list emailchecker.ListName, entries []entry) (emailchecker.ListData, error) {
+	var data emailchecker.ListData
+
+	for _, e := range entries {
+		domains := e.Domains
+		if e.Domain != "" {
+			domains = append([]string{e.Domain}, domains...)
+		}
+
+		for _, domain := range domains {
+			domain = normalize(domain)
+			if domain == "" {
+				continue
+			}
+
+			switch list {
+			case emailchecker.ListDisposable:
+				data.Disposable = append(data.Disposable, emailchecker.DisposableDomain{
+					Domain:  domain,
+					Sources: []string{Source},
+				})
+			case emailchecker.ListTop:
+				rank := e.Rank
+				if rank <= 0 {
+					rank = len(data.Top) + 1
+				}
+
+				data.Top = append(data.Top, emailchecker.TopDomain{Domain: domain, Rank: rank})
+			case emailchecker.ListEducational:
+				data.Educational = append(data.Educational, emailchecker.EducationalInstitution{
+					Domain:       domain,
+					Name:         e.Name,
+					Country:      e.Country,
+					AlphaTwoCode: e.AlphaTwoCode,
+					WebPages:     e.WebPages,
+				})
+			case emailchecker.ListParked:
+				parked, err := emailchecker.ParseParkedEntry(domain)
+				if err != nil {
+					return emailchecker.ListData{}, err
+				}
+
+				data.Parked = append(data.Parked, parked)
+			default:
+				return emailchecker.ListData{}, fmt.Errorf("unknown list %q", list)
+			}
+		}
+	}
+
+	if data.Len(list) == 0 {
+		return emailchecker.ListData{}, errors.New("no domains found")
+	}
+
+	return data, nil
+}
+
+func normalize(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+}
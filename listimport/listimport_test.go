@@ -0,0 +1,126 @@
+package listimport_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/listimport"
+)
+
+func TestRead_Disposable(t *testing.T) {
+	cases := []struct {
+		name   string
+		format emailchecker.ImportFormat
+		input  string
+	}{
+		{name: "Text", format: emailchecker.ImportText, input: "# comment\nTrash.Example.\n\nmailinator.com\n"},
+		{name: "CSV", format: emailchecker.ImportCSV, input: "trash.example,2024\nmailinator.com\n"},
+		{name: "CSV with header", format: emailchecker.ImportCSV, input: "added,domain\n2024,trash.example\n2024,mailinator.com\n"},
+		{name: "JSON", format: emailchecker.ImportJSON, input: `["trash.example", {"domain": "mailinator.com"}]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := listimport.Read(strings.NewReader(tc.input), emailchecker.ListDisposable, tc.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, []emailchecker.DisposableDomain{
+				{Domain: "trash.example", Sources: []string{listimport.Source}},
+				{Domain: "mailinator.com", Sources: []string{listimport.Source}},
+			}, data.Disposable)
+		})
+	}
+}
+
+func TestRead_Top(t *testing.T) {
+	cases := []struct {
+		name   string
+		format emailchecker.ImportFormat
+		input  string
+		want   []emailchecker.TopDomain
+	}{
+		{
+			name:   "Text in rank order",
+			format: emailchecker.ImportText,
+			input:  "google.com\nfacebook.com\n",
+			want:   []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "facebook.com", Rank: 2}},
+		},
+		{
+			name:   "Tranco CSV",
+			format: emailchecker.ImportCSV,
+			input:  "1,google.com\n5,facebook.com\n",
+			want:   []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "facebook.com", Rank: 5}},
+		},
+		{
+			name:   "JSON objects",
+			format: emailchecker.ImportJSON,
+			input:  `[{"domain": "google.com", "rank": 3}, "facebook.com"]`,
+			want:   []emailchecker.TopDomain{{Domain: "google.com", Rank: 3}, {Domain: "facebook.com", Rank: 2}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := listimport.Read(strings.NewReader(tc.input), emailchecker.ListTop, tc.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, data.Top)
+		})
+	}
+}
+
+func TestRead_Educational(t *testing.T) {
+	input := `[{"name": "Massachusetts Institute of Technology", "country": "United States", "alpha_two_code": "US",
+		"domains": ["mit.edu", "alum.mit.edu"], "web_pages": ["https://mit.edu/"]}]`
+
+	data, err := listimport.Read(strings.NewReader(input), emailchecker.ListEducational, emailchecker.ImportJSON)
+	require.NoError(t, err)
+
+	require.Len(t, data.Educational, 2)
+	assert.Equal(t, "alum.mit.edu", data.Educational[1].Domain)
+	assert.Equal(t, "Massachusetts Institute of Technology", data.Educational[1].Name)
+	assert.Equal(t, "US", data.Educational[1].AlphaTwoCode)
+	assert.Equal(t, []string{"https://mit.edu/"}, data.Educational[1].WebPages)
+}
+
+func TestRead_Parked(t *testing.T) {
+	cases := []struct {
+		name   string
+		format emailchecker.ImportFormat
+		input  string
+	}{
+		{name: "Text", format: emailchecker.ImportText, input: "# parking\nSedoParking.com.\n185.53.176.0/22\n2606:4700:20::681a:625\n"},
+		{name: "JSON", format: emailchecker.ImportJSON, input: `["sedoparking.com", {"domain": "185.53.177.1/22"}, "2606:4700:20::681a:625"]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := listimport.Read(strings.NewReader(tc.input), emailchecker.ListParked, tc.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, []emailchecker.ParkedEntry{
+				{Value: "sedoparking.com", Kind: emailchecker.ParkedNameServer},
+				{Value: "185.53.176.0/22", Kind: emailchecker.ParkedAddress},
+				{Value: "2606:4700:20::681a:625/128", Kind: emailchecker.ParkedAddress},
+			}, data.Parked)
+		})
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	_, err := listimport.Read(strings.NewReader("# nothing\n"), emailchecker.ListDisposable, emailchecker.ImportText)
+	assert.Error(t, err)
+
+	_, err = listimport.Read(strings.NewReader(`{"domain": "a.example"}`), emailchecker.ListDisposable, emailchecker.ImportJSON)
+	assert.Error(t, err)
+
+	_, err = listimport.Read(strings.NewReader("x,google.com\n"), emailchecker.ListTop, emailchecker.ImportCSV)
+	assert.Error(t, err)
+
+	_, err = listimport.Read(strings.NewReader("185.53.176.0/40\n"), emailchecker.ListParked, emailchecker.ImportText)
+	assert.Error(t, err)
+}
@@ -7,25 +7,73 @@ type EmailPatternCheckResult struct {
 	HasRandomPattern          bool `json:"has_random_pattern"`
 	TooManyConsecutiveNumbers bool `json:"too_many_consecutive_numbers"`
 	TooManySpecialChars       bool `json:"too_many_special_chars"`
+	// Features are the measures HasRandomPattern is derived from.
+	Features PatternFeatures `json:"features"`
+}
+
+// PatternFeature is a measure of a local part and whether it crossed its
+// threshold.
+type PatternFeature struct {
+	Value     float64 `json:"value"`
+	Triggered bool    `json:"triggered"`
+}
+
+// PatternFeatures are the measures of a local part. It has a random pattern
+// when the keyboard sequence, consecutive numbers, special chars, case
+// switches, script letters or gibberish feature triggers, or when the name
+// likeness feature triggers on a local part of at least MinRandomNameLength,
+// or together with entropy unless the name model scored the local part as
+// name-like.
+type PatternFeatures struct {
+	// Entropy is the Shannon entropy in bits per character.
+	Entropy PatternFeature `json:"entropy"`
+	// KeyboardSequence is the length of the longest keyboard walk.
+	KeyboardSequence PatternFeature `json:"keyboard_sequence"`
+	// ConsecutiveNumbers is the length of the longest run of digits.
+	ConsecutiveNumbers PatternFeature `json:"consecutive_numbers"`
+	// SpecialChars is the share of characters that are neither letters nor
+	// digits.
+	SpecialChars PatternFeature `json:"special_chars"`
+	// CaseSwitches is the number of lower/upper case switches per character.
+	CaseSwitches PatternFeature `json:"case_switches"`
+	// NameLikeness is the share of the parts of the local part that look
+	// like a name part. It triggers below 1.
+	NameLikeness PatternFeature `json:"name_likeness"`
+	// ScriptLetters is the share of letters. It triggers when the local part
+	// does not match the usual name pattern and has few letters.
+	ScriptLetters PatternFeature `json:"script_letters"`
+	// Gibberish is the number of bits per letter the name model of the
+	// script needs to encode the letters; names score low. It is 0 when no
+	// letter has a model.
+	Gibberish PatternFeature `json:"gibberish"`
+	// GibberishScript is the script of the model that scored the letters.
+	GibberishScript string `json:"gibberish_script,omitempty"`
 }
 
 type DNSValidationResult struct {
-	Domain      string     `json:"domain"`
-	HasMX       bool       `json:"has_mx"`
-	HasSPF      bool       `json:"has_spf"`
-	HasDMARC    bool       `json:"has_dmarc"`
-	IsParked    bool       `json:"is_parked"`
+	Domain   string `json:"domain"`
+	HasMX    bool   `json:"has_mx"`
+	HasSPF   bool   `json:"has_spf"`
+	HasDMARC bool   `json:"has_dmarc"`
+	IsParked bool   `json:"is_parked"`
+	// ImplicitMX is set when the domain has no MX records but has A or AAAA
+	// records, which mail servers fall back to.
+	ImplicitMX  bool       `json:"implicit_mx"`
 	ARecords    []string   `json:"a_records"`
+	AAAARecords []string   `json:"aaaa_records"`
 	NSRecords   []string   `json:"ns_records"`
 	MXRecords   []MXRecord `json:"mx_records"`
 	SPFRecord   string     `json:"spf_record"`
 	DMARCRecord string     `json:"dmarc_record"`
+	Trace       *DNSTrace  `json:"trace,omitempty"`
 }
 
 type MXRecord struct {
 	Value      string `json:"value"`
 	Priority   int    `json:"priority"`
 	Disposable bool   `json:"disposable"`
+	// IPs holds the IPv4 and IPv6 addresses of the MX host.
+	IPs []string `json:"ips,omitempty"`
 }
 
 type DNSRecord struct {
@@ -34,15 +82,145 @@ type DNSRecord struct {
 	CreatedAt time.Time
 }
 
+// DisposableDomain is an entry of the disposable list and the names of the
+// sources that listed it.
+type DisposableDomain struct {
+	Domain  string   `json:"domain"`
+	Sources []string `json:"sources"`
+	// Override is set when the entry comes from a local override.
+	Override bool `json:"override,omitempty"`
+	// Excluded is set when a local override declares the domain not
+	// disposable, which also disables the strict mode rules.
+	Excluded bool `json:"excluded,omitempty"`
+}
+
+// TopDomain is an entry of the top sites list. Rank is the position in the
+// list, starting at 1, and is zero when unknown.
+type TopDomain struct {
+	Domain string `json:"domain"`
+	Rank   int    `json:"rank"`
+}
+
+// EducationalInstitution is an entry of the educational list. An institution
+// with several domains has an entry per domain.
+type EducationalInstitution struct {
+	Domain       string   `json:"domain"`
+	Name         string   `json:"name,omitempty"`
+	Country      string   `json:"country,omitempty"`
+	AlphaTwoCode string   `json:"alpha_two_code,omitempty"`
+	WebPages     []string `json:"web_pages,omitempty"`
+}
+
+// EducationalCheckResult describes the institution of an educational domain.
+// The institution fields are empty when the domain is educational by an
+// override.
+type EducationalCheckResult struct {
+	Educational bool `json:"educational"`
+	// MatchedDomain is the list entry that matched, which may be a parent
+	// of the checked domain.
+	MatchedDomain string `json:"matched_domain,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Country       string `json:"country,omitempty"`
+	AlphaTwoCode  string `json:"alpha_two_code,omitempty"`
+}
+
+// ConfusableCheckResult tells whether an address imitates another with
+// characters that look alike, as defined by Unicode Technical Standard #39.
+type ConfusableCheckResult struct {
+	// Confusable is set when the domain imitates a well-known domain or when
+	// the domain or the local part mixes scripts.
+	Confusable bool `json:"confusable"`
+	// ImitatedDomain is the well-known domain that shares the skeleton of
+	// the organizational domain, such as gmail.com for gmaіl.com written
+	// with a Cyrillic і.
+	ImitatedDomain string `json:"imitated_domain,omitempty"`
+	// ImitatedRank is the top sites rank of ImitatedDomain, zero when it is
+	// unranked.
+	ImitatedRank int `json:"imitated_rank,omitempty"`
+	// Skeleton is the skeleton of the organizational domain.
+	Skeleton string `json:"skeleton"`
+	// MixedScriptDomain is set when a label of the domain mixes scripts.
+	MixedScriptDomain bool `json:"mixed_script_domain"`
+	// MixedScriptLocalPart is set when the local part mixes scripts that are
+	// not written together, as Han and Hiragana are.
+	MixedScriptLocalPart bool `json:"mixed_script_local_part"`
+	// LocalPartScripts are the scripts of the letters of the local part.
+	LocalPartScripts []string `json:"local_part_scripts,omitempty"`
+}
+
+// DisposableReason explains why a domain was considered disposable.
+type DisposableReason string
+
+const (
+	DisposableReasonListed   DisposableReason = "listed"
+	DisposableReasonOverride DisposableReason = "override"
+	// The following reasons are only reported in strict mode.
+	DisposableReasonDisposableMX       DisposableReason = "disposable_mx"
+	DisposableReasonDisposableNS       DisposableReason = "disposable_ns"
+	DisposableReasonRecentlyRegistered DisposableReason = "recently_registered"
+	DisposableReasonSharedInfra        DisposableReason = "shared_infrastructure"
+)
+
+type DisposableCheckResult struct {
+	Disposable bool             `json:"disposable"`
+	Reason     DisposableReason `json:"reason,omitempty"`
+	// MatchedDomain is the list entry that matched, which may be a parent
+	// of the checked domain, or the MX or NS host that triggered a strict rule.
+	MatchedDomain string     `json:"matched_domain,omitempty"`
+	Sources       []string   `json:"sources,omitempty"`
+	RegisteredAt  *time.Time `json:"registered_at,omitempty"`
+	// Confidence is the infrastructure match score behind the
+	// shared_infrastructure reason.
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+type DomainRegistration struct {
+	Domain string
+	// RegisteredAt is nil when the registry does not publish the date.
+	RegisteredAt *time.Time
+	CheckedAt    time.Time
+}
+
+type DNSCacheStats struct {
+	Records int        `json:"records"`
+	Stale   int        `json:"stale"`
+	Oldest  *time.Time `json:"oldest,omitempty"`
+	Newest  *time.Time `json:"newest,omitempty"`
+}
+
+type DNSPrewarmResult struct {
+	Total         int      `json:"total"`
+	Resolved      int      `json:"resolved"`
+	Failed        int      `json:"failed"`
+	FailedDomains []string `json:"failed_domains,omitempty"`
+}
+
 type EmailCheckResult struct {
 	Email       string                                  `json:"email"`
-	Disposable  SubCheckResult[bool]                    `json:"disposable"`
+	Domain      Domain                                  `json:"domain"`
+	Disposable  SubCheckResult[DisposableCheckResult]   `json:"disposable"`
 	WellKnown   SubCheckResult[bool]                    `json:"well_known"`
-	Educational SubCheckResult[bool]                    `json:"educational"`
+	Educational SubCheckResult[EducationalCheckResult]  `json:"educational"`
 	DNS         SubCheckResult[DNSValidationResult]     `json:"dns"`
 	Elapsed     time.Duration                           `json:"elapsed"`
 	Pattern     SubCheckResult[EmailPatternCheckResult] `json:"pattern"`
 	Analysis    *AnalysisReport                         `json:"prediction"`
+	// Readiness tells whether the lists behind the result held fresh data.
+	Readiness *Readiness `json:"readiness,omitempty"`
+	// WellKnownRank is the top sites rank of a well-known domain, zero when
+	// the domain is unranked.
+	WellKnownRank int `json:"well_known_rank,omitempty"`
+	// ProviderType is empty when the domain is not in the provider table.
+	ProviderType ProviderType `json:"provider_type,omitempty"`
+	// IsFreeEmail is set for the free webmail, ISP and privacy providers,
+	// whose mailboxes anyone can get.
+	IsFreeEmail bool `json:"is_free_email"`
+	// Sector classifies government, military, intergovernmental and
+	// non-profit domains.
+	Sector SubCheckResult[SectorCheckResult] `json:"sector"`
+	// Confusable tells whether the address imitates another with look-alike
+	// characters.
+	Confusable SubCheckResult[ConfusableCheckResult] `json:"confusable"`
 }
 
 type SubCheckResult[T any] struct {
@@ -59,9 +237,14 @@ type EmailCheckParams struct {
 	// DisposableTimeout is the timeout for checking disposable emails.
 	// If not set, a default value of 200ms will be used.
 	DisposableTimeout time.Duration
-	// If true, the disposable check will be strict.
+	// If true, the disposable check will be strict: besides list membership
+	// a domain is disposable when its MX or NS hosts belong to disposable
+	// domains or when it was registered within DisposableMinDomainAge.
 	// Default is false.
 	DisposableStrict bool
+	// DisposableMinDomainAge is the age below which a domain is disposable
+	// in strict mode. If not set, a default value of 30 days will be used.
+	DisposableMinDomainAge time.Duration
 	// SkipDNS indicates whether to skip the DNS check.
 	SkipDNS bool
 	// SkipWellKnown indicates whether to skip the well-known email provider check.
@@ -70,6 +253,16 @@ type EmailCheckParams struct {
 	SkipPatternCheck bool
 	// SkipEducationalDomains indicates whether to skip the educational domain check.
 	SkipEducationalDomains bool
+	// SkipSector indicates whether to skip the public sector check.
+	SkipSector bool
+	// SkipConfusable indicates whether to skip the confusable check.
+	SkipConfusable bool
+	// Debug attaches a trace of every DNS query to the DNS sub-result.
+	// Traced lookups bypass request coalescing so the trace is complete.
+	Debug bool
+	// PatternOverrides replaces the configured pattern thresholds that are
+	// set for this check only.
+	PatternOverrides PatternConfig
 }
 
 type AnalysisReport struct {
@@ -85,3 +278,92 @@ const (
 	RiskLevelMedium RiskLevel = "medium"
 	RiskLevelHigh   RiskLevel = "high"
 )
+
+// ListRefreshResult reports what a refresh changed in a domain list.
+type ListRefreshResult struct {
+	List ListName `json:"list"`
+	// Skipped is set when the list was refreshed recently enough.
+	Skipped bool `json:"skipped,omitempty"`
+	// NotModified is set when the upstream data did not change.
+	NotModified bool          `json:"not_modified,omitempty"`
+	Added       int64         `json:"added"`
+	Removed     int64         `json:"removed"`
+	Updated     int64         `json:"updated"`
+	Total       int64         `json:"total"`
+	Elapsed     time.Duration `json:"elapsed"`
+}
+
+// ListGuard rejects a refresh whose data looks truncated or wrong, so that a
+// bad upstream file never replaces the live list.
+type ListGuard struct {
+	// MinRows is the minimum number of domains of the new list.
+	MinRows int64
+	// MaxShrink is the largest allowed drop in size, as a fraction of the
+	// current list. Zero disables the check.
+	MaxShrink float64
+	// MaxRows is the number of domains the list is capped at, zero when it
+	// is not. The shrink is measured from at most MaxRows domains, so that
+	// lowering the cap is not mistaken for a truncated download.
+	MaxRows int64
+	// Samples must all be present in the new list.
+	Samples []string
+}
+
+// ListSnapshot is a copy of a domain list taken before a refresh changed it.
+type ListSnapshot struct {
+	ID        int64     `json:"id"`
+	List      ListName  `json:"list"`
+	Rows      int64     `json:"rows"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListDataSource tells where the data of a list came from.
+type ListDataSource string
+
+const (
+	// ListDataEmbedded is the snapshot shipped with the binary.
+	ListDataEmbedded ListDataSource = "embedded"
+	ListDataRemote   ListDataSource = "remote"
+	// ListDataImported is a local file loaded with an import.
+	ListDataImported ListDataSource = "imported"
+)
+
+// ListVersion identifies the data a list currently holds.
+type ListVersion struct {
+	List   ListName       `json:"list"`
+	Source ListDataSource `json:"source"`
+	// Version is the date of the data: the generation date of the embedded
+	// snapshot, or the day the list was last downloaded or imported.
+	Version string `json:"version"`
+	Rows    int64  `json:"rows"`
+	// RefreshedAt is the last successful download, nil when the list was
+	// never downloaded.
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+}
+
+// RefreshStatus records the outcome of the refresh attempts of a list.
+type RefreshStatus struct {
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	// LastError is the error of the last attempt, empty when it succeeded.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// DataSource is a location a list is downloaded from.
+type DataSource struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	// Allowlist is set for disposable sources that remove domains.
+	Allowlist bool `json:"allowlist,omitempty"`
+}
+
+// SourceStatus reports the data of a list, where it comes from and how its
+// last refreshes went.
+type SourceStatus struct {
+	ListVersion
+	RefreshStatus
+	Sources []DataSource `json:"sources"`
+	// Import is the last import of the list, nil when it was never imported.
+	Import *ListImport `json:"import,omitempty"`
+}
@@ -0,0 +1,404 @@
+// Package namemodel scores how name-like a word is with character trigram
+// models trained on names, one model per script.
+//
+// A model is trained from a corpus of names, where every run of letters is a
+// name, and stored as a gzipped file of tab separated script, n-gram and
+// count lines. The embedded model is trained from names.txt; run go generate
+// after editing it.
+package namemodel
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"emailchecker/pkg/unicodeext"
+)
+
+//go:generate go run ../cmd/email-checker train-name-model --corpus names.txt --out model.txt.gz
+
+//go:embed model.txt.gz
+var defaultModel []byte
+
+const (
+	// order is the length of the longest n-gram.
+	order = 3
+
+	// start pads the beginning of a word and end terminates it.
+	start = '^'
+	end   = '$'
+
+	header = "# emailchecker name model"
+)
+
+// weights are the interpolation weights of the unigram, bigram and trigram
+// probabilities.
+var weights = [order]float64{0.1, 0.3, 0.6}
+
+// foldScripts are the scripts whose diacritics are dropped, so that josé and
+// jose score the same.
+var foldScripts = []string{"Latin", "Greek", "Cyrillic"}
+
+// Model is a set of character n-gram models of names, keyed by script.
+type Model struct {
+	scripts map[string]*scriptModel
+}
+
+type scriptModel struct {
+	grams    map[string]int
+	contexts map[string]int
+	alphabet int
+}
+
+// Score is how name-like a string is.
+type Score struct {
+	// Script is the script of most of the scored letters.
+	Script string
+	// Bits is the average number of bits per character the model needs to
+	// encode the letters. Names score low, gibberish scores high.
+	Bits float64
+	// Letters is the number of scored letters. Letters of scripts without a
+	// model are not scored.
+	Letters int
+}
+
+var loadDefault = sync.OnceValue(func() *Model {
+	m, err := Read(bytes.NewReader(defaultModel))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded name model: %v", err))
+	}
+
+	return m
+})
+
+// Default returns the model shipped with the binary.
+func Default() *Model {
+	return loadDefault()
+}
+
+// Train builds a model from a corpus of names.
+func Train(r io.Reader) (*Model, error) {
+	m := &Model{scripts: make(map[string]*scriptModel)}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, w := range words(line) {
+			sm, ok := m.scripts[w.script]
+			if !ok {
+				sm = &scriptModel{grams: make(map[string]int)}
+				m.scripts[w.script] = sm
+			}
+
+			for gram := range ngrams(w.letters) {
+				sm.grams[gram]++
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(m.scripts) == 0 {
+		return nil, errors.New("no names found in the corpus")
+	}
+
+	m.index()
+
+	return m, nil
+}
+
+// Read reads a model written by Write.
+func Read(r io.Reader) (*Model, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not open name model: %w", err)
+	}
+	defer zr.Close() //nolint:errcheck
+
+	m := &Model{scripts: make(map[string]*scriptModel)}
+
+	scanner := bufio.NewScanner(zr)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid name model line %d: %q", n, line)
+		}
+
+		count, err := strconv.Atoi(fields[2])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid name model line %d: %q", n, line)
+		}
+
+		sm, ok := m.scripts[fields[0]]
+		if !ok {
+			sm = &scriptModel{grams: make(map[string]int)}
+			m.scripts[fields[0]] = sm
+		}
+
+		sm.grams[fields[1]] += count
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read name model: %w", err)
+	}
+
+	if len(m.scripts) == 0 {
+		return nil, errors.New("empty name model")
+	}
+
+	m.index()
+
+	return m, nil
+}
+
+// Load reads the model of a local file.
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	m, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Write writes the model, gzipped, in a stable order.
+func (m *Model) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+
+	bw := bufio.NewWriter(zw)
+	fmt.Fprintln(bw, header)
+
+	for _, script := range m.Scripts() {
+		sm := m.scripts[script]
+
+		grams := make([]string, 0, len(sm.grams))
+		for gram := range sm.grams {
+			grams = append(grams, gram)
+		}
+
+		slices.Sort(grams)
+
+		for _, gram := range grams {
+			fmt.Fprintf(bw, "%s\t%s\t%d\n", script, gram, sm.grams[gram])
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// Scripts returns the scripts the model has a model for.
+func (m *Model) Scripts() []string {
+	scripts := make([]string, 0, len(m.scripts))
+	for script := range m.scripts {
+		scripts = append(scripts, script)
+	}
+
+	slices.Sort(scripts)
+
+	return scripts
+}
+
+// Score scores the letters of s. Digits, punctuation and other characters
+// separate words, as do changes of script.
+func (m *Model) Score(s string) Score {
+	var (
+		ans         Score
+		bits        float64
+		transitions int
+	)
+
+	letters := make(map[string]int)
+
+	for _, w := range words(s) {
+		sm, ok := m.scripts[w.script]
+		if !ok {
+			continue
+		}
+
+		padded := pad(w.letters)
+		for i := order - 1; i < len(padded); i++ {
+			bits -= math.Log2(sm.prob(padded[i-order+1 : i+1]))
+			transitions++
+		}
+
+		letters[w.script] += len(w.letters)
+		ans.Letters += len(w.letters)
+	}
+
+	if transitions == 0 {
+		return ans
+	}
+
+	ans.Bits = bits / float64(transitions)
+
+	for script, n := range letters {
+		if n > letters[ans.Script] || (n == letters[ans.Script] && script < ans.Script) {
+			ans.Script = script
+		}
+	}
+
+	return ans
+}
+
+// index counts the contexts of the n-grams.
+func (m *Model) index() {
+	for _, sm := range m.scripts {
+		sm.contexts = make(map[string]int)
+		sm.alphabet = 0
+
+		for gram, count := range sm.grams {
+			runes := []rune(gram)
+			if len(runes) == 1 {
+				sm.alphabet++
+			}
+
+			sm.contexts[string(runes[:len(runes)-1])] += count
+		}
+	}
+}
+
+// prob returns the probability of the last rune of gram after the others,
+// interpolating the trigram, bigram and add-one smoothed unigram estimates.
+// Estimates whose context was never seen give their weight to the others.
+func (sm *scriptModel) prob(gram []rune) float64 {
+	var p, total float64
+
+	for n := 1; n <= order; n++ {
+		g := gram[len(gram)-n:]
+
+		if n == 1 {
+			chars := sm.contexts[""]
+			p += weights[0] * float64(sm.grams[string(g)]+1) / float64(chars+sm.alphabet+1)
+			total += weights[0]
+
+			continue
+		}
+
+		ctx := sm.contexts[string(g[:n-1])]
+		if ctx == 0 {
+			continue
+		}
+
+		p += weights[n-1] * float64(sm.grams[string(g)]) / float64(ctx)
+		total += weights[n-1]
+	}
+
+	return p / total
+}
+
+type word struct {
+	script  string
+	letters []rune
+}
+
+// words splits s into lower case words of letters and marks of a single
+// script.
+func words(s string) []word {
+	var (
+		ans     []word
+		current word
+	)
+
+	flush := func() {
+		if len(current.letters) > 0 {
+			ans = append(ans, current)
+		}
+
+		current = word{}
+	}
+
+	for _, r := range norm.NFD.String(s) {
+		if unicode.IsMark(r) {
+			if len(current.letters) == 0 || (unicode.Is(unicode.Mn, r) && slices.Contains(foldScripts, current.script)) {
+				continue
+			}
+
+			current.letters = append(current.letters, r)
+
+			continue
+		}
+
+		if !unicode.IsLetter(r) {
+			flush()
+			continue
+		}
+
+		script := unicodeext.Script(r)
+		if script != current.script {
+			flush()
+			current.script = script
+		}
+
+		current.letters = append(current.letters, unicode.ToLower(r))
+	}
+
+	flush()
+
+	for i := range ans {
+		ans[i].letters = []rune(norm.NFC.String(string(ans[i].letters)))
+	}
+
+	return ans
+}
+
+func pad(letters []rune) []rune {
+	padded := make([]rune, 0, len(letters)+order)
+	for range order - 1 {
+		padded = append(padded, start)
+	}
+
+	padded = append(padded, letters...)
+
+	return append(padded, end)
+}
+
+// ngrams yields the n-grams of a word, from unigrams to the order of the
+// model, ending at every letter and at the end of the word.
+func ngrams(letters []rune) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		padded := pad(letters)
+
+		for i := order - 1; i < len(padded); i++ {
+			for n := 1; n <= order; n++ {
+				if !yield(string(padded[i-n+1 : i+1])) {
+					return
+				}
+			}
+		}
+	}
+}
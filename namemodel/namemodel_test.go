@@ -0,0 +1,52 @@
+package namemodel_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker/namemodel"
+)
+
+func TestDefault_Score(t *testing.T) {
+	m := namemodel.Default()
+
+	gibberish := m.Score("wjmsfvlb")
+
+	for _, name := range []string{"xiaoqing", "maria.garcia", "josé", "oluwaseun", "Дмитрий", "Γιώργος"} {
+		score := m.Score(name)
+
+		assert.Positive(t, score.Letters, name)
+		assert.Less(t, score.Bits, gibberish.Bits, name)
+	}
+
+	assert.Equal(t, m.Score("jose"), m.Score("josé"), "diacritics of Latin names are folded")
+	assert.Equal(t, 7, m.Score("john1984.doe").Letters)
+
+	score := m.Score("用户")
+	assert.Zero(t, score.Letters, "scripts without a model are not scored")
+	assert.Empty(t, score.Script)
+}
+
+func TestTrain_WriteRead(t *testing.T) {
+	corpus := "# names\nanna hannah\njohanna, joanna\nиван\n"
+
+	m, err := namemodel.Train(strings.NewReader(corpus))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Cyrillic", "Latin"}, m.Scripts())
+
+	var buf bytes.Buffer
+	require.NoError(t, m.Write(&buf))
+
+	read, err := namemodel.Read(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, m.Score("hanna"), read.Score("hanna"))
+	assert.Less(t, read.Score("hanna").Bits, read.Score("qxzv").Bits)
+
+	_, err = namemodel.Train(strings.NewReader("# nothing\n123\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package emailchecker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListName identifies one of the reference domain lists.
+type ListName string
+
+const (
+	ListDisposable  ListName = "disposable"
+	ListTop         ListName = "top"
+	ListEducational ListName = "edu"
+	// ListParked holds the nameservers and address ranges of parking
+	// services. It is curated rather than downloaded and changes by import.
+	ListParked ListName = "parked"
+)
+
+func ParseListName(s string) (ListName, error) {
+	switch name := ListName(strings.ToLower(strings.TrimSpace(s))); name {
+	case ListDisposable, ListTop, ListEducational, ListParked:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown list %q: expected disposable, top, edu or parked", s)
+	}
+}
+
+// downloaded reports whether a list is refreshed from upstream sources.
+func (l ListName) downloaded() bool {
+	return l != ListParked
+}
+
+// OverrideAction tells whether an override forces a domain onto a list or
+// removes it from the list.
+type OverrideAction string
+
+const (
+	OverrideInclude OverrideAction = "include"
+	OverrideExclude OverrideAction = "exclude"
+)
+
+func ParseOverrideAction(s string) (OverrideAction, error) {
+	switch action := OverrideAction(strings.ToLower(strings.TrimSpace(s))); action {
+	case OverrideInclude, OverrideExclude:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown override action %q: expected include or exclude", s)
+	}
+}
+
+// DomainOverride is a locally managed list entry. Overrides are kept apart
+// from the fetched lists, survive list refreshes and take precedence over
+// fetched data.
+type DomainOverride struct {
+	List      ListName       `json:"list"`
+	Domain    string         `json:"domain"`
+	Action    OverrideAction `json:"action"`
+	Reason    string         `json:"reason"`
+	Author    string         `json:"author"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (o *DomainOverride) Validate() error {
+	if o == nil {
+		return fmt.Errorf("%w: override cannot be nil", ErrInvalidOverride)
+	}
+
+	if _, err := ParseListName(string(o.List)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOverride, err)
+	}
+
+	if o.List == ListParked {
+		return fmt.Errorf("%w: the parked list has no overrides, import it instead", ErrInvalidOverride)
+	}
+
+	if _, err := ParseOverrideAction(string(o.Action)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOverride, err)
+	}
+
+	if strings.TrimSpace(o.Domain) == "" {
+		return fmt.Errorf("%w: domain is required", ErrInvalidOverride)
+	}
+
+	if strings.TrimSpace(o.Reason) == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidOverride)
+	}
+
+	if strings.TrimSpace(o.Author) == "" {
+		return fmt.Errorf("%w: author is required", ErrInvalidOverride)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package emailchecker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emailchecker"
+)
+
+func TestDomainOverride_Validate(t *testing.T) {
+	valid := func() *emailchecker.DomainOverride {
+		return &emailchecker.DomainOverride{
+			List:   emailchecker.ListDisposable,
+			Domain: "isp.example",
+			Action: emailchecker.OverrideExclude,
+			Reason: "regional ISP",
+			Author: "support",
+		}
+	}
+
+	cases := []struct {
+		name   string
+		modify func(o *emailchecker.DomainOverride)
+		valid  bool
+	}{
+		{name: "Valid", modify: func(*emailchecker.DomainOverride) {}, valid: true},
+		{name: "Unknown list", modify: func(o *emailchecker.DomainOverride) { o.List = "spam" }},
+		{name: "Parked list", modify: func(o *emailchecker.DomainOverride) { o.List = emailchecker.ListParked }},
+		{name: "Unknown action", modify: func(o *emailchecker.DomainOverride) { o.Action = "block" }},
+		{name: "No domain", modify: func(o *emailchecker.DomainOverride) { o.Domain = " " }},
+		{name: "No reason", modify: func(o *emailchecker.DomainOverride) { o.Reason = "" }},
+		{name: "No author", modify: func(o *emailchecker.DomainOverride) { o.Author = "" }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := valid()
+			tc.modify(o)
+
+			err := o.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, emailchecker.ErrInvalidOverride)
+			}
+		})
+	}
+
+	var o *emailchecker.DomainOverride
+	assert.ErrorIs(t, o.Validate(), emailchecker.ErrInvalidOverride)
+}
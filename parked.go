@@ -0,0 +1,56 @@
+package emailchecker
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParkedKind tells what an entry of the parked list is matched against.
+type ParkedKind string
+
+const (
+	// ParkedNameServer matches a nameserver and its subdomains.
+	ParkedNameServer ParkedKind = "ns"
+	// ParkedAddress matches the A and AAAA answers within a range.
+	ParkedAddress ParkedKind = "ip"
+)
+
+// ParkedEntry is a nameserver or an address range of a parking service.
+// Address ranges are in CIDR notation.
+type ParkedEntry struct {
+	Value string     `json:"value"`
+	Kind  ParkedKind `json:"kind"`
+}
+
+// ParseParkedEntry reads a nameserver, a CIDR or a single address, which is
+// stored as a range of one address.
+func ParseParkedEntry(s string) (ParkedEntry, error) {
+	s = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "."))
+
+	if s == "" || strings.ContainsAny(s, " \t,") {
+		return ParkedEntry{}, fmt.Errorf("invalid parked entry %q", s)
+	}
+
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return ParkedEntry{}, fmt.Errorf("invalid parked address range %q: %w", s, err)
+		}
+
+		return ParkedEntry{Value: network.String(), Kind: ParkedAddress}, nil
+	}
+
+	if ip := net.ParseIP(s); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+
+		network := net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+
+		return ParkedEntry{Value: network.String(), Kind: ParkedAddress}, nil
+	}
+
+	return ParkedEntry{Value: s, Kind: ParkedNameServer}, nil
+}
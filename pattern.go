@@ -0,0 +1,232 @@
+package emailchecker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PatternConfig holds the thresholds of the email pattern heuristics. A field
+// is set when it is not zero or was given, even as zero, through Set or JSON.
+// The checker uses its default for unset fields, and a per-request override
+// keeps the configured value.
+type PatternConfig struct {
+	// MinLocalPartLength is the length below which a local part is short.
+	MinLocalPartLength int `json:"min_local_part_length"`
+	// MaxConsecutiveNumbers is the longest allowed run of digits.
+	MaxConsecutiveNumbers int `json:"max_consecutive_numbers"`
+	// MaxSpecialCharRatio is the largest allowed share of characters that
+	// are neither letters nor digits.
+	MaxSpecialCharRatio float64 `json:"max_special_char_ratio"`
+	// HighEntropyThreshold is the Shannon entropy, in bits per character,
+	// above which a local part is high entropy.
+	HighEntropyThreshold float64 `json:"high_entropy_threshold"`
+	// MinEntropyLength is the shortest local part whose entropy is checked.
+	MinEntropyLength int `json:"min_entropy_length"`
+	// MinKeyboardSeqLength is the shortest keyboard walk, such as qwer, that
+	// makes a local part random.
+	MinKeyboardSeqLength int `json:"min_keyboard_seq_length"`
+	// MinCaseSwitchLength is the shortest local part whose case switches are
+	// counted.
+	MinCaseSwitchLength int `json:"min_case_switch_length"`
+	// MaxCaseSwitchRatio is the largest allowed number of switches between
+	// lower and upper case, as a share of the length.
+	MaxCaseSwitchRatio float64 `json:"max_case_switch_ratio"`
+	// MinRandomNameLength is the length from which a local part that does
+	// not look like a name is random.
+	MinRandomNameLength int `json:"min_random_name_length"`
+	// MaxLetterDigitSwitches is the largest allowed number of switches
+	// between letters and digits in a name part.
+	MaxLetterDigitSwitches int `json:"max_letter_digit_switches"`
+	// MinDigitRatioLength is the shortest name part whose share of digits
+	// is checked.
+	MinDigitRatioLength int `json:"min_digit_ratio_length"`
+	// MaxNameDigitRatio is the largest allowed share of digits in a name
+	// part.
+	MaxNameDigitRatio float64 `json:"max_name_digit_ratio"`
+	// MaxTrailingDigits is the longest number allowed after a name, as in
+	// john1984.
+	MaxTrailingDigits int `json:"max_trailing_digits"`
+	// MinScriptLetterRatio is the smallest share of letters of a local part
+	// that does not match the usual name pattern but is still written in a
+	// script.
+	MinScriptLetterRatio float64 `json:"min_script_letter_ratio"`
+	// MaxGibberishBits is the largest allowed number of bits per letter the
+	// name model needs to encode a local part; names need few, keyboard
+	// mashes such as kjhgdfs need many.
+	MaxGibberishBits float64 `json:"max_gibberish_bits"`
+	// MinGibberishLength is the smallest number of letters the name model
+	// scores before it can make a local part random.
+	MinGibberishLength int `json:"min_gibberish_length"`
+
+	// set has the bit of each field, in the order of fields, that was given
+	// explicitly.
+	set uint32
+}
+
+// patternField is a PatternConfig field; exactly one of i and f is set.
+type patternField struct {
+	name string
+	i    *int
+	f    *float64
+}
+
+func (f patternField) zero() bool {
+	if f.i != nil {
+		return *f.i == 0
+	}
+
+	return *f.f == 0
+}
+
+// isSet tells whether the field at index i of fields is set.
+func (c *PatternConfig) isSet(i int, f patternField) bool {
+	return c.set&(1<<i) != 0 || !f.zero()
+}
+
+func (c *PatternConfig) fields() []patternField {
+	return []patternField{
+		{name: "min_local_part_length", i: &c.MinLocalPartLength},
+		{name: "max_consecutive_numbers", i: &c.MaxConsecutiveNumbers},
+		{name: "max_special_char_ratio", f: &c.MaxSpecialCharRatio},
+		{name: "high_entropy_threshold", f: &c.HighEntropyThreshold},
+		{name: "min_entropy_length", i: &c.MinEntropyLength},
+		{name: "min_keyboard_seq_length", i: &c.MinKeyboardSeqLength},
+		{name: "min_case_switch_length", i: &c.MinCaseSwitchLength},
+		{name: "max_case_switch_ratio", f: &c.MaxCaseSwitchRatio},
+		{name: "min_random_name_length", i: &c.MinRandomNameLength},
+		{name: "max_letter_digit_switches", i: &c.MaxLetterDigitSwitches},
+		{name: "min_digit_ratio_length", i: &c.MinDigitRatioLength},
+		{name: "max_name_digit_ratio", f: &c.MaxNameDigitRatio},
+		{name: "max_trailing_digits", i: &c.MaxTrailingDigits},
+		{name: "min_script_letter_ratio", f: &c.MinScriptLetterRatio},
+		{name: "max_gibberish_bits", f: &c.MaxGibberishBits},
+		{name: "min_gibberish_length", i: &c.MinGibberishLength},
+	}
+}
+
+// PatternConfigNames returns the names of the thresholds, as used in JSON.
+func PatternConfigNames() []string {
+	var c PatternConfig
+
+	fields := c.fields()
+	names := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+
+	return names
+}
+
+// Set parses value into the threshold called name. Zero is a valid
+// threshold.
+func (c *PatternConfig) Set(name, value string) error {
+	for i, f := range c.fields() {
+		if f.name != name {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		if f.i != nil {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q: expected a non-negative number", name, value)
+			}
+
+			*f.i = n
+		} else {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q: expected a non-negative number", name, value)
+			}
+
+			*f.f = n
+		}
+
+		c.set |= 1 << i
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown pattern threshold %q", name)
+}
+
+// UnmarshalJSON decodes the thresholds of a JSON object, marking the ones it
+// holds as set. Unknown thresholds are rejected.
+func (c *PatternConfig) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	fields := c.fields()
+
+	for name, value := range raw {
+		i := slices.IndexFunc(fields, func(f patternField) bool { return f.name == name })
+		if i < 0 {
+			return fmt.Errorf("unknown pattern threshold %q", name)
+		}
+
+		if bytes.Equal(value, []byte("null")) {
+			continue
+		}
+
+		var err error
+		if fields[i].i != nil {
+			err = json.Unmarshal(value, fields[i].i)
+		} else {
+			err = json.Unmarshal(value, fields[i].f)
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		c.set |= 1 << i
+	}
+
+	return nil
+}
+
+// IsZero tells whether no threshold is set.
+func (c PatternConfig) IsZero() bool {
+	return c == PatternConfig{}
+}
+
+// Merge returns c with the set fields of o.
+func (c PatternConfig) Merge(o PatternConfig) PatternConfig {
+	ans := c
+
+	dst, src := ans.fields(), o.fields()
+	for i := range dst {
+		if !o.isSet(i, src[i]) {
+			continue
+		}
+
+		if dst[i].i != nil {
+			*dst[i].i = *src[i].i
+		} else {
+			*dst[i].f = *src[i].f
+		}
+
+		ans.set |= 1 << i
+	}
+
+	return ans
+}
+
+// Validate rejects negative thresholds.
+func (c PatternConfig) Validate() error {
+	for _, f := range c.fields() {
+		if (f.i != nil && *f.i < 0) || (f.f != nil && *f.f < 0) {
+			return fmt.Errorf("%w: %s cannot be negative", ErrInvalidConfig, f.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package httpext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNotModified is returned when a resource did not change since the
+// validators were stored.
+var ErrNotModified = errors.New("not modified")
+
+// Validators are the cache validators of a downloaded resource.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+type ValidatorStore interface {
+	GetValidators(ctx context.Context, key string) (Validators, error)
+	SetValidators(ctx context.Context, key string, v Validators) error
+}
+
+// ConditionalClient issues conditional GET requests. The validators of new
+// responses are kept pending until Commit, so that they are only persisted
+// once the downloaded data has been stored.
+type ConditionalClient struct {
+	client *http.Client
+	store  ValidatorStore
+
+	mu      sync.Mutex
+	pending map[string]Validators
+}
+
+func NewConditionalClient(client *http.Client, store ValidatorStore) *ConditionalClient {
+	return &ConditionalClient{
+		client:  client,
+		store:   store,
+		pending: make(map[string]Validators),
+	}
+}
+
+// Get fetches u and returns ErrNotModified when the stored validators still
+// match. When conditional is false the stored validators are not sent, which
+// forces a full download. The caller must close the body.
+func (c *ConditionalClient) Get(ctx context.Context, u string, conditional bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	if conditional {
+		stored, err := c.store.GetValidators(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+
+		if stored.ETag != "" {
+			req.Header.Set("If-None-Match", stored.ETag)
+		}
+
+		if stored.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stored.LastModified)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.Stage(u, Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		})
+
+		return resp, nil
+	case http.StatusNotModified:
+		drain(resp)
+
+		return nil, ErrNotModified
+	default:
+		drain(resp)
+
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+}
+
+// Stored returns the persisted validators of key.
+func (c *ConditionalClient) Stored(ctx context.Context, key string) (Validators, error) {
+	return c.store.GetValidators(ctx, key)
+}
+
+// Stage records validators to persist on the next Commit.
+func (c *ConditionalClient) Stage(key string, v Validators) {
+	if v.IsZero() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending[key] = v
+}
+
+// Commit persists the pending validators.
+func (c *ConditionalClient) Commit(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, v := range c.pending {
+		if err := c.store.SetValidators(ctx, key, v); err != nil {
+			return err
+		}
+
+		delete(c.pending, key)
+	}
+
+	return nil
+}
+
+// Reset drops the pending validators of an abandoned download.
+func (c *ConditionalClient) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.pending)
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,126 @@
+package httpext_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker/pkg/httpext"
+)
+
+type memoryStore map[string]httpext.Validators
+
+func (m memoryStore) GetValidators(_ context.Context, key string) (httpext.Validators, error) {
+	return m[key], nil
+}
+
+func (m memoryStore) SetValidators(_ context.Context, key string, v httpext.Validators) error {
+	m[key] = v
+	return nil
+}
+
+// newServer serves a resource with the ETag "v1", answering requests that
+// send it with 304 Not Modified.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		_, _ = io.WriteString(w, "mailinator.com\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestConditionalClient_Get(t *testing.T) {
+	v1 := httpext.Validators{ETag: `"v1"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}
+
+	cases := []struct {
+		name        string
+		stored      httpext.Validators
+		conditional bool
+		notModified bool
+	}{
+		{name: "First download", conditional: true},
+		{name: "Unchanged", stored: v1, conditional: true, notModified: true},
+		{name: "Stale validators", stored: httpext.Validators{ETag: `"v0"`}, conditional: true},
+		{name: "Forced download", stored: v1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+			ctx := context.Background()
+
+			store := memoryStore{}
+			if !tc.stored.IsZero() {
+				store[srv.URL] = tc.stored
+			}
+
+			c := httpext.NewConditionalClient(srv.Client(), store)
+
+			resp, err := c.Get(ctx, srv.URL, tc.conditional)
+			if tc.notModified {
+				require.ErrorIs(t, err, httpext.ErrNotModified)
+				assert.Nil(t, resp)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			assert.Equal(t, "mailinator.com\n", string(body))
+
+			// The new validators are only stored on Commit.
+			assert.Equal(t, tc.stored, store[srv.URL])
+
+			require.NoError(t, c.Commit(ctx))
+			assert.Equal(t, v1, store[srv.URL])
+		})
+	}
+}
+
+func TestConditionalClient_Reset(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	store := memoryStore{}
+	c := httpext.NewConditionalClient(srv.Client(), store)
+
+	resp, err := c.Get(ctx, srv.URL, true)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// An abandoned download must not be answered not modified next time.
+	c.Reset()
+	require.NoError(t, c.Commit(ctx))
+	assert.Empty(t, store)
+}
+
+func TestConditionalClient_UnexpectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := httpext.NewConditionalClient(srv.Client(), memoryStore{})
+
+	_, err := c.Get(context.Background(), srv.URL, true)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, httpext.ErrNotModified)
+}
@@ -0,0 +1,16 @@
+package unicodeext
+
+import "unicode"
+
+// Script returns the name of the Unicode script of r, such as Latin or
+// Cyrillic. Characters shared by scripts, such as digits and combining
+// marks, are Common.
+func Script(r rune) string {
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+
+	return "Common"
+}
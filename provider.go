@@ -0,0 +1,80 @@
+package emailchecker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProviderType classifies who hands out the mailboxes of a domain.
+type ProviderType string
+
+const (
+	ProviderFreeWebmail ProviderType = "free_webmail"
+	ProviderISP         ProviderType = "isp"
+	ProviderPrivacy     ProviderType = "privacy"
+	// ProviderCorporate is only reported for domains listed as corporate.
+	// Unlisted domains are left unclassified, since an unknown domain is as
+	// likely an uncurated free or throwaway provider as a company.
+	ProviderCorporate ProviderType = "corporate"
+)
+
+func ParseProviderType(s string) (ProviderType, error) {
+	switch t := ProviderType(strings.ToLower(strings.TrimSpace(s))); t {
+	case ProviderFreeWebmail, ProviderISP, ProviderPrivacy, ProviderCorporate:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unknown provider type %q: expected free_webmail, isp, privacy or corporate", s)
+	}
+}
+
+// IsFree reports whether anyone can get a mailbox of the provider, as
+// opposed to the members of an organization. B2B forms usually reject these
+// addresses.
+func (t ProviderType) IsFree() bool {
+	switch t {
+	case ProviderFreeWebmail, ProviderISP, ProviderPrivacy:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProviderSource tells whether a provider entry ships with the binary or was
+// added locally.
+type ProviderSource string
+
+const (
+	ProviderSourceCurated ProviderSource = "curated"
+	ProviderSourceLocal   ProviderSource = "local"
+)
+
+// EmailProvider is an entry of the provider table. Local entries take
+// precedence over curated ones.
+type EmailProvider struct {
+	Domain    string         `json:"domain"`
+	Type      ProviderType   `json:"type"`
+	Source    ProviderSource `json:"source"`
+	Author    string         `json:"author,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (p *EmailProvider) Validate() error {
+	if p == nil {
+		return fmt.Errorf("%w: provider cannot be nil", ErrInvalidProvider)
+	}
+
+	if _, err := ParseProviderType(string(p.Type)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+	}
+
+	if strings.TrimSpace(p.Domain) == "" {
+		return fmt.Errorf("%w: domain is required", ErrInvalidProvider)
+	}
+
+	if strings.TrimSpace(p.Author) == "" {
+		return fmt.Errorf("%w: author is required", ErrInvalidProvider)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"emailchecker"
+)
+
+//go:embed providers.txt
+var curatedData []byte
+
+type repo interface {
+	SyncCuratedProviders(ctx context.Context, providers []emailchecker.EmailProvider) error
+	GetProvider(ctx context.Context, domains []string) (*emailchecker.EmailProvider, error)
+	UpsertProvider(ctx context.Context, p emailchecker.EmailProvider) error
+	DeleteProvider(ctx context.Context, domain string) (bool, error)
+	ListProviders(ctx context.Context, t emailchecker.ProviderType) ([]emailchecker.EmailProvider, error)
+}
+
+type Option func(*Classifier)
+
+// WithMatchMode sets which names of a domain are matched against the table.
+func WithMatchMode(mode emailchecker.MatchMode) Option {
+	return func(c *Classifier) {
+		c.matchMode = mode
+	}
+}
+
+// Classifier looks domains up in the provider table, which holds the
+// curated list shipped with the binary and the local entries.
+type Classifier struct {
+	repo      repo
+	matchMode emailchecker.MatchMode
+}
+
+// New loads the curated list into the provider table, replacing the one of
+// a previous release.
+func New(ctx context.Context, repo repo, opts ...Option) (*Classifier, error) {
+	ans := Classifier{
+		repo:      repo,
+		matchMode: emailchecker.MatchSuffix,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	curated, err := Curated()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.SyncCuratedProviders(ctx, curated); err != nil {
+		return nil, fmt.Errorf("could not load curated providers: %w", err)
+	}
+
+	return &ans, nil
+}
+
+func (c *Classifier) GetProvider(ctx context.Context, domain emailchecker.Domain) (*emailchecker.EmailProvider, error) {
+	return c.repo.GetProvider(ctx, domain.Candidates(c.matchMode))
+}
+
+func (c *Classifier) SetProvider(ctx context.Context, p emailchecker.EmailProvider) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	return c.repo.UpsertProvider(ctx, p)
+}
+
+func (c *Classifier) RemoveProvider(ctx context.Context, domain string) (bool, error) {
+	return c.repo.DeleteProvider(ctx, domain)
+}
+
+func (c *Classifier) ListProviders(ctx context.Context, t emailchecker.ProviderType) ([]emailchecker.EmailProvider, error) {
+	return c.repo.ListProviders(ctx, t)
+}
+
+// Curated returns the provider list shipped with the binary.
+func Curated() ([]emailchecker.EmailProvider, error) {
+	var ans []emailchecker.EmailProvider
+
+	scanner := bufio.NewScanner(bytes.NewReader(curatedData))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid curated provider on line %d: %q", n, line)
+		}
+
+		t, err := emailchecker.ParseProviderType(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid curated provider on line %d: %w", n, err)
+		}
+
+		ans = append(ans, emailchecker.EmailProvider{
+			Domain: fields[0],
+			Type:   t,
+			Source: emailchecker.ProviderSourceCurated,
+		})
+	}
+
+	return ans, scanner.Err()
+}
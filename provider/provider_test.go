@@ -0,0 +1,27 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/provider"
+)
+
+func TestCurated(t *testing.T) {
+	providers, err := provider.Curated()
+	require.NoError(t, err)
+
+	types := make(map[string]emailchecker.ProviderType, len(providers))
+	for _, p := range providers {
+		_, dup := types[p.Domain]
+		assert.False(t, dup, "%s is listed twice", p.Domain)
+		assert.Equal(t, emailchecker.ProviderSourceCurated, p.Source)
+
+		types[p.Domain] = p.Type
+	}
+
+	assert.Equal(t, emailchecker.ProviderFreeWebmail, types["gmail.com"])
+}
@@ -0,0 +1,64 @@
+package emailchecker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+func TestParseProviderType(t *testing.T) {
+	cases := []struct {
+		in   string
+		want emailchecker.ProviderType
+		free bool
+		err  bool
+	}{
+		{in: "free_webmail", want: emailchecker.ProviderFreeWebmail, free: true},
+		{in: " ISP ", want: emailchecker.ProviderISP, free: true},
+		{in: "privacy", want: emailchecker.ProviderPrivacy, free: true},
+		{in: "corporate", want: emailchecker.ProviderCorporate},
+		{in: "webmail", err: true},
+		{in: "", err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := emailchecker.ParseProviderType(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.free, got.IsFree())
+		})
+	}
+}
+
+func TestEmailProvider_Validate(t *testing.T) {
+	cases := []struct {
+		name     string
+		provider emailchecker.EmailProvider
+		valid    bool
+	}{
+		{name: "Valid", provider: emailchecker.EmailProvider{Domain: "corp.example", Type: emailchecker.ProviderCorporate, Author: "support"}, valid: true},
+		{name: "Unknown type", provider: emailchecker.EmailProvider{Domain: "corp.example", Type: "company", Author: "support"}},
+		{name: "Missing domain", provider: emailchecker.EmailProvider{Type: emailchecker.ProviderCorporate, Author: "support"}},
+		{name: "Missing author", provider: emailchecker.EmailProvider{Domain: "corp.example", Type: emailchecker.ProviderCorporate}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.provider.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, emailchecker.ErrInvalidProvider)
+			}
+		})
+	}
+}
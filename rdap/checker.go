@@ -0,0 +1,59 @@
+package rdap
+
+import (
+	"context"
+	"time"
+
+	"emailchecker"
+)
+
+const (
+	knownTTL   = 30 * 24 * time.Hour
+	unknownTTL = 24 * time.Hour
+)
+
+type repo interface {
+	GetDomainRegistration(ctx context.Context, domain string) (*emailchecker.DomainRegistration, error)
+	UpsertDomainRegistration(ctx context.Context, reg emailchecker.DomainRegistration) error
+}
+
+// Checker looks registration dates up over RDAP and caches them, including
+// the fact that a registry does not publish one.
+type Checker struct {
+	client *Client
+	repo   repo
+}
+
+func NewChecker(client *Client, repo repo) *Checker {
+	return &Checker{
+		client: client,
+		repo:   repo,
+	}
+}
+
+func (c *Checker) GetRegistrationDate(ctx context.Context, domain string) (*time.Time, error) {
+	cached, _ := c.repo.GetDomainRegistration(ctx, domain)
+	if cached != nil {
+		ttl := unknownTTL
+		if cached.RegisteredAt != nil {
+			ttl = knownTTL
+		}
+
+		if time.Since(cached.CheckedAt) < ttl {
+			return cached.RegisteredAt, nil
+		}
+	}
+
+	registeredAt, err := c.client.RegistrationDate(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = c.repo.UpsertDomainRegistration(ctx, emailchecker.DomainRegistration{
+		Domain:       domain,
+		RegisteredAt: registeredAt,
+		CheckedAt:    time.Now().UTC(),
+	})
+
+	return registeredAt, nil
+}
@@ -0,0 +1,76 @@
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const bootstrapEndpoint = "https://rdap.org/domain/"
+
+type Client struct {
+	httpClient *http.Client
+}
+
+func New(netClient *http.Client) *Client {
+	return &Client{
+		httpClient: netClient,
+	}
+}
+
+type domainResponse struct {
+	Events []event `json:"events"`
+}
+
+type event struct {
+	Action string    `json:"eventAction"`
+	Date   time.Time `json:"eventDate"`
+}
+
+// RegistrationDate returns when the domain was registered. It returns nil
+// without an error when the registry does not publish the date or does not
+// know the domain.
+func (c *Client) RegistrationDate(ctx context.Context, domain string) (*time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bootstrapEndpoint+url.PathEscape(domain), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create RDAP request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute RDAP request: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received non-200 status code from RDAP: %s", resp.Status)
+	}
+
+	var result domainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode RDAP response: %w", err)
+	}
+
+	for _, e := range result.Events {
+		if e.Action == "registration" && !e.Date.IsZero() {
+			registered := e.Date.UTC()
+
+			return &registered, nil
+		}
+	}
+
+	return nil, nil
+}
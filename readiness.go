@@ -0,0 +1,204 @@
+package emailchecker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type ReadinessState string
+
+const (
+	// ReadinessReady means the list has data downloaded within a few refresh
+	// intervals.
+	ReadinessReady ReadinessState = "ready"
+	// ReadinessStale means the list has data, but it is old or was only
+	// seeded from the embedded snapshot.
+	ReadinessStale ReadinessState = "stale"
+	// ReadinessLoading means the list is empty and waiting for its first download.
+	ReadinessLoading ReadinessState = "loading"
+	// ReadinessUnavailable means the list is empty and its last download failed.
+	ReadinessUnavailable ReadinessState = "unavailable"
+)
+
+type SourceReadiness struct {
+	ListVersion
+	State     ReadinessState `json:"state"`
+	LastError string         `json:"last_error,omitempty"`
+}
+
+// Readiness reports whether every domain list holds fresh data.
+type Readiness struct {
+	Ready   bool              `json:"ready"`
+	Sources []SourceReadiness `json:"sources"`
+}
+
+// versionsTTL is how long the list versions are cached. Lists can change
+// outside of the process, through the CLI, so the cache must expire even
+// when it is not invalidated.
+const versionsTTL = 30 * time.Second
+
+// readinessTracker caches the list versions, which are costly to count on
+// large lists, together with the outcome of the last refresh of each list.
+type readinessTracker struct {
+	mu         sync.Mutex
+	versions   []ListVersion
+	versionsAt time.Time
+	// generation counts the invalidations, so that versions read while a
+	// list changed are not cached.
+	generation int
+	// load is the read of the versions in flight, shared by every caller.
+	load       *versionsLoad
+	refreshing map[ListName]bool
+	errs       map[ListName]error
+}
+
+// versionsLoad is a read of the list versions; done is closed once versions
+// and err are set.
+type versionsLoad struct {
+	done     chan struct{}
+	versions []ListVersion
+	err      error
+}
+
+func newReadinessTracker() *readinessTracker {
+	return &readinessTracker{
+		refreshing: make(map[ListName]bool),
+		errs:       make(map[ListName]error),
+	}
+}
+
+func (t *readinessTracker) start(list ListName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refreshing[list] = true
+}
+
+// finish records the outcome of a refresh and drops the cached versions.
+func (t *readinessTracker) finish(list ListName, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.refreshing, list)
+
+	if err != nil {
+		t.errs[list] = err
+	} else {
+		delete(t.errs, list)
+	}
+
+	t.drop()
+}
+
+func (t *readinessTracker) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.drop()
+}
+
+// drop forgets the cached versions. A load in flight may have read them
+// before the change, so the next caller starts a new one.
+func (t *readinessTracker) drop() {
+	t.versions = nil
+	t.load = nil
+	t.generation++
+}
+
+// listVersions returns the cached versions, reading them again once they
+// expire. Concurrent callers share a single read of the store and wait for it
+// until their context is done.
+func (t *readinessTracker) listVersions(ctx context.Context, store ListStore) ([]ListVersion, error) {
+	t.mu.Lock()
+
+	if t.versions != nil && time.Since(t.versionsAt) < versionsTTL {
+		versions := t.versions
+		t.mu.Unlock()
+
+		return versions, nil
+	}
+
+	load := t.load
+	if load == nil {
+		load = &versionsLoad{done: make(chan struct{})}
+		t.load = load
+
+		// The read outlives the caller that started it, since others wait
+		// for it.
+		go t.loadVersions(context.WithoutCancel(ctx), load, store, t.generation)
+	}
+
+	t.mu.Unlock()
+
+	select {
+	case <-load.done:
+		return load.versions, load.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *readinessTracker) loadVersions(ctx context.Context, load *versionsLoad, store ListStore, generation int) {
+	defer close(load.done)
+
+	load.versions, load.err = store.ListVersions(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.load == load {
+		t.load = nil
+	}
+
+	if load.err == nil && t.generation == generation {
+		t.versions = load.versions
+		t.versionsAt = time.Now()
+	}
+}
+
+func (t *readinessTracker) readiness(ctx context.Context, store ListStore, maxAge func(ListName) time.Duration) (*Readiness, error) {
+	versions, err := t.listVersions(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ans := Readiness{
+		Ready:   true,
+		Sources: make([]SourceReadiness, 0, len(versions)),
+	}
+
+	for _, v := range versions {
+		s := SourceReadiness{ListVersion: v}
+
+		err := t.errs[v.List]
+		if err != nil {
+			s.LastError = err.Error()
+		}
+
+		switch {
+		case v.Rows == 0 && err != nil && !t.refreshing[v.List]:
+			s.State = ReadinessUnavailable
+		case v.Rows == 0:
+			s.State = ReadinessLoading
+		case !v.List.downloaded():
+			// Imported lists are never due for a refresh.
+			s.State = ReadinessReady
+		case v.RefreshedAt == nil || time.Since(*v.RefreshedAt) > maxAge(v.List):
+			s.State = ReadinessStale
+		default:
+			s.State = ReadinessReady
+		}
+
+		if s.State != ReadinessReady {
+			ans.Ready = false
+		}
+
+		ans.Sources = append(ans.Sources, s)
+	}
+
+	return &ans, nil
+}
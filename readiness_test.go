@@ -0,0 +1,138 @@
+package emailchecker_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+// versionStore counts the reads of the list versions, which block until gate
+// is closed.
+type versionStore struct {
+	reads   atomic.Int32
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func newVersionStore() *versionStore {
+	return &versionStore{
+		started: make(chan struct{}, 100),
+		gate:    make(chan struct{}),
+	}
+}
+
+func (v *versionStore) listVersions(context.Context) ([]emailchecker.ListVersion, error) {
+	v.reads.Add(1)
+	v.started <- struct{}{}
+	<-v.gate
+
+	now := time.Now()
+
+	return []emailchecker.ListVersion{{List: emailchecker.ListTop, Rows: 10, RefreshedAt: &now}}, nil
+}
+
+func TestEmailChecker_ReadinessSharesListVersionsRead(t *testing.T) {
+	store := newVersionStore()
+	c := newChecker(t, &stub{versions: store.listVersions})
+
+	var wg sync.WaitGroup
+
+	results := make([]*emailchecker.Readiness, 20)
+	errs := make([]error, len(results))
+
+	for i := range results {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i], errs[i] = c.Readiness(context.Background())
+		}()
+	}
+
+	<-store.started
+	close(store.gate)
+	wg.Wait()
+
+	for i := range results {
+		require.NoError(t, errs[i])
+		assert.True(t, results[i].Ready)
+	}
+
+	assert.Equal(t, int32(1), store.reads.Load())
+
+	// The versions are now cached.
+	_, err := c.Readiness(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), store.reads.Load())
+}
+
+func TestEmailChecker_ReadinessWaiterGivesUp(t *testing.T) {
+	store := newVersionStore()
+	c := newChecker(t, &stub{versions: store.listVersions})
+
+	// The caller that starts the read gives up, the read goes on for the
+	// others.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Readiness(ctx)
+		errc <- err
+	}()
+
+	<-store.started
+	cancel()
+	require.ErrorIs(t, <-errc, context.Canceled)
+
+	done := make(chan *emailchecker.Readiness, 1)
+	go func() {
+		r, err := c.Readiness(context.Background())
+		assert.NoError(t, err)
+		done <- r
+	}()
+
+	close(store.gate)
+
+	r := <-done
+	require.NotNil(t, r)
+	assert.True(t, r.Ready)
+	assert.Equal(t, int32(1), store.reads.Load())
+}
+
+func TestEmailChecker_ReadinessRereadsAfterInvalidation(t *testing.T) {
+	store := newVersionStore()
+	c := newChecker(t, &stub{versions: store.listVersions})
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Readiness(context.Background())
+		errc <- err
+	}()
+
+	<-store.started
+
+	// The list changes while its versions are read, so the read in flight
+	// is stale and a new caller starts another one.
+	_, err := c.RollbackList(context.Background(), emailchecker.ListTop)
+	require.NoError(t, err)
+
+	go func() {
+		_, err := c.Readiness(context.Background())
+		errc <- err
+	}()
+
+	<-store.started
+	close(store.gate)
+
+	require.NoError(t, <-errc)
+	require.NoError(t, <-errc)
+	assert.Equal(t, int32(2), store.reads.Load())
+}
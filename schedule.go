@@ -0,0 +1,89 @@
+package emailchecker
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"emailchecker/pkg/log"
+)
+
+// staleAfterIntervals is how many refresh intervals a list counts as fresh
+// after its last download. It tolerates a few failed refreshes.
+const staleAfterIntervals = 4
+
+// RefreshSchedule tells how often a domain list is refreshed.
+type RefreshSchedule struct {
+	// Interval is the age at which the data of the list is due for a refresh.
+	Interval time.Duration
+	// Jitter is the upper bound of a random delay added to every wait, so
+	// that instances started together do not hit the sources at once.
+	Jitter time.Duration
+}
+
+// DefaultRefreshSchedule is used for lists without a configured schedule.
+var DefaultRefreshSchedule = RefreshSchedule{
+	Interval: 12 * time.Hour,
+	Jitter:   time.Hour,
+}
+
+func (s RefreshSchedule) Validate() error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("refresh interval must be positive, got %s", s.Interval)
+	}
+
+	if s.Jitter < 0 {
+		return fmt.Errorf("refresh jitter cannot be negative, got %s", s.Jitter)
+	}
+
+	return nil
+}
+
+// wait returns the delay until the next refresh attempt.
+func (s RefreshSchedule) wait() time.Duration {
+	if s.Jitter <= 0 {
+		return s.Interval
+	}
+
+	return s.Interval + rand.N(s.Jitter)
+}
+
+// maxAge is the age after which the data of the list counts as stale.
+func (s RefreshSchedule) maxAge() time.Duration {
+	return staleAfterIntervals * s.Interval
+}
+
+// RefreshRecorder keeps the outcome of list refreshes for the sources report.
+type RefreshRecorder interface {
+	RecordRefresh(ctx context.Context, list ListName, refreshErr error) error
+}
+
+// RecordRefresh runs refresh and records its outcome unless it was skipped.
+// The outcome is recorded even when ctx is cancelled, which is often why
+// the refresh failed.
+func RecordRefresh(
+	ctx context.Context,
+	recorder RefreshRecorder,
+	list ListName,
+	refresh func(context.Context) (*ListRefreshResult, error),
+) (*ListRefreshResult, error) {
+	result, err := refresh(ctx)
+	if result != nil && result.Skipped {
+		return result, nil
+	}
+
+	if rerr := recorder.RecordRefresh(context.WithoutCancel(ctx), list, err); rerr != nil {
+		log.Warn(ctx, "Could not record list refresh", "list", list, "error", rerr.Error())
+	}
+
+	return result, err
+}
+
+// UpdateOptions select the lists UpdateDB refreshes.
+type UpdateOptions struct {
+	// Lists limits the update to these lists. Empty updates every list.
+	Lists []ListName
+	// Force refreshes the lists even when their data is not due.
+	Force bool
+}
@@ -0,0 +1,270 @@
+package emailchecker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+// recorder keeps the refreshes it records along with whether their context
+// was already cancelled.
+type recorder struct {
+	errs      []error
+	cancelled []bool
+}
+
+func (r *recorder) RecordRefresh(ctx context.Context, _ emailchecker.ListName, refreshErr error) error {
+	r.errs = append(r.errs, refreshErr)
+	r.cancelled = append(r.cancelled, ctx.Err() != nil)
+
+	return nil
+}
+
+func TestRecordRefresh(t *testing.T) {
+	t.Run("Skipped refresh is not recorded", func(t *testing.T) {
+		rec := &recorder{}
+
+		result, err := emailchecker.RecordRefresh(context.Background(), rec, emailchecker.ListTop,
+			func(context.Context) (*emailchecker.ListRefreshResult, error) {
+				return &emailchecker.ListRefreshResult{List: emailchecker.ListTop, Skipped: true}, nil
+			})
+		require.NoError(t, err)
+		assert.True(t, result.Skipped)
+		assert.Empty(t, rec.errs)
+	})
+
+	t.Run("Cancelled refresh is recorded", func(t *testing.T) {
+		rec := &recorder{}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		_, err := emailchecker.RecordRefresh(ctx, rec, emailchecker.ListTop,
+			func(ctx context.Context) (*emailchecker.ListRefreshResult, error) {
+				cancel()
+				return nil, ctx.Err()
+			})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []error{context.Canceled}, rec.errs)
+		assert.Equal(t, []bool{false}, rec.cancelled)
+	})
+}
+
+func TestRefreshSchedule_Validate(t *testing.T) {
+	cases := []struct {
+		name     string
+		schedule emailchecker.RefreshSchedule
+		valid    bool
+	}{
+		{name: "Default", schedule: emailchecker.DefaultRefreshSchedule, valid: true},
+		{name: "Without jitter", schedule: emailchecker.RefreshSchedule{Interval: time.Hour}, valid: true},
+		{name: "Zero interval", schedule: emailchecker.RefreshSchedule{Jitter: time.Hour}},
+		{name: "Negative interval", schedule: emailchecker.RefreshSchedule{Interval: -time.Hour}},
+		{name: "Negative jitter", schedule: emailchecker.RefreshSchedule{Interval: time.Hour, Jitter: -time.Minute}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.schedule.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+
+			// New refuses the same schedules.
+			_, err = emailchecker.New(config(&stub{}, map[emailchecker.ListName]emailchecker.RefreshSchedule{
+				emailchecker.ListTop: tc.schedule,
+			}))
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, emailchecker.ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestNew_ParkedSchedule(t *testing.T) {
+	// The parked list is imported, so it has no refresh schedule.
+	_, err := emailchecker.New(config(&stub{}, map[emailchecker.ListName]emailchecker.RefreshSchedule{
+		emailchecker.ListParked: emailchecker.DefaultRefreshSchedule,
+	}))
+	require.ErrorIs(t, err, emailchecker.ErrInvalidConfig)
+}
+
+func TestRefreshSchedule_Jitter(t *testing.T) {
+	t.Run("Without jitter", func(t *testing.T) {
+		s := emailchecker.RefreshSchedule{Interval: time.Hour}
+		assert.Equal(t, time.Hour, s.Wait())
+	})
+
+	t.Run("With jitter", func(t *testing.T) {
+		s := emailchecker.RefreshSchedule{Interval: time.Hour, Jitter: time.Minute}
+
+		seen := make(map[time.Duration]bool)
+		for range 1000 {
+			wait := s.Wait()
+			assert.GreaterOrEqual(t, wait, time.Hour)
+			assert.Less(t, wait, time.Hour+time.Minute)
+
+			seen[wait] = true
+		}
+
+		assert.Greater(t, len(seen), 1, "the delay is random")
+	})
+}
+
+// updates records the maxAge each list was refreshed with.
+type updates struct {
+	mu     sync.Mutex
+	maxAge map[emailchecker.ListName][]time.Duration
+	fail   map[emailchecker.ListName]error
+}
+
+func (u *updates) update(list emailchecker.ListName, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.maxAge == nil {
+		u.maxAge = make(map[emailchecker.ListName][]time.Duration)
+	}
+
+	u.maxAge[list] = append(u.maxAge[list], maxAge)
+
+	if err := u.fail[list]; err != nil {
+		return nil, err
+	}
+
+	return &emailchecker.ListRefreshResult{List: list}, nil
+}
+
+func (u *updates) count(list emailchecker.ListName) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return len(u.maxAge[list])
+}
+
+func TestEmailChecker_UpdateDB(t *testing.T) {
+	schedules := map[emailchecker.ListName]emailchecker.RefreshSchedule{
+		emailchecker.ListDisposable: {Interval: time.Hour},
+		emailchecker.ListTop:        {Interval: 7 * 24 * time.Hour},
+	}
+
+	cases := []struct {
+		name    string
+		opts    emailchecker.UpdateOptions
+		fail    map[emailchecker.ListName]error
+		want    map[emailchecker.ListName][]time.Duration
+		results []emailchecker.ListName
+		err     string
+	}{
+		{
+			name: "Every list on its schedule",
+			want: map[emailchecker.ListName][]time.Duration{
+				emailchecker.ListDisposable:  {time.Hour},
+				emailchecker.ListTop:         {7 * 24 * time.Hour},
+				emailchecker.ListEducational: {emailchecker.DefaultRefreshSchedule.Interval},
+			},
+			results: []emailchecker.ListName{emailchecker.ListEducational, emailchecker.ListTop, emailchecker.ListDisposable},
+		},
+		{
+			name: "Forced",
+			opts: emailchecker.UpdateOptions{Force: true},
+			want: map[emailchecker.ListName][]time.Duration{
+				emailchecker.ListDisposable:  {0},
+				emailchecker.ListTop:         {0},
+				emailchecker.ListEducational: {0},
+			},
+			results: []emailchecker.ListName{emailchecker.ListEducational, emailchecker.ListTop, emailchecker.ListDisposable},
+		},
+		{
+			name:    "Only some lists",
+			opts:    emailchecker.UpdateOptions{Lists: []emailchecker.ListName{emailchecker.ListTop}, Force: true},
+			want:    map[emailchecker.ListName][]time.Duration{emailchecker.ListTop: {0}},
+			results: []emailchecker.ListName{emailchecker.ListTop},
+		},
+		{
+			name: "Unknown list",
+			opts: emailchecker.UpdateOptions{Lists: []emailchecker.ListName{emailchecker.ListTop, "spam"}},
+			err:  "spam",
+		},
+		{
+			name: "Parked list",
+			opts: emailchecker.UpdateOptions{Lists: []emailchecker.ListName{emailchecker.ListParked}},
+			err:  "not downloaded",
+		},
+		{
+			name: "Failing list",
+			fail: map[emailchecker.ListName]error{emailchecker.ListTop: errors.New("connection refused")},
+			want: map[emailchecker.ListName][]time.Duration{
+				emailchecker.ListDisposable:  {time.Hour},
+				emailchecker.ListTop:         {7 * 24 * time.Hour},
+				emailchecker.ListEducational: {emailchecker.DefaultRefreshSchedule.Interval},
+			},
+			results: []emailchecker.ListName{emailchecker.ListEducational, emailchecker.ListDisposable},
+			err:     "top: connection refused",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &updates{fail: tc.fail}
+			c := newScheduledChecker(t, &stub{update: u.update}, schedules)
+
+			results, err := c.UpdateDB(context.Background(), tc.opts)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var lists []emailchecker.ListName
+			for _, r := range results {
+				lists = append(lists, r.List)
+			}
+
+			assert.Equal(t, tc.results, lists)
+			assert.Equal(t, tc.want, u.maxAge)
+		})
+	}
+}
+
+func TestEmailChecker_PeriodicUpdate(t *testing.T) {
+	u := &updates{
+		fail: map[emailchecker.ListName]error{emailchecker.ListDisposable: errors.New("connection refused")},
+	}
+
+	c := newScheduledChecker(t, &stub{update: u.update}, map[emailchecker.ListName]emailchecker.RefreshSchedule{
+		emailchecker.ListDisposable:  {Interval: 10 * time.Millisecond},
+		emailchecker.ListTop:         {Interval: time.Hour},
+		emailchecker.ListEducational: {Interval: time.Hour},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		c.PeriodicUpdate(ctx)
+	}()
+
+	// A failing list is retried on its own schedule while the others wait
+	// for theirs.
+	require.Eventually(t, func() bool {
+		return u.count(emailchecker.ListDisposable) >= 3
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+
+	assert.Equal(t, 1, u.count(emailchecker.ListTop))
+	assert.Equal(t, 1, u.count(emailchecker.ListEducational))
+}
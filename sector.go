@@ -0,0 +1,32 @@
+package emailchecker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sector is the public sector of a domain.
+type Sector string
+
+const (
+	SectorGovernment        Sector = "government"
+	SectorMilitary          Sector = "military"
+	SectorIntergovernmental Sector = "intergovernmental"
+	SectorNonProfit         Sector = "non_profit"
+)
+
+func ParseSector(s string) (Sector, error) {
+	switch sector := Sector(strings.ToLower(strings.TrimSpace(s))); sector {
+	case SectorGovernment, SectorMilitary, SectorIntergovernmental, SectorNonProfit:
+		return sector, nil
+	default:
+		return "", fmt.Errorf("unknown sector %q: expected government, military, intergovernmental or non_profit", s)
+	}
+}
+
+type SectorCheckResult struct {
+	// Sector is empty when the domain is not public sector.
+	Sector Sector `json:"sector,omitempty"`
+	// MatchedRule is the rule pattern that classified the domain.
+	MatchedRule string `json:"matched_rule,omitempty"`
+}
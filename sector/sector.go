@@ -0,0 +1,182 @@
+package sector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"emailchecker"
+)
+
+//go:embed rules.txt
+var defaultRules []byte
+
+// countryCode is the pattern label that matches any two letter country code.
+// A pattern with it only matches public suffixes, so that gov.{cc} matches
+// gov.uk but not the registrable gov.tv.
+const countryCode = "{cc}"
+
+// Rule classifies a domain suffix.
+type Rule struct {
+	Pattern string
+	Sector  emailchecker.Sector
+}
+
+// DefaultRules returns the rules shipped with the binary.
+func DefaultRules() []Rule {
+	rules, err := ParseRules(bytes.NewReader(defaultRules))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded sector rules: %v", err))
+	}
+
+	return rules
+}
+
+// ParseRules reads rules in the format of the embedded rules.txt.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var ans []Rule
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid sector rule on line %d: %q", n, line)
+		}
+
+		sector, err := emailchecker.ParseSector(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid sector rule on line %d: %w", n, err)
+		}
+
+		ans = append(ans, Rule{Pattern: strings.ToLower(fields[0]), Sector: sector})
+	}
+
+	return ans, scanner.Err()
+}
+
+// ReadRulesFile reads the rules of a local file.
+func ReadRulesFile(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	rules, err := ParseRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+type rule struct {
+	Rule
+	labels []string
+	// wildcards counts the country code labels.
+	wildcards int
+}
+
+// moreSpecific reports whether r wins over o when both match.
+func (r *rule) moreSpecific(o *rule) bool {
+	if len(r.labels) != len(o.labels) {
+		return len(r.labels) > len(o.labels)
+	}
+
+	return r.wildcards < o.wildcards
+}
+
+// Classifier assigns the public sector of a domain from suffix rules.
+type Classifier struct {
+	rules []rule
+}
+
+// New creates a classifier. Later rules replace earlier rules with the same
+// pattern, so extra rules can be appended to DefaultRules.
+func New(rules []Rule) *Classifier {
+	index := make(map[string]int, len(rules))
+
+	var ans Classifier
+	for _, r := range rules {
+		parsed := rule{Rule: r, labels: strings.Split(r.Pattern, ".")}
+		parsed.wildcards = strings.Count(r.Pattern, countryCode)
+
+		if i, ok := index[r.Pattern]; ok {
+			ans.rules[i] = parsed
+			continue
+		}
+
+		index[r.Pattern] = len(ans.rules)
+		ans.rules = append(ans.rules, parsed)
+	}
+
+	return &ans
+}
+
+func (c *Classifier) ClassifySector(_ context.Context, domain emailchecker.Domain) (emailchecker.SectorCheckResult, error) {
+	labels := strings.Split(domain.Name, ".")
+
+	var best *rule
+	for i := range c.rules {
+		r := &c.rules[i]
+		if !r.match(labels, domain.PublicSuffix) {
+			continue
+		}
+
+		if best == nil || r.moreSpecific(best) {
+			best = r
+		}
+	}
+
+	if best == nil {
+		return emailchecker.SectorCheckResult{}, nil
+	}
+
+	return emailchecker.SectorCheckResult{
+		Sector:      best.Sector,
+		MatchedRule: best.Pattern,
+	}, nil
+}
+
+// match reports whether the pattern is a suffix of the domain labels. The
+// suffix matched by a country code pattern must lie within publicSuffix.
+func (r *rule) match(labels []string, publicSuffix string) bool {
+	if len(r.labels) > len(labels) {
+		return false
+	}
+
+	offset := len(labels) - len(r.labels)
+	for i, pattern := range r.labels {
+		label := labels[offset+i]
+
+		if pattern == countryCode {
+			if len(label) != 2 {
+				return false
+			}
+
+			continue
+		}
+
+		if pattern != label {
+			return false
+		}
+	}
+
+	if r.wildcards == 0 {
+		return true
+	}
+
+	matched := strings.Join(labels[offset:], ".")
+
+	return publicSuffix == matched || strings.HasSuffix(publicSuffix, "."+matched)
+}
@@ -0,0 +1,72 @@
+package sector_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/sector"
+)
+
+func TestClassifier_ClassifySector(t *testing.T) {
+	c := sector.New(sector.DefaultRules())
+
+	cases := []struct {
+		name   string
+		domain string
+		sector emailchecker.Sector
+		rule   string
+	}{
+		{name: "US government", domain: "irs.gov", sector: emailchecker.SectorGovernment, rule: "gov"},
+		{name: "National government", domain: "hmrc.gov.uk", sector: emailchecker.SectorGovernment, rule: "gov.{cc}"},
+		{name: "French government", domain: "impots.gouv.fr", sector: emailchecker.SectorGovernment, rule: "gouv.{cc}"},
+		{name: "Canadian government", domain: "cra-arc.gc.ca", sector: emailchecker.SectorGovernment, rule: "gc.ca"},
+		{name: "Military", domain: "army.mil", sector: emailchecker.SectorMilitary, rule: "mil"},
+		{name: "Longest pattern wins", domain: "mail.defence.gov.au", sector: emailchecker.SectorMilitary, rule: "defence.gov.au"},
+		{name: "Intergovernmental", domain: "who.int", sector: emailchecker.SectorIntergovernmental, rule: "int"},
+		{name: "Non-profit", domain: "example.ngo", sector: emailchecker.SectorNonProfit, rule: "ngo"},
+		{name: "Public suffix within a country code", domain: "bogota.gov.co", sector: emailchecker.SectorGovernment, rule: "gov.{cc}"},
+		{name: "Spanish government", domain: "hacienda.gob.es", sector: emailchecker.SectorGovernment, rule: "gob.{cc}"},
+		{name: "Japanese government", domain: "mext.go.jp", sector: emailchecker.SectorGovernment, rule: "go.{cc}"},
+		{name: "National military", domain: "eer.mil.pl", sector: emailchecker.SectorMilitary, rule: "mil.{cc}"},
+		{name: "Country code is two letters", domain: "gov.example", sector: "", rule: ""},
+		{name: "Registrable domain", domain: "go.to", sector: "", rule: ""},
+		{name: "Subdomain of a registrable domain", domain: "mail.go.to", sector: "", rule: ""},
+		{name: "Registrable gob", domain: "gob.me", sector: "", rule: ""},
+		{name: "Registrable gouv", domain: "gouv.co", sector: "", rule: ""},
+		{name: "Subdomain below a country code", domain: "x.go.co", sector: "", rule: ""},
+		{name: "Registrable go", domain: "go.tv", sector: "", rule: ""},
+		{name: "Registrable gov", domain: "gov.tv", sector: "", rule: ""},
+		{name: "Registrable mil", domain: "x.mil.tv", sector: "", rule: ""},
+		{name: "Label boundary", domain: "notgov.com", sector: "", rule: ""},
+		{name: "Private domain", domain: "gmail.com", sector: "", rule: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := c.ClassifySector(context.Background(), emailchecker.NewDomain(tc.domain))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.sector, res.Sector)
+			assert.Equal(t, tc.rule, res.MatchedRule)
+		})
+	}
+}
+
+func TestNew_LaterRulesReplace(t *testing.T) {
+	extra, err := sector.ParseRules(strings.NewReader("# local\ngov.uk non_profit\n"))
+	require.NoError(t, err)
+
+	c := sector.New(append(sector.DefaultRules(), extra...))
+
+	res, err := c.ClassifySector(context.Background(), emailchecker.NewDomain("example.gov.uk"))
+	require.NoError(t, err)
+	assert.Equal(t, emailchecker.SectorNonProfit, res.Sector)
+
+	_, err = sector.ParseRules(strings.NewReader("example.org charity\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package sqlite_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_DeleteDNSRecords(t *testing.T) {
+	cases := []struct {
+		name              string
+		domain            string
+		includeSubdomains bool
+		deleted           []string
+	}{
+		{name: "Domain", domain: "Example.COM.", deleted: []string{"example.com"}},
+		{name: "Domain and subdomains", domain: "example.com", includeSubdomains: true, deleted: []string{"example.com", "mail.example.com"}},
+		{name: "Unknown domain", domain: "example.org"},
+		{name: "Wildcards are literal", domain: "exa_ple.com", includeSubdomains: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newRepository(t)
+			ctx := context.Background()
+
+			domains := []string{"example.com", "mail.example.com", "notexample.com"}
+			for _, domain := range domains {
+				require.NoError(t, r.UpsertDNSRecord(ctx, domain, []byte(`{}`)))
+			}
+
+			n, err := r.DeleteDNSRecords(ctx, tc.domain, tc.includeSubdomains)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tc.deleted)), n)
+
+			for _, domain := range domains {
+				rec, err := r.GetDNSRecord(ctx, domain)
+				require.NoError(t, err)
+				assert.Equal(t, !slices.Contains(tc.deleted, domain), rec != nil, domain)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+func TestRepository_EducationalInstitution(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	mit := emailchecker.EducationalInstitution{
+		Domain:       "mit.edu",
+		Name:         "Massachusetts Institute of Technology",
+		Country:      "United States",
+		AlphaTwoCode: "US",
+		WebPages:     []string{"https://web.mit.edu/", "https://mit.edu/"},
+	}
+	csail := emailchecker.EducationalInstitution{
+		Domain:       "csail.mit.edu",
+		Name:         "MIT CSAIL",
+		Country:      "United States",
+		AlphaTwoCode: "US",
+		WebPages:     []string{"https://www.csail.mit.edu/"},
+	}
+
+	_, err := r.UpdateEducationalDomains(ctx, []emailchecker.EducationalInstitution{mit, csail}, nil)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		domains []string
+		want    *emailchecker.EducationalInstitution
+	}{
+		{name: "Not listed", domains: []string{"example.com"}},
+		{name: "Listed", domains: []string{"mit.edu"}, want: &mit},
+		{name: "Subdomain", domains: []string{"math.mit.edu", "mit.edu"}, want: &mit},
+		{name: "Most specific domain", domains: []string{"csail.mit.edu", "mit.edu"}, want: &csail},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.GetEducationalInstitution(ctx, tc.domains)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	// A renamed institution is updated in place.
+	renamed := mit
+	renamed.Name = "MIT"
+
+	result, err := r.UpdateEducationalDomains(ctx, []emailchecker.EducationalInstitution{renamed, csail}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Updated)
+	assert.Zero(t, result.Added)
+	assert.Zero(t, result.Removed)
+
+	got, err := r.GetEducationalInstitution(ctx, []string{"mit.edu"})
+	require.NoError(t, err)
+	assert.Equal(t, &renamed, got)
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"emailchecker"
+)
+
+const importKeyPrefix = "import:"
+
+// ImportList stores the data of a list loaded from a local file, the same
+// way as a download: the change is applied as a diff after a snapshot of the
+// list is taken. No guard applies, as the operator chose the file. A replaced
+// list is downloaded in full by its next refresh, which would otherwise be
+// answered not modified and keep the imported data.
+func (r *Repository) ImportList(ctx context.Context, imp *emailchecker.ListImport, data emailchecker.ListData) (*emailchecker.ListRefreshResult, error) {
+	var rows []domainRow
+
+	switch imp.List {
+	case emailchecker.ListDisposable:
+		rows = disposableRows(data.Disposable)
+	case emailchecker.ListTop:
+		rows = topDomainRows(data.Top)
+	case emailchecker.ListEducational:
+		rows = eduRows(data.Educational)
+	case emailchecker.ListParked:
+		rows = parkedRows(data.Parked)
+	default:
+		return nil, fmt.Errorf("unknown list %q", imp.List)
+	}
+
+	return r.storeDomains(ctx, imp.List, rows, storeOptions{
+		merge:           imp.Mode == emailchecker.ImportMerge,
+		source:          emailchecker.ListDataImported,
+		provenance:      imp,
+		resetValidators: imp.Mode == emailchecker.ImportReplace,
+	})
+}
+
+// LastImport returns the last import of a list, nil when it was never
+// imported.
+func (r *Repository) LastImport(ctx context.Context, list emailchecker.ListName) (*emailchecker.ListImport, error) {
+	var raw string
+
+	query := "SELECT value FROM app_metadata WHERE key = ?"
+
+	err := r.readDB.QueryRowContext(ctx, query, importKeyPrefix+string(list)).Scan(&raw)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("could not query import of '%s': %w", list, err)
+	}
+
+	var imp emailchecker.ListImport
+	if err := json.Unmarshal([]byte(raw), &imp); err != nil {
+		return nil, fmt.Errorf("could not decode import of '%s': %w", list, err)
+	}
+
+	return &imp, nil
+}
+
+func (r *Repository) setImport(ctx context.Context, tx *sql.Tx, imp *emailchecker.ListImport) error {
+	raw, err := json.Marshal(imp)
+	if err != nil {
+		return fmt.Errorf("could not encode import: %w", err)
+	}
+
+	query := `
+	INSERT INTO app_metadata (key, value)
+	VALUES (?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value;
+	`
+
+	if _, err := tx.ExecContext(ctx, query, importKeyPrefix+string(imp.List), string(raw)); err != nil {
+		return fmt.Errorf("could not store import of '%s': %w", imp.List, err)
+	}
+
+	return nil
+}
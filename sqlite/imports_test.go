@@ -0,0 +1,55 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+)
+
+func TestRepository_ImportListValidators(t *testing.T) {
+	cases := []struct {
+		mode emailchecker.ImportMode
+		kept bool
+	}{
+		{mode: emailchecker.ImportReplace},
+		{mode: emailchecker.ImportMerge, kept: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			r := newRepository(t)
+			ctx := context.Background()
+
+			_, err := r.UpdateDomains(ctx, disposableDomains("mailinator.com"), nil)
+			require.NoError(t, err)
+
+			validators := httpext.Validators{ETag: `"v1"`}
+			store := r.Validators(emailchecker.ListDisposable)
+			require.NoError(t, store.SetValidators(ctx, "https://lists.example/disposable.txt", validators))
+
+			_, err = r.ImportList(ctx, &emailchecker.ListImport{
+				List:   emailchecker.ListDisposable,
+				File:   "/tmp/disposable.txt",
+				Format: emailchecker.ImportText,
+				Mode:   tc.mode,
+			}, emailchecker.ListData{Disposable: disposableDomains("local.example")})
+			require.NoError(t, err)
+
+			// A replaced list no longer matches the download, so the next
+			// refresh must not be answered not modified.
+			v, err := store.GetValidators(ctx, "https://lists.example/disposable.txt")
+			require.NoError(t, err)
+
+			if tc.kept {
+				assert.Equal(t, validators, v)
+			} else {
+				assert.True(t, v.IsZero())
+			}
+		})
+	}
+}
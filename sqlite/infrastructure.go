@@ -0,0 +1,241 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"emailchecker"
+)
+
+// RecordInfrastructure stores the infrastructure a domain was observed with.
+// disposable tells whether the domain is a known disposable domain or a
+// trusted clean one.
+func (r *Repository) RecordInfrastructure(ctx context.Context, domain string, disposable bool, features []emailchecker.InfraFeature) error {
+	if len(features) == 0 {
+		return nil
+	}
+
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO infrastructure_observations (kind, value, domain, disposable, seen_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(kind, value, domain) DO UPDATE SET
+		disposable = excluded.disposable,
+		seen_at = excluded.seen_at;
+	`)
+	if err != nil {
+		return fmt.Errorf("could not prepare infrastructure insert: %w", err)
+	}
+	defer stmt.Close() //nolint:errcheck
+
+	now := time.Now().UTC()
+	for _, f := range features {
+		if _, err := stmt.ExecContext(ctx, f.Kind, f.Value, domain, disposable, now); err != nil {
+			return fmt.Errorf("could not record infrastructure of '%s': %w", domain, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetInfrastructureStats counts, for each feature, the disposable and clean
+// domains other than excludeDomain that were observed with it.
+func (r *Repository) GetInfrastructureStats(ctx context.Context, excludeDomain string, features []emailchecker.InfraFeature) ([]emailchecker.InfraFeatureStats, error) {
+	if len(features) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(features)*2+1)
+	args = append(args, excludeDomain)
+
+	for _, f := range features {
+		args = append(args, f.Kind, f.Value)
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?),", len(features)), ",")
+	query := fmt.Sprintf(`
+	SELECT kind, value, SUM(disposable), SUM(1 - disposable)
+	FROM infrastructure_observations
+	WHERE domain != ? AND (kind, value) IN (VALUES %s)
+	GROUP BY kind, value`, values)
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query infrastructure: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var ans []emailchecker.InfraFeatureStats
+	for rows.Next() {
+		var s emailchecker.InfraFeatureStats
+		if err := rows.Scan(&s.Kind, &s.Value, &s.DisposableDomains, &s.CleanDomains); err != nil {
+			return nil, fmt.Errorf("could not scan infrastructure: %w", err)
+		}
+
+		ans = append(ans, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query infrastructure: %w", err)
+	}
+
+	return ans, nil
+}
+
+// UpsertCandidate records a candidate or refreshes its score. The review
+// status of an existing candidate is kept.
+func (r *Repository) UpsertCandidate(ctx context.Context, c emailchecker.DisposableCandidate) error {
+	evidence, err := json.Marshal(c.Evidence)
+	if err != nil {
+		return fmt.Errorf("could not encode evidence: %w", err)
+	}
+
+	query := `
+	INSERT INTO disposable_candidates (domain, confidence, evidence, status, first_seen_at, last_seen_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(domain) DO UPDATE SET
+		confidence = excluded.confidence,
+		evidence = excluded.evidence,
+		last_seen_at = excluded.last_seen_at;
+	`
+
+	_, err = r.writeDB.ExecContext(ctx, query,
+		c.Domain, c.Confidence, evidence, emailchecker.CandidatePending, c.LastSeenAt.UTC(), c.LastSeenAt.UTC())
+	if err != nil {
+		return fmt.Errorf("could not upsert candidate '%s': %w", c.Domain, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetCandidate(ctx context.Context, domain string) (*emailchecker.DisposableCandidate, error) {
+	query := candidateSelect + " WHERE domain = ?"
+
+	rows, err := r.readDB.QueryContext(ctx, query, normalizeDomain(domain))
+	if err != nil {
+		return nil, fmt.Errorf("could not get candidate '%s': %w", domain, err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	return scanCandidate(rows)
+}
+
+// ListCandidates returns candidates with the given status, or all of them
+// when status is empty, highest confidence first.
+func (r *Repository) ListCandidates(ctx context.Context, status emailchecker.CandidateStatus) ([]emailchecker.DisposableCandidate, error) {
+	query := candidateSelect
+
+	var args []any
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+
+	query += " ORDER BY confidence DESC, domain"
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list candidates: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	ans := []emailchecker.DisposableCandidate{}
+	for rows.Next() {
+		c, err := scanCandidate(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list candidates: %w", err)
+	}
+
+	return ans, nil
+}
+
+func (r *Repository) SetCandidateStatus(ctx context.Context, domain string, status emailchecker.CandidateStatus, reviewer string) (bool, error) {
+	query := "UPDATE disposable_candidates SET status = ?, reviewed_by = ?, reviewed_at = ? WHERE domain = ?"
+
+	res, err := r.writeDB.ExecContext(ctx, query, status, reviewer, time.Now().UTC(), normalizeDomain(domain))
+	if err != nil {
+		return false, fmt.Errorf("could not update candidate '%s': %w", domain, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not update candidate '%s': %w", domain, err)
+	}
+
+	return n > 0, nil
+}
+
+const candidateSelect = `SELECT domain, confidence, evidence, status, first_seen_at, last_seen_at, reviewed_by, reviewed_at
+	FROM disposable_candidates`
+
+func scanCandidate(rows *sql.Rows) (*emailchecker.DisposableCandidate, error) {
+	var (
+		c          emailchecker.DisposableCandidate
+		evidence   []byte
+		reviewedBy sql.NullString
+		reviewedAt sql.NullTime
+	)
+
+	err := rows.Scan(&c.Domain, &c.Confidence, &evidence, &c.Status, &c.FirstSeenAt, &c.LastSeenAt, &reviewedBy, &reviewedAt)
+	if err != nil {
+		return nil, fmt.Errorf("could not scan candidate: %w", err)
+	}
+
+	if err := json.Unmarshal(evidence, &c.Evidence); err != nil {
+		return nil, fmt.Errorf("could not decode evidence of '%s': %w", c.Domain, err)
+	}
+
+	c.ReviewedBy = reviewedBy.String
+	if reviewedAt.Valid {
+		c.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &c, nil
+}
+
+func (r *Repository) createInfrastructureTables(ctx context.Context, tx *sql.Tx) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS infrastructure_observations (
+		kind TEXT NOT NULL,
+		value TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		disposable INTEGER NOT NULL,
+		seen_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (kind, value, domain)
+	);
+	CREATE TABLE IF NOT EXISTS disposable_candidates (
+		domain TEXT PRIMARY KEY NOT NULL,
+		confidence REAL NOT NULL,
+		evidence BLOB NOT NULL,
+		status TEXT NOT NULL,
+		first_seen_at TIMESTAMP NOT NULL,
+		last_seen_at TIMESTAMP NOT NULL,
+		reviewed_by TEXT,
+		reviewed_at TIMESTAMP
+	);`
+	_, err := tx.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("could not create infrastructure tables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/sqlite"
+)
+
+// newRepository opens a new database whose lists start empty.
+func newRepository(t *testing.T, opts ...sqlite.Option) *sqlite.Repository {
+	t.Helper()
+
+	r, err := sqlite.New(filepath.Join(t.TempDir(), "checker.db"), append([]sqlite.Option{sqlite.WithoutSeed()}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func TestRepository_InfrastructureStats(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	host := emailchecker.InfraFeature{Kind: emailchecker.InfraMXHost, Value: "mx.trash.example"}
+	ns := emailchecker.InfraFeature{Kind: emailchecker.InfraNameServer, Value: "ns.trash.example"}
+
+	require.NoError(t, r.RecordInfrastructure(ctx, "trash1.example", true, []emailchecker.InfraFeature{host, ns}))
+	require.NoError(t, r.RecordInfrastructure(ctx, "trash2.example", true, []emailchecker.InfraFeature{host}))
+	require.NoError(t, r.RecordInfrastructure(ctx, "clean.example", false, []emailchecker.InfraFeature{host}))
+
+	stats, err := r.GetInfrastructureStats(ctx, "trash1.example", []emailchecker.InfraFeature{host, ns})
+	require.NoError(t, err)
+	assert.Equal(t, []emailchecker.InfraFeatureStats{
+		{InfraFeature: host, DisposableDomains: 1, CleanDomains: 1},
+	}, stats)
+
+	// A domain observed again takes its latest label.
+	require.NoError(t, r.RecordInfrastructure(ctx, "trash2.example", false, []emailchecker.InfraFeature{host}))
+
+	stats, err = r.GetInfrastructureStats(ctx, "", []emailchecker.InfraFeature{host})
+	require.NoError(t, err)
+	assert.Equal(t, []emailchecker.InfraFeatureStats{
+		{InfraFeature: host, DisposableDomains: 1, CleanDomains: 2},
+	}, stats)
+}
+
+func TestRepository_CandidateReview(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	seen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, r.UpsertCandidate(ctx, emailchecker.DisposableCandidate{
+		Domain:     "fresh.example",
+		Confidence: 0.6,
+		Evidence:   []emailchecker.InfraEvidence{},
+		LastSeenAt: seen,
+	}))
+
+	c, err := r.GetCandidate(ctx, "fresh.example")
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, emailchecker.CandidatePending, c.Status)
+	assert.Nil(t, c.ReviewedAt)
+
+	ok, err := r.SetCandidateStatus(ctx, "fresh.example", emailchecker.CandidateRejected, "jane")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = r.SetCandidateStatus(ctx, "unknown.example", emailchecker.CandidateRejected, "jane")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Seeing the candidate again refreshes its score but keeps the review.
+	require.NoError(t, r.UpsertCandidate(ctx, emailchecker.DisposableCandidate{
+		Domain:     "fresh.example",
+		Confidence: 0.9,
+		Evidence:   []emailchecker.InfraEvidence{},
+		LastSeenAt: seen.Add(time.Hour),
+	}))
+
+	c, err = r.GetCandidate(ctx, "fresh.example")
+	require.NoError(t, err)
+	assert.Equal(t, emailchecker.CandidateRejected, c.Status)
+	assert.Equal(t, "jane", c.ReviewedBy)
+	assert.NotNil(t, c.ReviewedAt)
+	assert.InDelta(t, 0.9, c.Confidence, 1e-9)
+	assert.True(t, c.FirstSeenAt.Equal(seen))
+
+	pending, err := r.ListCandidates(ctx, emailchecker.CandidatePending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rejected, err := r.ListCandidates(ctx, emailchecker.CandidateRejected)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "fresh.example", rejected[0].Domain)
+}
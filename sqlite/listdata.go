@@ -0,0 +1,227 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"emailchecker"
+	"emailchecker/listdata"
+	"emailchecker/pkg/log"
+)
+
+const dataVersionKeyPrefix = "data_version:"
+
+var lists = []emailchecker.ListName{
+	emailchecker.ListDisposable,
+	emailchecker.ListTop,
+	emailchecker.ListEducational,
+	emailchecker.ListParked,
+}
+
+type dataVersion struct {
+	Source  emailchecker.ListDataSource `json:"source"`
+	Version string                      `json:"version"`
+}
+
+// ListSize returns the number of domains of a list, not counting overrides.
+func (r *Repository) ListSize(ctx context.Context, list emailchecker.ListName) (int64, error) {
+	tbl, err := r.listTable(list)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	if err := r.readDB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tbl.MainTable)).Scan(&n); err != nil {
+		return 0, fmt.Errorf("could not count domains of '%s': %w", tbl.MainTable, err)
+	}
+
+	return n, nil
+}
+
+func (r *Repository) ListVersions(ctx context.Context) ([]emailchecker.ListVersion, error) {
+	ans := make([]emailchecker.ListVersion, 0, len(lists))
+
+	for _, list := range lists {
+		v, err := r.ListVersion(ctx, list)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, *v)
+	}
+
+	return ans, nil
+}
+
+// ListVersion returns the data version, size and last refresh of a list.
+func (r *Repository) ListVersion(ctx context.Context, list emailchecker.ListName) (*emailchecker.ListVersion, error) {
+	v := emailchecker.ListVersion{List: list}
+
+	var raw string
+
+	query := "SELECT value FROM app_metadata WHERE key = ?"
+
+	err := r.readDB.QueryRowContext(ctx, query, dataVersionKeyPrefix+string(list)).Scan(&raw)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return nil, fmt.Errorf("could not query data version of '%s': %w", list, err)
+	default:
+		var dv dataVersion
+		if err := json.Unmarshal([]byte(raw), &dv); err != nil {
+			return nil, fmt.Errorf("could not decode data version of '%s': %w", list, err)
+		}
+
+		v.Source = dv.Source
+		v.Version = dv.Version
+	}
+
+	if v.Rows, err = r.ListSize(ctx, list); err != nil {
+		return nil, err
+	}
+
+	if v.RefreshedAt, err = r.lastRefresh(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *Repository) lastRefresh(ctx context.Context, list emailchecker.ListName) (*time.Time, error) {
+	tbl, err := r.listTable(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw string
+
+	query := "SELECT value FROM app_metadata WHERE key = ?"
+
+	err = r.readDB.QueryRowContext(ctx, query, tbl.Key).Scan(&raw)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("could not query last refresh of '%s': %w", list, err)
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse last refresh of '%s': %w", list, err)
+	}
+
+	return &t, nil
+}
+
+// seed loads the embedded snapshot into the lists that are empty, which is
+// the case of a new database. A binary built without a snapshot is refused.
+func (r *Repository) seed(ctx context.Context) error {
+	version := listdata.Version()
+	if version == "" {
+		return errors.New("the binary embeds no list snapshot, run go generate ./listdata")
+	}
+
+	for _, list := range lists {
+		size, err := r.ListSize(ctx, list)
+		if err != nil {
+			return err
+		}
+
+		if size > 0 {
+			continue
+		}
+
+		rows, err := embeddedRows(list)
+		if err != nil {
+			return fmt.Errorf("could not read embedded %s list: %w", list, err)
+		}
+
+		if len(rows) == 0 {
+			return fmt.Errorf("the embedded %s list is empty, run go generate ./listdata", list)
+		}
+
+		if err := r.seedList(ctx, list, rows, version); err != nil {
+			return err
+		}
+
+		log.Info(ctx, "Seeded domain list from embedded snapshot", "list", list, "version", version, "domains", len(rows))
+	}
+
+	return nil
+}
+
+// seedList loads rows without touching the refresh timestamp, so that the
+// list is still downloaded on the first refresh.
+func (r *Repository) seedList(ctx context.Context, list emailchecker.ListName, rows []domainRow, version string) error {
+	tbl, err := r.listTable(list)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stageTable, _, err := r.stageDomains(ctx, tx, tbl, rows)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.applyDomains(ctx, tx, tbl, stageTable); err != nil {
+		return err
+	}
+
+	if err := r.setDataVersion(ctx, tx, list, emailchecker.ListDataEmbedded, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) setDataVersion(ctx context.Context, tx *sql.Tx, list emailchecker.ListName, source emailchecker.ListDataSource, version string) error {
+	raw, err := json.Marshal(dataVersion{Source: source, Version: version})
+	if err != nil {
+		return fmt.Errorf("could not encode data version: %w", err)
+	}
+
+	query := `
+	INSERT INTO app_metadata (key, value)
+	VALUES (?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value;
+	`
+
+	if _, err := tx.ExecContext(ctx, query, dataVersionKeyPrefix+string(list), string(raw)); err != nil {
+		return fmt.Errorf("could not update data version of '%s': %w", list, err)
+	}
+
+	return nil
+}
+
+func embeddedRows(list emailchecker.ListName) ([]domainRow, error) {
+	switch list {
+	case emailchecker.ListDisposable:
+		domains, err := listdata.Disposable()
+
+		return disposableRows(domains), err
+	case emailchecker.ListTop:
+		domains, err := listdata.Top()
+
+		return topDomainRows(domains), err
+	case emailchecker.ListEducational:
+		institutions, err := listdata.Educational()
+
+		return eduRows(institutions), err
+	case emailchecker.ListParked:
+		entries, err := listdata.Parked()
+
+		return parkedRows(entries), err
+	default:
+		return nil, fmt.Errorf("unknown list %q", list)
+	}
+}
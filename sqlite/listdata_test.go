@@ -0,0 +1,45 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/listdata"
+	"emailchecker/sqlite"
+)
+
+// TestRepository_Seed fails on a binary without a list snapshot, which would
+// start an air-gapped deployment with empty lists.
+func TestRepository_Seed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checker.db")
+
+	r, err := sqlite.New(path)
+	require.NoError(t, err)
+
+	versions, err := r.ListVersions(context.Background())
+	require.NoError(t, err)
+	r.Close()
+
+	require.Len(t, versions, 4)
+
+	for _, v := range versions {
+		assert.Equal(t, emailchecker.ListDataEmbedded, v.Source, v.List)
+		assert.Equal(t, listdata.Version(), v.Version, v.List)
+		assert.Positive(t, v.Rows, v.List)
+		assert.Nil(t, v.RefreshedAt, "a seeded list is still downloaded on the first refresh")
+	}
+
+	// An existing database is not seeded again.
+	r, err = sqlite.New(path)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	again, err := r.ListVersions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, versions, again)
+}
@@ -0,0 +1,167 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"emailchecker"
+)
+
+func (r *Repository) UpsertOverride(ctx context.Context, o emailchecker.DomainOverride) error {
+	query := `
+	INSERT INTO domain_overrides (list, domain, action, reason, author, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(list, domain) DO UPDATE SET
+		action = excluded.action,
+		reason = excluded.reason,
+		author = excluded.author,
+		created_at = excluded.created_at;
+	`
+
+	_, err := r.writeDB.ExecContext(ctx, query,
+		o.List, normalizeDomain(o.Domain), o.Action, o.Reason, o.Author, o.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("could not upsert override for '%s': %w", o.Domain, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteOverride(ctx context.Context, list emailchecker.ListName, domain string) (bool, error) {
+	query := "DELETE FROM domain_overrides WHERE list = ? AND domain = ?"
+
+	res, err := r.writeDB.ExecContext(ctx, query, list, normalizeDomain(domain))
+	if err != nil {
+		return false, fmt.Errorf("could not delete override for '%s': %w", domain, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not delete override for '%s': %w", domain, err)
+	}
+
+	return n > 0, nil
+}
+
+// ListOverrides returns the overrides of the list, or of every list when
+// list is empty.
+func (r *Repository) ListOverrides(ctx context.Context, list emailchecker.ListName) ([]emailchecker.DomainOverride, error) {
+	query := "SELECT list, domain, action, reason, author, created_at FROM domain_overrides"
+
+	var args []any
+	if list != "" {
+		query += " WHERE list = ?"
+		args = append(args, list)
+	}
+
+	query += " ORDER BY list, domain"
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list overrides: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	ans := []emailchecker.DomainOverride{}
+	for rows.Next() {
+		o, err := scanOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, *o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list overrides: %w", err)
+	}
+
+	return ans, nil
+}
+
+// findOverride returns the override of the most specific of the given
+// domains, or nil when none of them is overridden.
+func (r *Repository) findOverride(ctx context.Context, list emailchecker.ListName, domains []string) (*emailchecker.DomainOverride, error) {
+	if len(domains) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(domains)+1)
+	args = append(args, list)
+
+	for _, domain := range domains {
+		args = append(args, normalizeDomain(domain))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(domains)), ",")
+	query := fmt.Sprintf(`SELECT list, domain, action, reason, author, created_at
+		FROM domain_overrides WHERE list = ? AND domain IN (%s)`, placeholders)
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query overrides: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	found := make(map[string]*emailchecker.DomainOverride, len(domains))
+	for rows.Next() {
+		o, err := scanOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		found[o.Domain] = o
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query overrides: %w", err)
+	}
+
+	for _, domain := range domains {
+		if o, ok := found[normalizeDomain(domain)]; ok {
+			return o, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func scanOverride(rows *sql.Rows) (*emailchecker.DomainOverride, error) {
+	var (
+		o         emailchecker.DomainOverride
+		createdAt time.Time
+	)
+
+	if err := rows.Scan(&o.List, &o.Domain, &o.Action, &o.Reason, &o.Author, &createdAt); err != nil {
+		return nil, fmt.Errorf("could not scan override: %w", err)
+	}
+
+	o.CreatedAt = createdAt
+
+	return &o, nil
+}
+
+func (r *Repository) createOverridesTable(ctx context.Context, tx *sql.Tx) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS domain_overrides (
+		list TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		action TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		author TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (list, domain)
+	);`
+	_, err := tx.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("could not create domain_overrides table: %w", err)
+	}
+	return nil
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+}
@@ -0,0 +1,131 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/sqlite"
+)
+
+func override(list emailchecker.ListName, domain string, action emailchecker.OverrideAction) emailchecker.DomainOverride {
+	return emailchecker.DomainOverride{
+		List:      list,
+		Domain:    domain,
+		Action:    action,
+		Reason:    "customer report",
+		Author:    "support",
+		CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// seedLists fills every list with a single domain.
+func seedLists(t *testing.T, r *sqlite.Repository) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	_, err := r.UpdateDomains(ctx, disposableDomains("trash.example"), nil)
+	require.NoError(t, err)
+
+	_, err = r.UpdateTopDomains(ctx, []emailchecker.TopDomain{{Domain: "popular.example", Rank: 10}}, nil)
+	require.NoError(t, err)
+
+	_, err = r.UpdateEducationalDomains(ctx, []emailchecker.EducationalInstitution{{Domain: "uni.example", Name: "University"}}, nil)
+	require.NoError(t, err)
+}
+
+func TestRepository_OverridesTakePrecedence(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	seedLists(t, r)
+
+	for _, o := range []emailchecker.DomainOverride{
+		override(emailchecker.ListDisposable, "trash.example", emailchecker.OverrideExclude),
+		override(emailchecker.ListDisposable, "Isp.Example.", emailchecker.OverrideInclude),
+		override(emailchecker.ListTop, "popular.example", emailchecker.OverrideExclude),
+		override(emailchecker.ListTop, "corp.example", emailchecker.OverrideInclude),
+		override(emailchecker.ListEducational, "uni.example", emailchecker.OverrideExclude),
+		override(emailchecker.ListEducational, "college.example", emailchecker.OverrideInclude),
+	} {
+		require.NoError(t, r.UpsertOverride(ctx, o))
+	}
+
+	// Overrides survive the table swap of a refresh.
+	seedLists(t, r)
+
+	disposable, err := r.GetDisposableDomain(ctx, []string{"trash.example"})
+	require.NoError(t, err)
+	assert.Equal(t, &emailchecker.DisposableDomain{Domain: "trash.example", Sources: []string{"override"}, Override: true, Excluded: true}, disposable)
+
+	disposable, err = r.GetDisposableDomain(ctx, []string{"mail.isp.example", "isp.example"})
+	require.NoError(t, err)
+	assert.Equal(t, &emailchecker.DisposableDomain{Domain: "isp.example", Sources: []string{"override"}, Override: true}, disposable)
+
+	top, err := r.GetTopDomain(ctx, []string{"popular.example"})
+	require.NoError(t, err)
+	assert.Nil(t, top)
+
+	top, err = r.GetTopDomain(ctx, []string{"corp.example"})
+	require.NoError(t, err)
+	assert.Equal(t, &emailchecker.TopDomain{Domain: "corp.example"}, top)
+
+	edu, err := r.GetEducationalInstitution(ctx, []string{"uni.example"})
+	require.NoError(t, err)
+	assert.Nil(t, edu)
+
+	edu, err = r.GetEducationalInstitution(ctx, []string{"college.example"})
+	require.NoError(t, err)
+	assert.Equal(t, &emailchecker.EducationalInstitution{Domain: "college.example"}, edu)
+}
+
+func TestRepository_MostSpecificOverrideWins(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.UpsertOverride(ctx, override(emailchecker.ListDisposable, "example.com", emailchecker.OverrideInclude)))
+	require.NoError(t, r.UpsertOverride(ctx, override(emailchecker.ListDisposable, "mail.example.com", emailchecker.OverrideExclude)))
+
+	got, err := r.GetDisposableDomain(ctx, []string{"mail.example.com", "example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "mail.example.com", got.Domain)
+	assert.True(t, got.Excluded)
+}
+
+func TestRepository_ManageOverrides(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.UpsertOverride(ctx, override(emailchecker.ListTop, "b.example", emailchecker.OverrideInclude)))
+	require.NoError(t, r.UpsertOverride(ctx, override(emailchecker.ListDisposable, "a.example", emailchecker.OverrideInclude)))
+
+	// Upserting again replaces the override.
+	updated := override(emailchecker.ListDisposable, "A.example", emailchecker.OverrideExclude)
+	updated.Reason = "false positive"
+	require.NoError(t, r.UpsertOverride(ctx, updated))
+
+	all, err := r.ListOverrides(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "a.example", all[0].Domain)
+	assert.Equal(t, emailchecker.OverrideExclude, all[0].Action)
+	assert.Equal(t, "false positive", all[0].Reason)
+	assert.Equal(t, "b.example", all[1].Domain)
+
+	top, err := r.ListOverrides(ctx, emailchecker.ListTop)
+	require.NoError(t, err)
+	assert.Len(t, top, 1)
+
+	deleted, err := r.DeleteOverride(ctx, emailchecker.ListDisposable, "A.EXAMPLE")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = r.DeleteOverride(ctx, emailchecker.ListDisposable, "a.example")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"emailchecker"
+)
+
+// IsParkedNameServer reports whether one of the given domains, usually the
+// candidates of a nameserver, is a parking nameserver.
+func (r *Repository) IsParkedNameServer(ctx context.Context, domains []string) (bool, error) {
+	if len(domains) == 0 {
+		return false, nil
+	}
+
+	args := []any{emailchecker.ParkedNameServer}
+	for _, domain := range domains {
+		args = append(args, strings.TrimSuffix(domain, "."))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(domains)), ",")
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM parked_entries WHERE kind = ? AND domain IN (%s))", placeholders)
+
+	var exists bool
+	if err := r.readDB.QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("could not query parked nameservers: %w", err)
+	}
+
+	return exists, nil
+}
+
+// IsParkedAddress reports whether ip is within a parking address range.
+func (r *Repository) IsParkedAddress(ctx context.Context, ip net.IP) (bool, error) {
+	if ip.To16() == nil {
+		return false, nil
+	}
+
+	key := hex.EncodeToString(ip.To16())
+	query := "SELECT EXISTS(SELECT 1 FROM parked_entries WHERE kind = ? AND first_ip <= ? AND last_ip >= ?)"
+
+	var exists bool
+	if err := r.readDB.QueryRowContext(ctx, query, emailchecker.ParkedAddress, key, key).Scan(&exists); err != nil {
+		return false, fmt.Errorf("could not query parked addresses: %w", err)
+	}
+
+	return exists, nil
+}
+
+// parkedRows stores the bounds of address ranges as hex encoded 16 byte
+// addresses, which compare in address order as text. Nameservers have none.
+func parkedRows(entries []emailchecker.ParkedEntry) []domainRow {
+	rows := make([]domainRow, 0, len(entries))
+	for _, e := range entries {
+		var first, last any
+
+		if e.Kind == emailchecker.ParkedAddress {
+			_, network, err := net.ParseCIDR(e.Value)
+			if err != nil {
+				continue
+			}
+
+			lo, hi := addressBounds(network)
+			first, last = hex.EncodeToString(lo), hex.EncodeToString(hi)
+		}
+
+		rows = append(rows, domainRow{Domain: e.Value, Values: []any{string(e.Kind), first, last}})
+	}
+
+	return rows
+}
+
+// addressBounds returns the first and last addresses of a network in their
+// 16 byte form.
+func addressBounds(network *net.IPNet) (net.IP, net.IP) {
+	first := network.IP.Mask(network.Mask)
+	last := make(net.IP, len(first))
+
+	for i := range first {
+		last[i] = first[i] | ^network.Mask[i]
+	}
+
+	return first.To16(), last.To16()
+}
+
+func (r *Repository) createParkedEntriesTable(ctx context.Context, tx *sql.Tx, name string) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			domain TEXT PRIMARY KEY NOT NULL,
+			kind TEXT NOT NULL,
+			first_ip TEXT,
+			last_ip TEXT
+	);
+		CREATE INDEX IF NOT EXISTS idx_%s_first_ip ON %s (first_ip);`, name, name, name)
+
+	_, err := tx.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("could not create parked_entries table: %w", err)
+	}
+
+	return nil
+}
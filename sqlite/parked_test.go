@@ -0,0 +1,103 @@
+package sqlite_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/sqlite"
+)
+
+func importParked(t *testing.T, r *sqlite.Repository, values ...string) *emailchecker.ListRefreshResult {
+	t.Helper()
+
+	data := emailchecker.ListData{}
+	for _, v := range values {
+		entry, err := emailchecker.ParseParkedEntry(v)
+		require.NoError(t, err)
+
+		data.Parked = append(data.Parked, entry)
+	}
+
+	result, err := r.ImportList(context.Background(), &emailchecker.ListImport{
+		List:   emailchecker.ListParked,
+		File:   "/tmp/parked.txt",
+		Format: emailchecker.ImportText,
+		Mode:   emailchecker.ImportReplace,
+	}, data)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestRepository_ParkedList(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	importParked(t, r, "sedoparking.com", "185.53.176.0/22", "2606:4700:20::681a:625")
+
+	nameServers := []struct {
+		candidates []string
+		parked     bool
+	}{
+		{candidates: []string{"ns1.sedoparking.com", "sedoparking.com"}, parked: true},
+		{candidates: []string{"ns1.example.net", "example.net"}},
+		// Address ranges are not nameservers.
+		{candidates: []string{"185.53.176.0/22"}},
+	}
+
+	for _, tc := range nameServers {
+		parked, err := r.IsParkedNameServer(ctx, tc.candidates)
+		require.NoError(t, err)
+		assert.Equal(t, tc.parked, parked, tc.candidates)
+	}
+
+	addresses := []struct {
+		ip     string
+		parked bool
+	}{
+		{ip: "185.53.176.0", parked: true},
+		{ip: "185.53.179.255", parked: true},
+		{ip: "185.53.175.255"},
+		{ip: "185.53.180.0"},
+		{ip: "2606:4700:20::681a:625", parked: true},
+		{ip: "2606:4700:20::681a:626"},
+	}
+
+	for _, tc := range addresses {
+		parked, err := r.IsParkedAddress(ctx, net.ParseIP(tc.ip))
+		require.NoError(t, err)
+		assert.Equal(t, tc.parked, parked, tc.ip)
+	}
+}
+
+func TestRepository_ParkedListRollback(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	importParked(t, r, "sedoparking.com", "185.53.176.0/22")
+
+	result := importParked(t, r, "bodis.com", "199.59.240.0/22")
+	assert.EqualValues(t, 2, result.Added)
+	assert.EqualValues(t, 2, result.Removed)
+
+	parked, err := r.IsParkedAddress(ctx, net.ParseIP("185.53.177.1"))
+	require.NoError(t, err)
+	assert.False(t, parked)
+
+	// The snapshot keeps the address ranges, so the rollback restores them.
+	_, err = r.RollbackList(ctx, emailchecker.ListParked)
+	require.NoError(t, err)
+
+	parked, err = r.IsParkedAddress(ctx, net.ParseIP("185.53.177.1"))
+	require.NoError(t, err)
+	assert.True(t, parked)
+
+	parked, err = r.IsParkedNameServer(ctx, []string{"bodis.com"})
+	require.NoError(t, err)
+	assert.False(t, parked)
+}
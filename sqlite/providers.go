@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"emailchecker"
+)
+
+// providerColumns lists the columns read by scanProvider.
+const providerColumns = "domain, type, source, author, updated_at"
+
+// SyncCuratedProviders replaces the curated entries of the provider table.
+// Local entries are kept.
+func (r *Repository) SyncCuratedProviders(ctx context.Context, providers []emailchecker.EmailProvider) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM email_providers WHERE source = ?", emailchecker.ProviderSourceCurated); err != nil {
+		return fmt.Errorf("could not delete curated providers: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO email_providers (domain, type, source, author, updated_at)
+		VALUES (?, ?, ?, '', ?)`)
+	if err != nil {
+		return fmt.Errorf("could not prepare insert for providers: %w", err)
+	}
+	defer stmt.Close() //nolint:errcheck
+
+	now := time.Now().UTC()
+	for _, p := range providers {
+		if _, err := stmt.ExecContext(ctx, normalizeDomain(p.Domain), p.Type, emailchecker.ProviderSourceCurated, now); err != nil {
+			return fmt.Errorf("could not insert provider '%s': %w", p.Domain, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit curated providers: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpsertProvider(ctx context.Context, p emailchecker.EmailProvider) error {
+	query := `
+	INSERT INTO email_providers (domain, type, source, author, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(domain, source) DO UPDATE SET
+		type = excluded.type,
+		author = excluded.author,
+		updated_at = excluded.updated_at;
+	`
+
+	_, err := r.writeDB.ExecContext(ctx, query,
+		normalizeDomain(p.Domain), p.Type, emailchecker.ProviderSourceLocal, p.Author, p.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("could not upsert provider '%s': %w", p.Domain, err)
+	}
+
+	return nil
+}
+
+// DeleteProvider deletes the local entry of a domain.
+func (r *Repository) DeleteProvider(ctx context.Context, domain string) (bool, error) {
+	query := "DELETE FROM email_providers WHERE domain = ? AND source = ?"
+
+	res, err := r.writeDB.ExecContext(ctx, query, normalizeDomain(domain), emailchecker.ProviderSourceLocal)
+	if err != nil {
+		return false, fmt.Errorf("could not delete provider '%s': %w", domain, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not delete provider '%s': %w", domain, err)
+	}
+
+	return n > 0, nil
+}
+
+// GetProvider returns the entry of the most specific of the given domains,
+// preferring local entries, or nil when none of them is in the table.
+func (r *Repository) GetProvider(ctx context.Context, domains []string) (*emailchecker.EmailProvider, error) {
+	if len(domains) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(domains))
+	for _, domain := range domains {
+		args = append(args, normalizeDomain(domain))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	query := fmt.Sprintf("SELECT %s FROM email_providers WHERE domain IN (%s)", providerColumns, placeholders)
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query providers: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	found := make(map[string]*emailchecker.EmailProvider, len(domains))
+	for rows.Next() {
+		p, err := scanProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if prev, ok := found[p.Domain]; ok && prev.Source == emailchecker.ProviderSourceLocal {
+			continue
+		}
+
+		found[p.Domain] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query providers: %w", err)
+	}
+
+	for _, domain := range domains {
+		if p, ok := found[normalizeDomain(domain)]; ok {
+			return p, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ListProviders returns the effective entry of each domain, of the given
+// type or of every type when t is empty.
+func (r *Repository) ListProviders(ctx context.Context, t emailchecker.ProviderType) ([]emailchecker.EmailProvider, error) {
+	query := fmt.Sprintf(`SELECT %s FROM email_providers p
+		WHERE (source = ? OR NOT EXISTS (
+			SELECT 1 FROM email_providers l WHERE l.domain = p.domain AND l.source = ?
+		))`, providerColumns)
+
+	args := []any{emailchecker.ProviderSourceLocal, emailchecker.ProviderSourceLocal}
+	if t != "" {
+		query += " AND type = ?"
+		args = append(args, t)
+	}
+
+	query += " ORDER BY domain"
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list providers: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	ans := []emailchecker.EmailProvider{}
+	for rows.Next() {
+		p, err := scanProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ans = append(ans, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list providers: %w", err)
+	}
+
+	return ans, nil
+}
+
+func scanProvider(rows *sql.Rows) (*emailchecker.EmailProvider, error) {
+	var p emailchecker.EmailProvider
+
+	if err := rows.Scan(&p.Domain, &p.Type, &p.Source, &p.Author, &p.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("could not scan provider: %w", err)
+	}
+
+	return &p, nil
+}
+
+func (r *Repository) createProvidersTable(ctx context.Context, tx *sql.Tx) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS email_providers (
+		domain TEXT NOT NULL,
+		type TEXT NOT NULL,
+		source TEXT NOT NULL,
+		author TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (domain, source)
+	);`
+	_, err := tx.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("could not create email_providers table: %w", err)
+	}
+	return nil
+}
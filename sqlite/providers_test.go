@@ -0,0 +1,110 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+)
+
+func curated(domain string, t emailchecker.ProviderType) emailchecker.EmailProvider {
+	return emailchecker.EmailProvider{Domain: domain, Type: t, Source: emailchecker.ProviderSourceCurated}
+}
+
+func TestRepository_Providers(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.SyncCuratedProviders(ctx, []emailchecker.EmailProvider{
+		curated("gmail.com", emailchecker.ProviderFreeWebmail),
+		curated("Orange.FR", emailchecker.ProviderISP),
+	}))
+
+	require.NoError(t, r.UpsertProvider(ctx, emailchecker.EmailProvider{
+		Domain:    "gmail.com",
+		Type:      emailchecker.ProviderCorporate,
+		Author:    "support",
+		UpdatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}))
+
+	cases := []struct {
+		name    string
+		domains []string
+		want    *emailchecker.EmailProvider
+	}{
+		{name: "Not listed", domains: []string{"example.com"}},
+		{name: "Curated", domains: []string{"orange.fr"}, want: &emailchecker.EmailProvider{Domain: "orange.fr", Type: emailchecker.ProviderISP, Source: emailchecker.ProviderSourceCurated}},
+		{name: "Most specific domain", domains: []string{"mail.orange.fr", "orange.fr"}, want: &emailchecker.EmailProvider{Domain: "orange.fr", Type: emailchecker.ProviderISP, Source: emailchecker.ProviderSourceCurated}},
+		{name: "Local entry wins", domains: []string{"gmail.com"}, want: &emailchecker.EmailProvider{Domain: "gmail.com", Type: emailchecker.ProviderCorporate, Source: emailchecker.ProviderSourceLocal, Author: "support"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.GetProvider(ctx, tc.domains)
+			require.NoError(t, err)
+
+			if tc.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			got.UpdatedAt = time.Time{}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRepository_ManageProviders(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.SyncCuratedProviders(ctx, []emailchecker.EmailProvider{
+		curated("gmail.com", emailchecker.ProviderFreeWebmail),
+		curated("proton.me", emailchecker.ProviderPrivacy),
+	}))
+	require.NoError(t, r.UpsertProvider(ctx, emailchecker.EmailProvider{Domain: "gmail.com", Type: emailchecker.ProviderCorporate, Author: "support"}))
+	require.NoError(t, r.UpsertProvider(ctx, emailchecker.EmailProvider{Domain: "corp.example", Type: emailchecker.ProviderCorporate, Author: "support"}))
+
+	// A new release replaces the curated entries and keeps the local ones.
+	require.NoError(t, r.SyncCuratedProviders(ctx, []emailchecker.EmailProvider{
+		curated("gmail.com", emailchecker.ProviderFreeWebmail),
+		curated("tutanota.com", emailchecker.ProviderPrivacy),
+	}))
+
+	all, err := r.ListProviders(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"corp.example", "gmail.com", "tutanota.com"}, providerDomains(all))
+	assert.Equal(t, emailchecker.ProviderSourceLocal, all[1].Source, "the local entry is listed instead of the curated one")
+
+	privacy, err := r.ListProviders(ctx, emailchecker.ProviderPrivacy)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tutanota.com"}, providerDomains(privacy))
+
+	// Removing the local entry restores the curated one.
+	deleted, err := r.DeleteProvider(ctx, "GMAIL.com")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	got, err := r.GetProvider(ctx, []string{"gmail.com"})
+	require.NoError(t, err)
+	assert.Equal(t, emailchecker.ProviderFreeWebmail, got.Type)
+
+	// Curated entries cannot be deleted.
+	deleted, err = r.DeleteProvider(ctx, "gmail.com")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func providerDomains(providers []emailchecker.EmailProvider) []string {
+	ans := make([]string, 0, len(providers))
+	for _, p := range providers {
+		ans = append(ans, p.Domain)
+	}
+
+	return ans
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"emailchecker"
+)
+
+func (r *Repository) GetDomainRegistration(ctx context.Context, domain string) (*emailchecker.DomainRegistration, error) {
+	reg := emailchecker.DomainRegistration{Domain: domain}
+
+	var registeredAt sql.NullTime
+
+	query := "SELECT registered_at, checked_at FROM domain_registrations WHERE domain = ?"
+	err := r.readDB.QueryRowContext(ctx, query, domain).Scan(&registeredAt, &reg.CheckedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not get registration of '%s': %w", domain, err)
+	}
+
+	if registeredAt.Valid {
+		reg.RegisteredAt = &registeredAt.Time
+	}
+
+	return &reg, nil
+}
+
+func (r *Repository) UpsertDomainRegistration(ctx context.Context, reg emailchecker.DomainRegistration) error {
+	query := `
+	INSERT INTO domain_registrations (domain, registered_at, checked_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT(domain) DO UPDATE SET
+		registered_at = excluded.registered_at,
+		checked_at = excluded.checked_at;
+	`
+
+	var registeredAt any
+	if reg.RegisteredAt != nil {
+		registeredAt = reg.RegisteredAt.UTC()
+	}
+
+	_, err := r.writeDB.ExecContext(ctx, query, reg.Domain, registeredAt, reg.CheckedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("could not upsert registration of '%s': %w", reg.Domain, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) createDomainRegistrationsTable(ctx context.Context, tx *sql.Tx) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS domain_registrations (
+		domain TEXT PRIMARY KEY NOT NULL,
+		registered_at TIMESTAMP,
+		checked_at TIMESTAMP NOT NULL
+	);`
+	_, err := tx.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("could not create domain_registrations table: %w", err)
+	}
+	return nil
+}
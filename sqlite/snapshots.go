@@ -0,0 +1,243 @@
+package sqlite
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"emailchecker"
+)
+
+const defaultSnapshotRetention = 3
+
+// nullField encodes a NULL column in snapshot data.
+const nullField = `\N`
+
+func (r *Repository) ListSnapshots(ctx context.Context, list emailchecker.ListName) ([]emailchecker.ListSnapshot, error) {
+	query := "SELECT id, list, rows, created_at FROM list_snapshots"
+
+	var args []any
+	if list != "" {
+		query += " WHERE list = ?"
+		args = append(args, list)
+	}
+
+	query += " ORDER BY id DESC"
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list snapshots: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	ans := []emailchecker.ListSnapshot{}
+	for rows.Next() {
+		var s emailchecker.ListSnapshot
+		if err := rows.Scan(&s.ID, &s.List, &s.Rows, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("could not scan snapshot: %w", err)
+		}
+
+		ans = append(ans, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list snapshots: %w", err)
+	}
+
+	return ans, nil
+}
+
+// RollbackList restores the latest snapshot of a list and deletes it. The
+// refresh timestamp is reset, so the next scheduled refresh does not undo the
+// rollback right away, and the download validators are cleared, so that the
+// refresh after it downloads the list in full rather than keeping the
+// restored data as not modified.
+func (r *Repository) RollbackList(ctx context.Context, list emailchecker.ListName) (*emailchecker.ListRefreshResult, error) {
+	tbl, err := r.listTable(list)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var (
+		id   int64
+		data []byte
+	)
+
+	query := "SELECT id, data FROM list_snapshots WHERE list = ? ORDER BY id DESC LIMIT 1"
+
+	err = tx.QueryRowContext(ctx, query, list).Scan(&id, &data)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, fmt.Errorf("%w: %s has no snapshot to roll back to", emailchecker.ErrNoSnapshot, list)
+	case err != nil:
+		return nil, fmt.Errorf("could not get snapshot of '%s': %w", list, err)
+	}
+
+	rows, err := decodeSnapshot(data, len(tbl.Columns))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode snapshot %d: %w", id, err)
+	}
+
+	stageTable, total, err := r.stageDomains(ctx, tx, tbl, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.applyDomains(ctx, tx, tbl, stageTable)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Total = total
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM list_snapshots WHERE id = ?", id); err != nil {
+		return nil, fmt.Errorf("could not delete snapshot %d: %w", id, err)
+	}
+
+	if err := r.updateRefreshTimestamp(ctx, tx, tbl.Key); err != nil {
+		return nil, err
+	}
+
+	if err := r.clearValidators(ctx, tx, list); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit rollback of '%s': %w", list, err)
+	}
+
+	return result, nil
+}
+
+// createSnapshot stores the current data of a list and prunes the snapshots
+// beyond the retention. Empty lists are not snapshotted.
+func (r *Repository) createSnapshot(ctx context.Context, tx *sql.Tx, list emailchecker.ListName, tbl listTable) error {
+	if r.snapshotRetention <= 0 {
+		return nil
+	}
+
+	columns := strings.Join(append([]string{"domain"}, tbl.Columns...), ", ")
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", columns, tbl.MainTable))
+	if err != nil {
+		return fmt.Errorf("could not read domains of '%s': %w", tbl.MainTable, err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	values := make([]sql.NullString, len(tbl.Columns)+1)
+	dest := make([]any, len(values))
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("could not scan domain: %w", err)
+		}
+
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = v.String
+			if !v.Valid {
+				fields[i] = nullField
+			}
+		}
+
+		if _, err := fmt.Fprintln(zw, strings.Join(fields, "\t")); err != nil {
+			return fmt.Errorf("could not write snapshot: %w", err)
+		}
+
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read domains of '%s': %w", tbl.MainTable, err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	insert := "INSERT INTO list_snapshots (list, rows, data, created_at) VALUES (?, ?, ?, ?)"
+	if _, err := tx.ExecContext(ctx, insert, list, count, buf.Bytes(), time.Now().UTC()); err != nil {
+		return fmt.Errorf("could not store snapshot of '%s': %w", list, err)
+	}
+
+	prune := `DELETE FROM list_snapshots WHERE list = ? AND id NOT IN (
+		SELECT id FROM list_snapshots WHERE list = ? ORDER BY id DESC LIMIT ?
+	)`
+	if _, err := tx.ExecContext(ctx, prune, list, list, r.snapshotRetention); err != nil {
+		return fmt.Errorf("could not prune snapshots of '%s': %w", list, err)
+	}
+
+	return nil
+}
+
+// decodeSnapshot reads the rows of a snapshot. Columns missing from
+// snapshots taken before they were added are restored as NULL.
+func decodeSnapshot(data []byte, columns int) ([]domainRow, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close() //nolint:errcheck
+
+	var rows []domainRow
+
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+
+		row := domainRow{Domain: fields[0], Values: make([]any, columns)}
+		for i, f := range fields[1:] {
+			if i < columns && f != nullField {
+				row.Values[i] = f
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *Repository) createSnapshotsTable(ctx context.Context, tx *sql.Tx) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS list_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		list TEXT NOT NULL,
+		rows INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_list_snapshots_list ON list_snapshots(list, id);`
+	_, err := tx.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("could not create list_snapshots table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+	"emailchecker/sqlite"
+)
+
+func disposableDomains(names ...string) []emailchecker.DisposableDomain {
+	ans := make([]emailchecker.DisposableDomain, 0, len(names))
+	for _, name := range names {
+		ans = append(ans, emailchecker.DisposableDomain{Domain: name, Sources: []string{"test"}})
+	}
+
+	return ans
+}
+
+func numbered(n int) []string {
+	ans := []string{"mailinator.com"}
+	for i := 1; i < n; i++ {
+		ans = append(ans, fmt.Sprintf("trash%d.example", i))
+	}
+
+	return ans
+}
+
+func TestRepository_UpdateDomainsGuard(t *testing.T) {
+	guard := &emailchecker.ListGuard{
+		MinRows:   5,
+		MaxShrink: 0.3,
+		Samples:   []string{"mailinator.com"},
+	}
+
+	cases := []struct {
+		name    string
+		domains []string
+		unsafe  bool
+	}{
+		{name: "Safe update", domains: numbered(9)},
+		{name: "Too few domains", domains: numbered(4), unsafe: true},
+		{name: "Shrinks too much", domains: numbered(6), unsafe: true},
+		{name: "Missing sample", domains: numbered(11)[1:], unsafe: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newRepository(t)
+			ctx := context.Background()
+
+			_, err := r.UpdateDomains(ctx, disposableDomains(numbered(10)...), nil)
+			require.NoError(t, err)
+
+			_, err = r.UpdateDomains(ctx, disposableDomains(tc.domains...), guard)
+
+			size, serr := r.ListSize(ctx, emailchecker.ListDisposable)
+			require.NoError(t, serr)
+
+			if tc.unsafe {
+				require.ErrorIs(t, err, emailchecker.ErrUnsafeListData)
+				assert.Equal(t, int64(10), size, "a rejected update keeps the live list")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tc.domains)), size)
+		})
+	}
+}
+
+func TestRepository_RollbackList(t *testing.T) {
+	r := newRepository(t)
+	ctx := context.Background()
+
+	_, err := r.UpdateDomains(ctx, disposableDomains("mailinator.com", "old.example"), nil)
+	require.NoError(t, err)
+
+	_, err = r.UpdateDomains(ctx, disposableDomains("mailinator.com", "new.example", "newer.example"), nil)
+	require.NoError(t, err)
+
+	validators := httpext.Validators{ETag: `"v2"`}
+	require.NoError(t, r.Validators(emailchecker.ListDisposable).SetValidators(ctx, "https://lists.example/disposable.txt", validators))
+	require.NoError(t, r.Validators(emailchecker.ListTop).SetValidators(ctx, "https://lists.example/top.csv", validators))
+
+	snapshots, err := r.ListSnapshots(ctx, emailchecker.ListDisposable)
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshots)
+	assert.Equal(t, int64(2), snapshots[0].Rows)
+
+	result, err := r.RollbackList(ctx, emailchecker.ListDisposable)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, int64(1), result.Added)
+	assert.Equal(t, int64(2), result.Removed)
+
+	for domain, listed := range map[string]bool{"old.example": true, "new.example": false, "newer.example": false} {
+		d, err := r.GetDisposableDomain(ctx, []string{domain})
+		require.NoError(t, err)
+		assert.Equal(t, listed, d != nil, domain)
+	}
+
+	// The next refresh must download the list in full, without touching
+	// the validators of the other lists.
+	v, err := r.Validators(emailchecker.ListDisposable).GetValidators(ctx, "https://lists.example/disposable.txt")
+	require.NoError(t, err)
+	assert.True(t, v.IsZero())
+
+	v, err = r.Validators(emailchecker.ListTop).GetValidators(ctx, "https://lists.example/top.csv")
+	require.NoError(t, err)
+	assert.Equal(t, validators, v)
+
+	rest, err := r.ListSnapshots(ctx, emailchecker.ListDisposable)
+	require.NoError(t, err)
+	assert.Len(t, rest, len(snapshots)-1)
+}
+
+func TestRepository_RollbackListWithoutSnapshot(t *testing.T) {
+	r := newRepository(t, sqlite.WithSnapshotRetention(0))
+	ctx := context.Background()
+
+	_, err := r.UpdateDomains(ctx, disposableDomains("mailinator.com"), nil)
+	require.NoError(t, err)
+
+	_, err = r.UpdateDomains(ctx, disposableDomains("mailinator.com", "new.example"), nil)
+	require.NoError(t, err)
+
+	_, err = r.RollbackList(ctx, emailchecker.ListDisposable)
+	assert.ErrorIs(t, err, emailchecker.ErrNoSnapshot)
+}
+
+func TestRepository_UpdateDomainsGuardMaxRows(t *testing.T) {
+	top := func(n int) []emailchecker.TopDomain {
+		ans := make([]emailchecker.TopDomain, 0, n)
+		for i := range n {
+			ans = append(ans, emailchecker.TopDomain{Domain: fmt.Sprintf("site%d.example", i), Rank: i + 1})
+		}
+
+		return ans
+	}
+
+	r := newRepository(t)
+	ctx := context.Background()
+
+	_, err := r.UpdateTopDomains(ctx, top(10), nil)
+	require.NoError(t, err)
+
+	// Cutting the list to 3 domains looks like a truncated download,
+	_, err = r.UpdateTopDomains(ctx, top(3), &emailchecker.ListGuard{MaxShrink: 0.3})
+	require.ErrorIs(t, err, emailchecker.ErrUnsafeListData)
+
+	// unless the list was capped at 3 domains.
+	_, err = r.UpdateTopDomains(ctx, top(3), &emailchecker.ListGuard{MaxShrink: 0.3, MaxRows: 3})
+	require.NoError(t, err)
+
+	size, err := r.ListSize(ctx, emailchecker.ListTop)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), size)
+}
+
+func TestRepository_UpdateDomainsDiff(t *testing.T) {
+	cases := []struct {
+		name    string
+		domains []emailchecker.TopDomain
+		want    emailchecker.ListRefreshResult
+	}{
+		{
+			name:    "Unchanged",
+			domains: []emailchecker.TopDomain{{Domain: "a.example", Rank: 1}, {Domain: "b.example", Rank: 2}, {Domain: "c.example", Rank: 3}},
+			want:    emailchecker.ListRefreshResult{Total: 3},
+		},
+		{
+			name:    "Added and removed",
+			domains: []emailchecker.TopDomain{{Domain: "a.example", Rank: 1}, {Domain: "b.example", Rank: 2}, {Domain: "d.example", Rank: 3}, {Domain: "e.example", Rank: 4}},
+			want:    emailchecker.ListRefreshResult{Added: 2, Removed: 1, Total: 4},
+		},
+		{
+			name:    "Updated",
+			domains: []emailchecker.TopDomain{{Domain: "a.example", Rank: 2}, {Domain: "b.example", Rank: 1}, {Domain: "c.example", Rank: 3}},
+			want:    emailchecker.ListRefreshResult{Updated: 2, Total: 3},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newRepository(t)
+			ctx := context.Background()
+
+			_, err := r.UpdateTopDomains(ctx, []emailchecker.TopDomain{
+				{Domain: "a.example", Rank: 1},
+				{Domain: "b.example", Rank: 2},
+				{Domain: "c.example", Rank: 3},
+			}, nil)
+			require.NoError(t, err)
+
+			got, err := r.UpdateTopDomains(ctx, tc.domains, nil)
+			require.NoError(t, err)
+
+			got.Elapsed = 0
+			assert.Equal(t, tc.want, *got)
+
+			top, err := r.GetTopDomain(ctx, []string{tc.domains[0].Domain})
+			require.NoError(t, err)
+			assert.Equal(t, tc.domains[0].Rank, top.Rank)
+		})
+	}
+}
@@ -9,16 +9,39 @@ import (
 
 	"emailchecker"
 
-	"golang.org/x/net/publicsuffix"
 	_ "modernc.org/sqlite"
 )
 
+// overrideSource is reported as the source of domains listed by a local override.
+const overrideSource = "override"
+
 type Repository struct {
 	readDB  *sql.DB
 	writeDB *sql.DB
+
+	snapshotRetention int
+	skipSeed          bool
+}
+
+type Option func(*Repository)
+
+// WithSnapshotRetention sets how many snapshots are kept per list. Zero
+// disables snapshots.
+func WithSnapshotRetention(n int) Option {
+	return func(r *Repository) {
+		r.snapshotRetention = n
+	}
 }
 
-func New(dbPath string) (*Repository, error) {
+// WithoutSeed leaves the lists of a new database empty instead of loading the
+// embedded snapshot.
+func WithoutSeed() Option {
+	return func(r *Repository) {
+		r.skipSeed = true
+	}
+}
+
+func New(dbPath string, opts ...Option) (*Repository, error) {
 	connStr := fmt.Sprintf("%s?"+
 		"_pragma=journal_mode(WAL)&"+
 		"_pragma=synchronous(NORMAL)&"+
@@ -63,8 +86,13 @@ func New(dbPath string) (*Repository, error) {
 	writeDB.SetConnMaxIdleTime(30 * time.Minute)
 
 	repo := &Repository{
-		readDB:  readDB,
-		writeDB: writeDB,
+		readDB:            readDB,
+		writeDB:           writeDB,
+		snapshotRetention: defaultSnapshotRetention,
+	}
+
+	for _, opt := range opts {
+		opt(repo)
 	}
 
 	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 5*time.Second)
@@ -74,6 +102,15 @@ func New(dbPath string) (*Repository, error) {
 		return nil, err
 	}
 
+	if !repo.skipSeed {
+		seedCtx, cancelSeed := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancelSeed()
+
+		if err := repo.seed(seedCtx); err != nil {
+			return nil, err
+		}
+	}
+
 	return repo, nil
 }
 
@@ -81,46 +118,85 @@ func (r *Repository) Close() {
 	_ = r.readDB.Close()
 }
 
-func (r *Repository) IsDisposable(ctx context.Context, domain string) (bool, error) {
-	var exists bool
-	domain = strings.TrimSuffix(domain, ".")
+// GetDisposableDomain returns the first of the given domains that is on the
+// disposable list, together with the sources that listed it, or nil.
+func (r *Repository) GetDisposableDomain(ctx context.Context, domains []string) (*emailchecker.DisposableDomain, error) {
+	if len(domains) == 0 {
+		return nil, nil
+	}
 
-	baseDomain := extractBaseDomain(domain)
+	o, err := r.findOverride(ctx, emailchecker.ListDisposable, domains)
+	if err != nil {
+		return nil, err
+	}
 
-	if domain == baseDomain {
-		query := "SELECT EXISTS(SELECT 1 FROM disposable_domains WHERE domain = ?)"
+	if o != nil {
+		return &emailchecker.DisposableDomain{
+			Domain:   o.Domain,
+			Sources:  []string{overrideSource},
+			Override: true,
+			Excluded: o.Action == emailchecker.OverrideExclude,
+		}, nil
+	}
+
+	args := make([]any, 0, len(domains))
+	for _, domain := range domains {
+		args = append(args, strings.TrimSuffix(domain, "."))
+	}
 
-		err := r.readDB.QueryRowContext(ctx, query, domain).Scan(&exists)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	query := fmt.Sprintf("SELECT domain, sources FROM disposable_domains WHERE domain IN (%s)", placeholders)
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query domain: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
 
-		if err != nil && err != sql.ErrNoRows {
-			return false, fmt.Errorf("could not query domain: %w", err)
+	found := make(map[string]string, len(domains))
+	for rows.Next() {
+		var domain, sources string
+		if err := rows.Scan(&domain, &sources); err != nil {
+			return nil, fmt.Errorf("could not scan domain: %w", err)
 		}
 
-		return exists, nil
+		found[domain] = sources
 	}
 
-	query := "SELECT EXISTS(SELECT 1 FROM disposable_domains WHERE domain IN (?, ?))"
-	err := r.readDB.QueryRowContext(ctx, query, domain, baseDomain).Scan(&exists)
-	if err != nil && err != sql.ErrNoRows {
-		return false, fmt.Errorf("could not query domain: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query domain: %w", err)
 	}
 
-	return exists, nil
+	for _, arg := range args {
+		domain := arg.(string)
+
+		sources, ok := found[domain]
+		if !ok {
+			continue
+		}
+
+		ans := emailchecker.DisposableDomain{Domain: domain}
+		if sources != "" {
+			ans.Sources = strings.Split(sources, ",")
+		}
+
+		return &ans, nil
+	}
+
+	return nil, nil
 }
 
-func (r *Repository) UpdateDomains(ctx context.Context, newDomains []string) error {
-	mainTable := "disposable_domains"
-	key := "last_refresh_at"
+func (r *Repository) UpdateDomains(ctx context.Context, newDomains []emailchecker.DisposableDomain, guard *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error) {
+	return r.updateDomains(ctx, emailchecker.ListDisposable, disposableRows(newDomains), guard)
+}
 
-	return r.updateDomains(ctx, updateDomainsParams{
-		Domains:   newDomains,
-		MainTable: mainTable,
-		Key:       key,
-	})
+func (r *Repository) NeedsRefresh(ctx context.Context, maxAge time.Duration) (bool, error) {
+	return r.needsRefresh(ctx, "last_refresh_at", maxAge)
 }
 
-func (r *Repository) NeedsRefresh(ctx context.Context) (bool, error) {
-	return r.needsRefresh(ctx, "last_refresh_at")
+// MarkRefreshed records a refresh that found the disposable list unchanged.
+func (r *Repository) MarkRefreshed(ctx context.Context) error {
+	return r.markRefreshed(ctx, "last_refresh_at")
 }
 
 func (r *Repository) GetDNSRecord(ctx context.Context, domain string) (*emailchecker.DNSRecord, error) {
@@ -154,126 +230,596 @@ func (r *Repository) UpsertDNSRecord(ctx context.Context, domain string, data []
 	return nil
 }
 
-func (r *Repository) IsTop(ctx context.Context, domain string) (bool, error) {
-	var exists bool
-	domain = strings.TrimSuffix(domain, ".")
+func (r *Repository) DeleteDNSRecords(ctx context.Context, domain string, includeSubdomains bool) (int64, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
 
-	query := "SELECT EXISTS(SELECT 1 FROM top_domains WHERE domain = ?)"
+	// Domains are stored lowercase, so the primary key serves the lookup.
+	query := "DELETE FROM dns_records WHERE domain = ?"
+	args := []any{domain}
 
-	err := r.readDB.QueryRowContext(ctx, query, domain).Scan(&exists)
+	if includeSubdomains {
+		query += ` OR domain LIKE ? ESCAPE '\'`
+		args = append(args, "%."+escapeLike(domain))
+	}
 
-	if err != nil && err != sql.ErrNoRows {
-		return false, fmt.Errorf("could not query domain: %w", err)
+	res, err := r.writeDB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("could not delete DNS records for '%s': %w", domain, err)
 	}
 
-	return exists, nil
+	return res.RowsAffected()
 }
 
-func (r *Repository) TopNeedsRefresh(ctx context.Context) (bool, error) {
-	return r.needsRefresh(ctx, "top_domains_refreshed_at")
+func (r *Repository) GetDNSCacheStats(ctx context.Context, staleBefore time.Time) (*emailchecker.DNSCacheStats, error) {
+	var stats emailchecker.DNSCacheStats
+
+	query := "SELECT COUNT(*), COALESCE(SUM(CASE WHEN created_at < ? THEN 1 ELSE 0 END), 0) FROM dns_records"
+	if err := r.readDB.QueryRowContext(ctx, query, staleBefore).Scan(&stats.Records, &stats.Stale); err != nil {
+		return nil, fmt.Errorf("could not count DNS records: %w", err)
+	}
+
+	if stats.Records == 0 {
+		return &stats, nil
+	}
+
+	var oldest, newest time.Time
+
+	query = "SELECT created_at FROM dns_records ORDER BY created_at ASC LIMIT 1"
+	if err := r.readDB.QueryRowContext(ctx, query).Scan(&oldest); err != nil {
+		return nil, fmt.Errorf("could not query oldest DNS record: %w", err)
+	}
+
+	query = "SELECT created_at FROM dns_records ORDER BY created_at DESC LIMIT 1"
+	if err := r.readDB.QueryRowContext(ctx, query).Scan(&newest); err != nil {
+		return nil, fmt.Errorf("could not query newest DNS record: %w", err)
+	}
+
+	stats.Oldest = &oldest
+	stats.Newest = &newest
+
+	return &stats, nil
 }
 
-func (r *Repository) UpdateTopDomains(ctx context.Context, domains []string) error {
-	return r.updateDomains(ctx, updateDomainsParams{
-		Domains:   domains,
-		MainTable: "top_domains",
-		Key:       "top_domains_refreshed_at",
-	})
+// GetTopDomain returns the most specific of the domains that is in the top
+// list, or nil when none is. Domains included by an override have no rank.
+func (r *Repository) GetTopDomain(ctx context.Context, domains []string) (*emailchecker.TopDomain, error) {
+	o, err := r.findOverride(ctx, emailchecker.ListTop, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	if o != nil {
+		if o.Action != emailchecker.OverrideInclude {
+			return nil, nil
+		}
+
+		return &emailchecker.TopDomain{Domain: o.Domain}, nil
+	}
+
+	if len(domains) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(domains))
+	for _, domain := range domains {
+		args = append(args, strings.TrimSuffix(domain, "."))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	query := fmt.Sprintf("SELECT domain, rank FROM top_domains WHERE domain IN (%s)", placeholders)
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query top domain: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	found := make(map[string]emailchecker.TopDomain, len(domains))
+	for rows.Next() {
+		var (
+			top  emailchecker.TopDomain
+			rank sql.NullInt64
+		)
+
+		if err := rows.Scan(&top.Domain, &rank); err != nil {
+			return nil, fmt.Errorf("could not scan top domain: %w", err)
+		}
+
+		top.Rank = int(rank.Int64)
+		found[top.Domain] = top
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query top domain: %w", err)
+	}
+
+	for _, domain := range domains {
+		if top, ok := found[strings.TrimSuffix(domain, ".")]; ok {
+			return &top, nil
+		}
+	}
+
+	return nil, nil
 }
 
-func (r *Repository) IsEducationalDomain(ctx context.Context, domain string) (bool, error) {
-	var exists bool
-	domain = strings.TrimSuffix(domain, ".")
+// TopDomains returns the domains of the top list in rank order, the
+// unranked ones last. Overrides are not included.
+func (r *Repository) TopDomains(ctx context.Context) ([]emailchecker.TopDomain, error) {
+	rows, err := r.readDB.QueryContext(ctx, "SELECT domain, rank FROM top_domains ORDER BY rank IS NULL, rank, domain")
+	if err != nil {
+		return nil, fmt.Errorf("could not query top domains: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var ans []emailchecker.TopDomain
+	for rows.Next() {
+		var (
+			top  emailchecker.TopDomain
+			rank sql.NullInt64
+		)
 
-	query := "SELECT EXISTS(SELECT 1 FROM edu_domains WHERE domain = ?)"
+		if err := rows.Scan(&top.Domain, &rank); err != nil {
+			return nil, fmt.Errorf("could not scan top domain: %w", err)
+		}
 
-	err := r.readDB.QueryRowContext(ctx, query, domain).Scan(&exists)
+		top.Rank = int(rank.Int64)
+		ans = append(ans, top)
+	}
 
-	if err != nil && err != sql.ErrNoRows {
-		return false, fmt.Errorf("could not query domain: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query top domains: %w", err)
 	}
 
-	return exists, nil
+	return ans, nil
 }
 
-func (r *Repository) UpdateEducationalDomains(ctx context.Context, domains []string) error {
-	return r.updateDomains(ctx, updateDomainsParams{
-		Domains:   domains,
-		MainTable: "edu_domains",
-		Key:       "edu_domains_refreshed_at",
-	})
+func (r *Repository) TopNeedsRefresh(ctx context.Context, maxAge time.Duration) (bool, error) {
+	return r.needsRefresh(ctx, "top_domains_refreshed_at", maxAge)
+}
+
+func (r *Repository) MarkTopRefreshed(ctx context.Context) error {
+	return r.markRefreshed(ctx, "top_domains_refreshed_at")
 }
 
-func (r *Repository) NeedsEduRefresh(ctx context.Context) (bool, error) {
-	return r.needsRefresh(ctx, "edu_domains_refreshed_at")
+func (r *Repository) UpdateTopDomains(ctx context.Context, domains []emailchecker.TopDomain, guard *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error) {
+	return r.updateDomains(ctx, emailchecker.ListTop, topDomainRows(domains), guard)
+}
+
+// GetEducationalInstitution returns the institution of the most specific of
+// the domains that is in the educational list, or nil when none is. Domains
+// included by an override have no institution details.
+func (r *Repository) GetEducationalInstitution(ctx context.Context, domains []string) (*emailchecker.EducationalInstitution, error) {
+	o, err := r.findOverride(ctx, emailchecker.ListEducational, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	if o != nil {
+		if o.Action != emailchecker.OverrideInclude {
+			return nil, nil
+		}
+
+		return &emailchecker.EducationalInstitution{Domain: o.Domain}, nil
+	}
+
+	if len(domains) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(domains))
+	for _, domain := range domains {
+		args = append(args, strings.TrimSuffix(domain, "."))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	query := fmt.Sprintf(`SELECT domain, name, country, alpha_two_code, web_pages
+		FROM edu_domains WHERE domain IN (%s)`, placeholders)
+
+	rows, err := r.readDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query educational domain: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	found := make(map[string]emailchecker.EducationalInstitution, len(domains))
+	for rows.Next() {
+		var (
+			e                                     emailchecker.EducationalInstitution
+			name, country, alphaTwoCode, webPages sql.NullString
+		)
+
+		if err := rows.Scan(&e.Domain, &name, &country, &alphaTwoCode, &webPages); err != nil {
+			return nil, fmt.Errorf("could not scan educational domain: %w", err)
+		}
+
+		e.Name, e.Country, e.AlphaTwoCode = name.String, country.String, alphaTwoCode.String
+		e.WebPages = strings.Fields(webPages.String)
+		found[e.Domain] = e
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query educational domain: %w", err)
+	}
+
+	for _, domain := range domains {
+		if e, ok := found[strings.TrimSuffix(domain, ".")]; ok {
+			return &e, nil
+		}
+	}
+
+	return nil, nil
 }
 
-type updateDomainsParams struct {
-	Domains   []string
+func (r *Repository) UpdateEducationalDomains(ctx context.Context, institutions []emailchecker.EducationalInstitution, guard *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error) {
+	return r.updateDomains(ctx, emailchecker.ListEducational, eduRows(institutions), guard)
+}
+
+func (r *Repository) NeedsEduRefresh(ctx context.Context, maxAge time.Duration) (bool, error) {
+	return r.needsRefresh(ctx, "edu_domains_refreshed_at", maxAge)
+}
+
+func (r *Repository) MarkEduRefreshed(ctx context.Context) error {
+	return r.markRefreshed(ctx, "edu_domains_refreshed_at")
+}
+
+type domainRow struct {
+	Domain string
+	// Values holds the values of listTable.Columns, in order.
+	Values []any
+}
+
+func disposableRows(domains []emailchecker.DisposableDomain) []domainRow {
+	rows := make([]domainRow, 0, len(domains))
+	for _, d := range domains {
+		rows = append(rows, domainRow{
+			Domain: d.Domain,
+			Values: []any{strings.Join(d.Sources, ",")},
+		})
+	}
+
+	return rows
+}
+
+func topDomainRows(domains []emailchecker.TopDomain) []domainRow {
+	rows := make([]domainRow, 0, len(domains))
+	for _, d := range domains {
+		var rank any
+		if d.Rank > 0 {
+			rank = d.Rank
+		}
+
+		rows = append(rows, domainRow{Domain: d.Domain, Values: []any{rank}})
+	}
+
+	return rows
+}
+
+func eduRows(institutions []emailchecker.EducationalInstitution) []domainRow {
+	rows := make([]domainRow, 0, len(institutions))
+	for _, e := range institutions {
+		rows = append(rows, domainRow{
+			Domain: e.Domain,
+			Values: []any{e.Name, e.Country, e.AlphaTwoCode, strings.Join(e.WebPages, " ")},
+		})
+	}
+
+	return rows
+}
+
+// listTable describes where a domain list is stored.
+type listTable struct {
 	MainTable string
 	Key       string
+	// Columns lists the columns stored next to domain.
+	Columns []string
+	// CreateTable creates a table with the list schema.
+	CreateTable func(ctx context.Context, tx *sql.Tx, name string) error
+}
+
+func (r *Repository) listTable(list emailchecker.ListName) (listTable, error) {
+	switch list {
+	case emailchecker.ListDisposable:
+		return listTable{
+			MainTable:   "disposable_domains",
+			Key:         "last_refresh_at",
+			Columns:     []string{"sources"},
+			CreateTable: r.createDisposableDomainsTable,
+		}, nil
+	case emailchecker.ListTop:
+		return listTable{
+			MainTable:   "top_domains",
+			Key:         "top_domains_refreshed_at",
+			Columns:     []string{"rank"},
+			CreateTable: r.createTopDomainsTable,
+		}, nil
+	case emailchecker.ListEducational:
+		return listTable{
+			MainTable:   "edu_domains",
+			Key:         "edu_domains_refreshed_at",
+			Columns:     []string{"name", "country", "alpha_two_code", "web_pages"},
+			CreateTable: r.createEduDomainsTable,
+		}, nil
+	case emailchecker.ListParked:
+		return listTable{
+			MainTable:   "parked_entries",
+			Key:         "parked_entries_refreshed_at",
+			Columns:     []string{"kind", "first_ip", "last_ip"},
+			CreateTable: r.createParkedEntriesTable,
+		}, nil
+	default:
+		return listTable{}, fmt.Errorf("unknown list %q", list)
+	}
 }
 
-func (r *Repository) updateDomains(ctx context.Context, params updateDomainsParams) error {
-	mainTable := params.MainTable
-	newTable := fmt.Sprintf("%s_new", mainTable)
-	oldTable := fmt.Sprintf("%s_old", mainTable)
+// updateDomains replaces a list with the given rows after checking them
+// against the guard, and snapshots the previous data when it changes.
+func (r *Repository) updateDomains(ctx context.Context, list emailchecker.ListName, rows []domainRow, guard *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error) {
+	return r.storeDomains(ctx, list, rows, storeOptions{
+		guard:  guard,
+		source: emailchecker.ListDataRemote,
+	})
+}
+
+type storeOptions struct {
+	guard *emailchecker.ListGuard
+	// merge keeps the live domains that are missing from the new rows.
+	merge  bool
+	source emailchecker.ListDataSource
+	// provenance is stored with the data of an imported list.
+	provenance *emailchecker.ListImport
+	// resetValidators clears the validators of the downloads of the list,
+	// once its data no longer comes from them.
+	resetValidators bool
+}
 
-	tx, err := r.writeDB.Begin()
+func (r *Repository) storeDomains(ctx context.Context, list emailchecker.ListName, rows []domainRow, opts storeOptions) (*emailchecker.ListRefreshResult, error) {
+	tbl, err := r.listTable(list)
 	if err != nil {
-		return fmt.Errorf("could not begin transaction: %w", err)
+		return nil, err
+	}
+
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
 	}
 	defer tx.Rollback() //nolint:errcheck
 
-	err = r.createDisposableDomainsTable(ctx, tx, newTable)
+	stageTable, total, err := r.stageDomains(ctx, tx, tbl, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.merge {
+		if total, err = r.stageLiveDomains(ctx, tx, tbl, stageTable, total); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.checkGuard(ctx, tx, tbl, stageTable, total, opts.guard); err != nil {
+		return nil, err
+	}
+
+	changed, err := r.hasChanges(ctx, tx, tbl, stageTable)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		if err := r.createSnapshot(ctx, tx, list, tbl); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := r.applyDomains(ctx, tx, tbl, stageTable)
 	if err != nil {
-		return fmt.Errorf("could not create new table '%s': %w", newTable, err)
+		return nil, err
 	}
 
-	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s (domain) VALUES (?)", newTable))
+	result.Total = total
+
+	err = r.updateRefreshTimestamp(ctx, tx, tbl.Key)
 	if err != nil {
-		return fmt.Errorf("could not prepare insert for new table: %w", err)
+		return nil, err
+	}
+
+	err = r.setDataVersion(ctx, tx, list, opts.source, time.Now().UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.provenance != nil {
+		if err := r.setImport(ctx, tx, opts.provenance); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.resetValidators {
+		if err := r.clearValidators(ctx, tx, list); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit domains of '%s': %w", tbl.MainTable, err)
+	}
+
+	return result, nil
+}
+
+// stageDomains loads the rows into a staging table and returns its name and
+// the number of distinct domains.
+func (r *Repository) stageDomains(ctx context.Context, tx *sql.Tx, tbl listTable, rows []domainRow) (string, int64, error) {
+	stageTable := fmt.Sprintf("%s_new", tbl.MainTable)
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s;", stageTable)); err != nil {
+		return "", 0, fmt.Errorf("could not drop stale table '%s': %w", stageTable, err)
+	}
+
+	if err := tbl.CreateTable(ctx, tx, stageTable); err != nil {
+		return "", 0, fmt.Errorf("could not create new table '%s': %w", stageTable, err)
+	}
+
+	columns := append([]string{"domain"}, tbl.Columns...)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",")
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", stageTable, strings.Join(columns, ", "), placeholders))
+	if err != nil {
+		return "", 0, fmt.Errorf("could not prepare insert for new table: %w", err)
 	}
 	defer stmt.Close() //nolint:errcheck
 
-	seen := make(map[string]struct{}, len(params.Domains))
-	for _, domain := range params.Domains {
-		if domain == "" {
+	seen := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		if row.Domain == "" {
 			continue
 		}
 
-		if _, ok := seen[domain]; ok {
+		if _, ok := seen[row.Domain]; ok {
 			continue
 		}
 
-		seen[domain] = struct{}{}
-		if _, err := stmt.Exec(domain); err != nil {
-			return fmt.Errorf("could not insert domain '%s' into new table: %w", domain, err)
+		seen[row.Domain] = struct{}{}
+		if _, err := stmt.ExecContext(ctx, append([]any{row.Domain}, row.Values...)...); err != nil {
+			return "", 0, fmt.Errorf("could not insert domain '%s' into new table: %w", row.Domain, err)
+		}
+	}
+
+	return stageTable, int64(len(seen)), nil
+}
+
+// stageLiveDomains copies the live domains missing from the staging table
+// into it and returns the new number of domains.
+func (r *Repository) stageLiveDomains(ctx context.Context, tx *sql.Tx, tbl listTable, stageTable string, total int64) (int64, error) {
+	columns := strings.Join(append([]string{"domain"}, tbl.Columns...), ", ")
+	query := fmt.Sprintf("INSERT INTO %[2]s (%[3]s) SELECT %[3]s FROM %[1]s WHERE domain NOT IN (SELECT domain FROM %[2]s);",
+		tbl.MainTable, stageTable, columns)
+
+	added, err := execCount(ctx, tx, query)
+	if err != nil {
+		return 0, fmt.Errorf("could not merge domains of '%s': %w", tbl.MainTable, err)
+	}
+
+	return total + added, nil
+}
+
+func (r *Repository) checkGuard(ctx context.Context, tx *sql.Tx, tbl listTable, stageTable string, total int64, guard *emailchecker.ListGuard) error {
+	if guard == nil {
+		return nil
+	}
+
+	if total < guard.MinRows {
+		return fmt.Errorf("%w: %s would have %d domains, at least %d are required",
+			emailchecker.ErrUnsafeListData, tbl.MainTable, total, guard.MinRows)
+	}
+
+	if guard.MaxShrink > 0 {
+		var current int64
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tbl.MainTable)).Scan(&current); err != nil {
+			return fmt.Errorf("could not count domains of '%s': %w", tbl.MainTable, err)
+		}
+
+		if guard.MaxRows > 0 {
+			current = min(current, guard.MaxRows)
+		}
+
+		if current > 0 && float64(current-total)/float64(current) > guard.MaxShrink {
+			return fmt.Errorf("%w: %s would shrink from %d to %d domains, more than %.0f%%",
+				emailchecker.ErrUnsafeListData, tbl.MainTable, current, total, guard.MaxShrink*100)
+		}
+	}
+
+	for _, sample := range guard.Samples {
+		var exists bool
+
+		query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE domain = ?)", stageTable)
+		if err := tx.QueryRowContext(ctx, query, sample).Scan(&exists); err != nil {
+			return fmt.Errorf("could not check sample '%s': %w", sample, err)
+		}
+
+		if !exists {
+			return fmt.Errorf("%w: %s is missing the sample domain %s",
+				emailchecker.ErrUnsafeListData, tbl.MainTable, sample)
 		}
 	}
-	renameOldCmd := fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", mainTable, oldTable)
-	if _, err := tx.Exec(renameOldCmd); err != nil {
-		return fmt.Errorf("could not rename main table to old: %w", err)
+
+	return nil
+}
+
+func (r *Repository) hasChanges(ctx context.Context, tx *sql.Tx, tbl listTable, stageTable string) (bool, error) {
+	columns := strings.Join(append([]string{"domain"}, tbl.Columns...), ", ")
+	query := fmt.Sprintf(`SELECT
+		EXISTS(SELECT %[1]s FROM %[2]s EXCEPT SELECT %[1]s FROM %[3]s) OR
+		EXISTS(SELECT %[1]s FROM %[3]s EXCEPT SELECT %[1]s FROM %[2]s)`,
+		columns, tbl.MainTable, stageTable)
+
+	var changed bool
+	if err := tx.QueryRowContext(ctx, query).Scan(&changed); err != nil {
+		return false, fmt.Errorf("could not compare domains of '%s': %w", tbl.MainTable, err)
+	}
+
+	return changed, nil
+}
+
+// applyDomains applies the difference between the staging table and the
+// live table, so that unchanged rows are left untouched, and drops the
+// staging table.
+func (r *Repository) applyDomains(ctx context.Context, tx *sql.Tx, tbl listTable, stageTable string) (*emailchecker.ListRefreshResult, error) {
+	var (
+		result emailchecker.ListRefreshResult
+		err    error
+	)
+
+	mainTable := tbl.MainTable
+	columnList := strings.Join(append([]string{"domain"}, tbl.Columns...), ", ")
+
+	removeCmd := fmt.Sprintf("DELETE FROM %s WHERE domain NOT IN (SELECT domain FROM %s);", mainTable, stageTable)
+	if result.Removed, err = execCount(ctx, tx, removeCmd); err != nil {
+		return nil, fmt.Errorf("could not remove domains from '%s': %w", mainTable, err)
+	}
+
+	if len(tbl.Columns) > 0 {
+		var (
+			assignments []string
+			changed     []string
+		)
+
+		for _, c := range tbl.Columns {
+			assignments = append(assignments, fmt.Sprintf("%[1]s = (SELECT s.%[1]s FROM %[2]s s WHERE s.domain = %[3]s.domain)", c, stageTable, mainTable))
+			changed = append(changed, fmt.Sprintf("s.%[1]s IS NOT %[2]s.%[1]s", c, mainTable))
+		}
+
+		updateCmd := fmt.Sprintf("UPDATE %s SET %s WHERE EXISTS (SELECT 1 FROM %s s WHERE s.domain = %s.domain AND (%s));",
+			mainTable, strings.Join(assignments, ", "), stageTable, mainTable, strings.Join(changed, " OR "))
+		if result.Updated, err = execCount(ctx, tx, updateCmd); err != nil {
+			return nil, fmt.Errorf("could not update domains of '%s': %w", mainTable, err)
+		}
 	}
 
-	renameNewCmd := fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", newTable, mainTable)
-	if _, err := tx.Exec(renameNewCmd); err != nil {
-		return fmt.Errorf("could not rename new table to main: %w", err)
+	addCmd := fmt.Sprintf("INSERT INTO %[1]s (%[3]s) SELECT %[3]s FROM %[2]s WHERE domain NOT IN (SELECT domain FROM %[1]s);",
+		mainTable, stageTable, columnList)
+	if result.Added, err = execCount(ctx, tx, addCmd); err != nil {
+		return nil, fmt.Errorf("could not add domains to '%s': %w", mainTable, err)
 	}
-	dropOldCmd := fmt.Sprintf("DROP TABLE %s;", oldTable)
-	if _, err := tx.Exec(dropOldCmd); err != nil {
-		return fmt.Errorf("could not drop old table: %w", err)
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s;", stageTable)); err != nil {
+		return nil, fmt.Errorf("could not drop new table: %w", err)
 	}
 
-	err = r.updateRefreshTimestamp(ctx, tx, params.Key)
+	return &result, nil
+}
+
+func execCount(ctx context.Context, tx *sql.Tx, query string) (int64, error) {
+	res, err := tx.ExecContext(ctx, query)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return tx.Commit()
+	return res.RowsAffected()
 }
 
-func (r *Repository) needsRefresh(ctx context.Context, key string) (bool, error) {
+// needsRefresh reports whether the list refreshed at the time stored under key
+// is older than maxAge.
+func (r *Repository) needsRefresh(ctx context.Context, key string, maxAge time.Duration) (bool, error) {
 	var lastRefreshStr string
 
 	query := "SELECT value FROM app_metadata WHERE key = ?"
@@ -292,12 +838,21 @@ func (r *Repository) needsRefresh(ctx context.Context, key string) (bool, error)
 		return true, fmt.Errorf("could not parse last refresh time '%s': %w", lastRefreshStr, err)
 	}
 
-	durationSinceRefresh := time.Since(lastRefreshTime)
-	if durationSinceRefresh > 12*time.Hour {
-		return true, nil
+	return time.Since(lastRefreshTime) >= maxAge, nil
+}
+
+func (r *Repository) markRefreshed(ctx context.Context, key string) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := r.updateRefreshTimestamp(ctx, tx, key); err != nil {
+		return err
 	}
 
-	return false, nil
+	return tx.Commit()
 }
 
 func (r *Repository) updateRefreshTimestamp(ctx context.Context, tx *sql.Tx, key string) error {
@@ -333,28 +888,76 @@ func (r *Repository) init(ctx context.Context) error {
 		return fmt.Errorf("could not create disposable_domains table: %w", err)
 	}
 
+	err = r.addColumnIfMissing(ctx, tx, "disposable_domains", "sources", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+
 	err = r.createDNSRecordsTable(ctx, tx)
 	if err != nil {
 		return fmt.Errorf("could not create dns_records table: %w", err)
 	}
 
-	err = r.createTopDomainsTable(ctx, tx)
+	err = r.createTopDomainsTable(ctx, tx, "top_domains")
 	if err != nil {
 		return fmt.Errorf("could not create top_domains table: %w", err)
 	}
 
-	err = r.createEduDomainsTable(ctx, tx)
+	err = r.addColumnIfMissing(ctx, tx, "top_domains", "rank", "INTEGER")
+	if err != nil {
+		return err
+	}
+
+	err = r.createEduDomainsTable(ctx, tx, "edu_domains")
 	if err != nil {
 		return fmt.Errorf("could not create edu_domains table: %w", err)
 	}
 
+	for _, column := range []string{"name", "country", "alpha_two_code", "web_pages"} {
+		err = r.addColumnIfMissing(ctx, tx, "edu_domains", column, "TEXT")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.createOverridesTable(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not create domain_overrides table: %w", err)
+	}
+
+	err = r.createDomainRegistrationsTable(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not create domain_registrations table: %w", err)
+	}
+
+	err = r.createParkedEntriesTable(ctx, tx, "parked_entries")
+	if err != nil {
+		return err
+	}
+
+	err = r.createInfrastructureTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = r.createSnapshotsTable(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = r.createProvidersTable(ctx, tx)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 func (r *Repository) createDisposableDomainsTable(ctx context.Context, tx *sql.Tx, name string) error {
 	schema := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
-			domain TEXT PRIMARY KEY NOT NULL
+			domain TEXT PRIMARY KEY NOT NULL,
+			sources TEXT NOT NULL DEFAULT ''
 	);`, name)
 
 	_, err := tx.ExecContext(ctx, schema)
@@ -365,6 +968,27 @@ func (r *Repository) createDisposableDomainsTable(ctx context.Context, tx *sql.T
 	return nil
 }
 
+// addColumnIfMissing upgrades tables created by older versions.
+func (r *Repository) addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	var exists bool
+
+	query := "SELECT EXISTS(SELECT 1 FROM pragma_table_info(?) WHERE name = ?)"
+	if err := tx.QueryRowContext(ctx, query, table, column).Scan(&exists); err != nil {
+		return fmt.Errorf("could not inspect table '%s': %w", table, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	alter := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := tx.ExecContext(ctx, alter); err != nil {
+		return fmt.Errorf("could not add column '%s' to '%s': %w", column, table, err)
+	}
+
+	return nil
+}
+
 func (r *Repository) createMetadataTable(ctx context.Context, tx *sql.Tx) error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS app_metadata (
@@ -392,11 +1016,13 @@ func (r *Repository) createDNSRecordsTable(ctx context.Context, tx *sql.Tx) erro
 	return nil
 }
 
-func (r *Repository) createTopDomainsTable(ctx context.Context, tx *sql.Tx) error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS top_domains (
-		domain TEXT PRIMARY KEY NOT NULL
-	);`
+func (r *Repository) createTopDomainsTable(ctx context.Context, tx *sql.Tx, name string) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			domain TEXT PRIMARY KEY NOT NULL,
+			rank INTEGER
+	);`, name)
+
 	_, err := tx.ExecContext(ctx, schema)
 	if err != nil {
 		return fmt.Errorf("could not create top_domains table: %w", err)
@@ -404,11 +1030,16 @@ func (r *Repository) createTopDomainsTable(ctx context.Context, tx *sql.Tx) erro
 	return nil
 }
 
-func (r *Repository) createEduDomainsTable(ctx context.Context, tx *sql.Tx) error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS edu_domains (
-		domain TEXT PRIMARY KEY NOT NULL
-	);`
+func (r *Repository) createEduDomainsTable(ctx context.Context, tx *sql.Tx, name string) error {
+	schema := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			domain TEXT PRIMARY KEY NOT NULL,
+			name TEXT,
+			country TEXT,
+			alpha_two_code TEXT,
+			web_pages TEXT
+	);`, name)
+
 	_, err := tx.ExecContext(ctx, schema)
 	if err != nil {
 		return fmt.Errorf("could not create edu_domains table: %w", err)
@@ -416,13 +1047,10 @@ func (r *Repository) createEduDomainsTable(ctx context.Context, tx *sql.Tx) erro
 	return nil
 }
 
-func extractBaseDomain(domain string) string {
-	baseDomain, err := publicsuffix.EffectiveTLDPlusOne(domain)
-	if err != nil {
-		return domain
-	}
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
-	return baseDomain
+	return replacer.Replace(s)
 }
 
 func testConnection(ctx context.Context, conn *sql.DB) error {
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"emailchecker"
+)
+
+const refreshStatusKeyPrefix = "refresh_status:"
+
+// RecordRefresh records a refresh attempt of a list, failed when refreshErr
+// is not nil.
+func (r *Repository) RecordRefresh(ctx context.Context, list emailchecker.ListName, refreshErr error) error {
+	status, err := r.RefreshStatus(ctx, list)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	status.LastAttemptAt = &now
+
+	if refreshErr != nil {
+		status.LastFailureAt = &now
+		status.LastError = refreshErr.Error()
+	} else {
+		status.LastSuccessAt = &now
+		status.LastError = ""
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("could not encode refresh status of '%s': %w", list, err)
+	}
+
+	query := `
+	INSERT INTO app_metadata (key, value)
+	VALUES (?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value;
+	`
+
+	if _, err := r.writeDB.ExecContext(ctx, query, refreshStatusKeyPrefix+string(list), string(data)); err != nil {
+		return fmt.Errorf("could not store refresh status of '%s': %w", list, err)
+	}
+
+	return nil
+}
+
+// RefreshStatus returns the recorded refresh attempts of a list. Lists that
+// were never refreshed have an empty status.
+func (r *Repository) RefreshStatus(ctx context.Context, list emailchecker.ListName) (*emailchecker.RefreshStatus, error) {
+	var (
+		raw    string
+		status emailchecker.RefreshStatus
+	)
+
+	query := "SELECT value FROM app_metadata WHERE key = ?"
+
+	err := r.readDB.QueryRowContext(ctx, query, refreshStatusKeyPrefix+string(list)).Scan(&raw)
+	switch {
+	case err == sql.ErrNoRows:
+		return &status, nil
+	case err != nil:
+		return nil, fmt.Errorf("could not query refresh status of '%s': %w", list, err)
+	}
+
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		return nil, fmt.Errorf("could not decode refresh status of '%s': %w", list, err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+)
+
+const validatorsKeyPrefix = "validators:"
+
+// listValidators stores the HTTP cache validators of the downloads of a list
+// under a key prefix of its own, so that they can be cleared with the list.
+type listValidators struct {
+	repo *Repository
+	list emailchecker.ListName
+}
+
+// Validators returns the validator store of the downloads of a list. The
+// validators are cleared when the list data is replaced by other means than
+// a download, so that the next refresh downloads the list in full instead of
+// being answered not modified.
+func (r *Repository) Validators(list emailchecker.ListName) httpext.ValidatorStore {
+	return listValidators{repo: r, list: list}
+}
+
+// GetValidators returns the validators stored for key, which is usually the
+// URL of a list. Zero validators are returned when none exist.
+func (s listValidators) GetValidators(ctx context.Context, key string) (httpext.Validators, error) {
+	var (
+		v   httpext.Validators
+		raw string
+	)
+
+	query := "SELECT value FROM app_metadata WHERE key = ?"
+
+	err := s.repo.readDB.QueryRowContext(ctx, query, validatorsKey(s.list)+key).Scan(&raw)
+	switch {
+	case err == sql.ErrNoRows:
+		return v, nil
+	case err != nil:
+		return v, fmt.Errorf("could not query validators of '%s': %w", key, err)
+	}
+
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return httpext.Validators{}, fmt.Errorf("could not decode validators of '%s': %w", key, err)
+	}
+
+	return v, nil
+}
+
+func (s listValidators) SetValidators(ctx context.Context, key string, v httpext.Validators) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not encode validators: %w", err)
+	}
+
+	query := `
+	INSERT INTO app_metadata (key, value)
+	VALUES (?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value;
+	`
+
+	if _, err := s.repo.writeDB.ExecContext(ctx, query, validatorsKey(s.list)+key, string(raw)); err != nil {
+		return fmt.Errorf("could not store validators of '%s': %w", key, err)
+	}
+
+	return nil
+}
+
+// clearValidators deletes the validators of the downloads of a list.
+func (r *Repository) clearValidators(ctx context.Context, tx *sql.Tx, list emailchecker.ListName) error {
+	prefix := validatorsKey(list)
+
+	query := "DELETE FROM app_metadata WHERE substr(key, 1, ?) = ?"
+	if _, err := tx.ExecContext(ctx, query, len(prefix), prefix); err != nil {
+		return fmt.Errorf("could not clear validators of '%s': %w", list, err)
+	}
+
+	return nil
+}
+
+func validatorsKey(list emailchecker.ListName) string {
+	return validatorsKeyPrefix + string(list) + ":"
+}
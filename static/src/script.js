@@ -162,7 +162,7 @@ function emailChecker() {
             
             const checks = [];
             if (this.result.disposable?.checked) {
-                checks.push(`Disposable: ${this.result.disposable.value ? '❌' : '✅'}`);
+                checks.push(`Disposable: ${this.result.disposable.value?.disposable ? '❌' : '✅'}`);
             }
             if (this.result.dns?.checked) {
                 checks.push(`DNS: ${this.result.dns.value?.has_mx ? '✅' : '❌'}`);
@@ -170,8 +170,12 @@ function emailChecker() {
             if (this.result.well_known?.checked) {
                 checks.push(`Known provider: ${this.result.well_known.value ? '✅' : '❓'}`);
             }
-            if (this.result.educational?.checked && this.result.educational.value) {
-                checks.push(`Educational: ✅`);
+            if (this.result.educational?.checked && this.result.educational.value?.educational) {
+                const institution = this.result.educational.value.name;
+                checks.push(`Educational: ✅${institution ? ` (${institution})` : ''}`);
+            }
+            if (this.result.provider_type) {
+                checks.push(`Provider: ${this.result.provider_type.replace('_', ' ')}`);
             }
             
             return checks.length ? checks.join(' • ') : '';
@@ -0,0 +1,74 @@
+package emailchecker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type dnsTraceKey struct{}
+
+// DNSTrace records how a DNS validation result was obtained. It is only
+// collected when EmailCheckParams.Debug is set.
+type DNSTrace struct {
+	mu sync.Mutex
+
+	CacheHit bool            `json:"cache_hit"`
+	CachedAt *time.Time      `json:"cached_at,omitempty"`
+	Queries  []DNSQueryTrace `json:"queries"`
+}
+
+type DNSQueryTrace struct {
+	Name     string           `json:"name"`
+	Type     string           `json:"type"`
+	Upstream string           `json:"upstream"`
+	RCode    int              `json:"rcode"`
+	Answers  []DNSAnswerTrace `json:"answers"`
+	Latency  time.Duration    `json:"latency"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type DNSAnswerTrace struct {
+	Name string `json:"name"`
+	Type int    `json:"type"`
+	TTL  int    `json:"ttl"`
+	Data string `json:"data"`
+}
+
+// WithDNSTrace returns a context that collects DNS queries into the returned trace.
+func WithDNSTrace(ctx context.Context) (context.Context, *DNSTrace) {
+	trace := &DNSTrace{Queries: []DNSQueryTrace{}}
+
+	return context.WithValue(ctx, dnsTraceKey{}, trace), trace
+}
+
+// DNSTraceFromContext returns the trace attached to ctx, or nil if the
+// caller did not ask for one.
+func DNSTraceFromContext(ctx context.Context) *DNSTrace {
+	trace, _ := ctx.Value(dnsTraceKey{}).(*DNSTrace)
+
+	return trace
+}
+
+func (t *DNSTrace) AddQuery(q DNSQueryTrace) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Queries = append(t.Queries, q)
+}
+
+func (t *DNSTrace) SetCacheHit(cachedAt time.Time) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.CacheHit = true
+	t.CachedAt = &cachedAt
+}
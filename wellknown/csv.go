@@ -0,0 +1,109 @@
+package wellknown
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+)
+
+// CSVFetcher loads a top sites list in the Tranco CSV format, rank,domain
+// per line, from an HTTP(S) URL or a local file. Lines without a rank are
+// ranked by their position.
+type CSVFetcher struct {
+	conditional *httpext.ConditionalClient
+	location    string
+	size        int
+}
+
+func NewCSVFetcher(netClient *http.Client, store httpext.ValidatorStore, location string, size int) *CSVFetcher {
+	return &CSVFetcher{
+		conditional: httpext.NewConditionalClient(netClient, store),
+		location:    location,
+		size:        size,
+	}
+}
+
+// GetTopList returns httpext.ErrNotModified when a URL did not change. Local
+// files are always read.
+func (f *CSVFetcher) GetTopList(ctx context.Context) ([]emailchecker.TopDomain, error) {
+	f.conditional.Reset()
+
+	if !strings.HasPrefix(f.location, "http://") && !strings.HasPrefix(f.location, "https://") {
+		file, err := os.Open(f.location)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close() //nolint:errcheck
+
+		return readTopList(file, f.size)
+	}
+
+	resp, err := f.conditional.Get(ctx, f.location, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	return readTopList(resp.Body, f.size)
+}
+
+func (f *CSVFetcher) Commit(ctx context.Context) error {
+	return f.conditional.Commit(ctx)
+}
+
+func (f *CSVFetcher) Sources() []emailchecker.DataSource {
+	return []emailchecker.DataSource{{Name: "top-list", Location: f.location}}
+}
+
+// readTopList reads up to size domains of a rank,domain list.
+func readTopList(r io.Reader, size int) ([]emailchecker.TopDomain, error) {
+	domainList := make([]emailchecker.TopDomain, 0, min(size, DefaultListSize))
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if len(domainList) >= size {
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		top := emailchecker.TopDomain{Domain: line, Rank: len(domainList) + 1}
+
+		if rank, domain, ok := strings.Cut(line, ","); ok {
+			n, err := strconv.Atoi(rank)
+			if err != nil {
+				continue
+			}
+
+			top = emailchecker.TopDomain{Domain: domain, Rank: n}
+		}
+
+		top.Domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(top.Domain), "."))
+		if top.Domain == "" {
+			continue
+		}
+
+		domainList = append(domainList, top)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading top list: %w", err)
+	}
+
+	return domainList, nil
+}
@@ -0,0 +1,111 @@
+package wellknown_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+	"emailchecker/wellknown"
+)
+
+// memValidators keeps validators in memory.
+type memValidators struct {
+	mu sync.Mutex
+	v  map[string]httpext.Validators
+}
+
+func (m *memValidators) GetValidators(_ context.Context, key string) (httpext.Validators, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.v[key], nil
+}
+
+func (m *memValidators) SetValidators(_ context.Context, key string, v httpext.Validators) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.v == nil {
+		m.v = make(map[string]httpext.Validators)
+	}
+
+	m.v[key] = v
+
+	return nil
+}
+
+func TestCSVFetcher_GetTopList(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		size  int
+		want  []emailchecker.TopDomain
+	}{
+		{
+			name:  "Ranked",
+			input: "1,google.com\n2,facebook.com\n",
+			size:  10,
+			want:  []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "facebook.com", Rank: 2}},
+		},
+		{
+			name:  "Unranked lines ranked by position",
+			input: "# comment\ngoogle.com\n\nFacebook.COM.\n",
+			size:  10,
+			want:  []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "facebook.com", Rank: 2}},
+		},
+		{
+			name:  "Header and invalid ranks skipped",
+			input: "rank,domain\n1,google.com\nx,bad.example\n3, amazon.com \n",
+			size:  10,
+			want:  []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "amazon.com", Rank: 3}},
+		},
+		{
+			name:  "Capped at size",
+			input: "1,google.com\n2,facebook.com\n3,amazon.com\n",
+			size:  2,
+			want:  []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "facebook.com", Rank: 2}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "top.csv")
+			require.NoError(t, os.WriteFile(path, []byte(tc.input), 0o600))
+
+			f := wellknown.NewCSVFetcher(nil, &memValidators{}, path, tc.size)
+
+			got, err := f.GetTopList(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGuards(t *testing.T) {
+	assert.Equal(t, emailchecker.ListGuard{
+		MinRows:   100000,
+		MaxShrink: 0.3,
+		MaxRows:   1000000,
+		Samples:   []string{"google.com"},
+	}, wellknown.DefaultGuard)
+
+	assert.Equal(t, emailchecker.ListGuard{
+		MinRows:   1000,
+		MaxShrink: 0.3,
+		MaxRows:   10000,
+		Samples:   []string{"google.com"},
+	}, wellknown.TrancoGuard(10000))
+
+	// Another source may rank other sites and hold fewer of them.
+	assert.Equal(t, emailchecker.ListGuard{
+		MaxShrink: 0.3,
+		MaxRows:   10000,
+	}, wellknown.CSVGuard(10000))
+}
@@ -1,39 +1,135 @@
 package wellknown
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"time"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
+	"emailchecker/pkg/log"
+)
+
+const (
+	// DefaultListSize is the number of domains of the full Tranco list.
+	DefaultListSize = 1000000
+	// DefaultFallbackDays is how many days before yesterday are tried when
+	// the list of yesterday is not published yet.
+	DefaultFallbackDays = 7
 )
 
+// trancoListIDKey stores the ID and size of the last imported list as its
+// entity tag. Tranco lists are immutable, so an unchanged ID means an
+// unchanged list.
+const trancoListIDKey = "tranco-list-id"
+
+// errListUnavailable is returned when Tranco has no list for a date.
+var errListUnavailable = errors.New("tranco list unavailable")
+
+type TrancoOption func(*Tranco)
+
+// WithListSize sets how many top domains are downloaded.
+func WithListSize(size int) TrancoOption {
+	return func(t *Tranco) {
+		t.size = size
+	}
+}
+
+// WithFallbackDays sets how many earlier days are tried when the list of
+// yesterday is unavailable.
+func WithFallbackDays(days int) TrancoOption {
+	return func(t *Tranco) {
+		t.fallbackDays = days
+	}
+}
+
 type Tranco struct {
-	client *http.Client
+	client       *http.Client
+	conditional  *httpext.ConditionalClient
+	size         int
+	fallbackDays int
 }
 
-func NewTranco(netClient *http.Client) *Tranco {
-	return &Tranco{
-		client: netClient,
+func NewTranco(netClient *http.Client, store httpext.ValidatorStore, opts ...TrancoOption) *Tranco {
+	ans := Tranco{
+		client:       netClient,
+		conditional:  httpext.NewConditionalClient(netClient, store),
+		size:         DefaultListSize,
+		fallbackDays: DefaultFallbackDays,
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
 	}
+
+	return &ans
 }
 
-func (t *Tranco) GetTopList(ctx context.Context) ([]string, error) {
-	yesteday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+// GetTopList downloads the latest published list, starting from yesterday
+// and going back up to the fallback days.
+func (t *Tranco) GetTopList(ctx context.Context) ([]emailchecker.TopDomain, error) {
+	t.conditional.Reset()
 
-	id, err := t.getTrancoListID(ctx, yesteday)
+	id, err := t.latestListID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	tag := fmt.Sprintf("%s/%d", id, t.size)
+
+	last, err := t.conditional.Stored(ctx, trancoListIDKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if last.ETag == tag {
+		return nil, httpext.ErrNotModified
+	}
+
+	t.conditional.Stage(trancoListIDKey, httpext.Validators{ETag: tag})
+
 	return t.fetchTrancoList(ctx, id)
 }
 
+func (t *Tranco) Commit(ctx context.Context) error {
+	return t.conditional.Commit(ctx)
+}
+
+func (t *Tranco) Sources() []emailchecker.DataSource {
+	return []emailchecker.DataSource{{Name: "tranco", Location: "https://tranco-list.eu"}}
+}
+
+// latestListID only falls back on days without a list. Any other error is
+// returned right away, since earlier days would fail the same way.
+func (t *Tranco) latestListID(ctx context.Context) (string, error) {
+	var lastErr error
+
+	for days := 1; days <= t.fallbackDays+1; days++ {
+		date := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+
+		id, err := t.getTrancoListID(ctx, date)
+		if err == nil {
+			return id, nil
+		}
+
+		if !errors.Is(err, errListUnavailable) {
+			return "", err
+		}
+
+		log.Debug(ctx, "Tranco list unavailable, trying the day before", "date", date, "error", err.Error())
+
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("no Tranco list in the last %d days: %w", t.fallbackDays+1, lastErr)
+}
+
 func (t *Tranco) getTrancoListID(ctx context.Context, date string) (string, error) {
 	urlObject := url.URL{
 		Scheme: "https",
@@ -61,7 +157,7 @@ func (t *Tranco) getTrancoListID(ctx context.Context, date string) (string, erro
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", fmt.Errorf("%w: unexpected status code: %d", errListUnavailable, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -70,25 +166,20 @@ func (t *Tranco) getTrancoListID(ctx context.Context, date string) (string, erro
 	}
 
 	if bytes.Equal(body, []byte("null")) {
-		return "", fmt.Errorf("no Tranco list ID found for date: %s", date)
+		return "", fmt.Errorf("%w: no Tranco list ID found for date: %s", errListUnavailable, date)
 	}
 
 	if bytes.Equal(body, []byte("500 Internal Server Error")) {
-		return "", fmt.Errorf("Tranco server error for date: %s", date)
+		return "", fmt.Errorf("%w: Tranco server error for date: %s", errListUnavailable, date)
 	}
 
 	return string(body), nil
 }
 
-func (t *Tranco) fetchTrancoList(ctx context.Context, listID string) ([]string, error) {
-	u := fmt.Sprintf("https://tranco-list.eu/download/%s/1000000", listID)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request for Tranco list: %w", err)
-	}
+func (t *Tranco) fetchTrancoList(ctx context.Context, listID string) ([]emailchecker.TopDomain, error) {
+	u := fmt.Sprintf("https://tranco-list.eu/download/%s/%d", listID, t.size)
 
-	resp, err := t.client.Do(req)
+	resp, err := t.conditional.Get(ctx, u, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Tranco list: %w", err)
 	}
@@ -98,28 +189,5 @@ func (t *Tranco) fetchTrancoList(ctx context.Context, listID string) ([]string,
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code from Tranco: %d", resp.StatusCode)
-	}
-
-	domainList := make([]string, 0, 1000000)
-	scanner := bufio.NewScanner(resp.Body)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
-		idx := strings.Index(line, ",")
-		if idx < 0 || idx >= len(line)-1 {
-			continue
-		}
-
-		domain := line[idx+1:]
-
-		domainList = append(domainList, domain)
-	}
-
-	return domainList, nil
+	return readTopList(resp.Body, t.size)
 }
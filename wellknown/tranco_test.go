@@ -0,0 +1,96 @@
+package wellknown_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emailchecker"
+	"emailchecker/wellknown"
+)
+
+// trancoServer answers list ID requests from ids, keyed by days before
+// today, and downloads of any list with the same CSV.
+type trancoServer struct {
+	ids map[int]string
+	err error
+
+	mu    sync.Mutex
+	dates []string
+}
+
+func (s *trancoServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := "null"
+
+	switch {
+	case req.URL.Path == "/daily_list_id":
+		date := req.URL.Query().Get("date")
+
+		s.mu.Lock()
+		s.dates = append(s.dates, date)
+		s.mu.Unlock()
+
+		if s.err != nil {
+			return nil, s.err
+		}
+
+		for days, id := range s.ids {
+			if daysAgo(days) == date {
+				body = id
+			}
+		}
+	case strings.HasPrefix(req.URL.Path, "/download/"):
+		body = "1,google.com\n2,facebook.com\n"
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func daysAgo(days int) string {
+	return time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+}
+
+func newTranco(s *trancoServer, fallbackDays int) *wellknown.Tranco {
+	return wellknown.NewTranco(&http.Client{Transport: s}, &memValidators{},
+		wellknown.WithListSize(2), wellknown.WithFallbackDays(fallbackDays))
+}
+
+func TestTranco_FallsBackToEarlierLists(t *testing.T) {
+	s := &trancoServer{ids: map[int]string{3: "ABCD"}}
+
+	got, err := newTranco(s, 7).GetTopList(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []emailchecker.TopDomain{{Domain: "google.com", Rank: 1}, {Domain: "facebook.com", Rank: 2}}, got)
+	assert.Equal(t, []string{daysAgo(1), daysAgo(2), daysAgo(3)}, s.dates)
+}
+
+func TestTranco_NoListWithinFallbackDays(t *testing.T) {
+	s := &trancoServer{ids: map[int]string{5: "ABCD"}}
+
+	_, err := newTranco(s, 2).GetTopList(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{daysAgo(1), daysAgo(2), daysAgo(3)}, s.dates)
+}
+
+func TestTranco_DoesNotFallBackOnNetworkErrors(t *testing.T) {
+	s := &trancoServer{err: errors.New("connection refused")}
+
+	_, err := newTranco(s, 7).GetTopList(context.Background())
+	require.ErrorContains(t, err, "connection refused")
+
+	assert.Equal(t, []string{daysAgo(1)}, s.dates)
+}
@@ -2,64 +2,184 @@ package wellknown
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+
+	"emailchecker"
+	"emailchecker/pkg/httpext"
 )
 
 type repo interface {
-	IsTop(context.Context, string) (bool, error)
-	TopNeedsRefresh(context.Context) (bool, error)
-	UpdateTopDomains(context.Context, []string) error
+	GetTopDomain(context.Context, []string) (*emailchecker.TopDomain, error)
+	TopDomains(context.Context) ([]emailchecker.TopDomain, error)
+	ListVersion(context.Context, emailchecker.ListName) (*emailchecker.ListVersion, error)
+	TopNeedsRefresh(context.Context, time.Duration) (bool, error)
+	UpdateTopDomains(context.Context, []emailchecker.TopDomain, *emailchecker.ListGuard) (*emailchecker.ListRefreshResult, error)
+	MarkTopRefreshed(context.Context) error
+	RecordRefresh(context.Context, emailchecker.ListName, error) error
+}
+
+// Fetcher downloads the top sites list. Tranco and CSVFetcher implement it.
+type Fetcher interface {
+	// GetTopList returns httpext.ErrNotModified when the list did not
+	// change since the last committed fetch.
+	GetTopList(ctx context.Context) ([]emailchecker.TopDomain, error)
+	// Commit persists the cache validators of the last fetch.
+	Commit(ctx context.Context) error
+	// Sources returns the locations the list is downloaded from.
+	Sources() []emailchecker.DataSource
+}
+
+// DefaultGuard expects a list of the size of the Tranco top million.
+var DefaultGuard = TrancoGuard(DefaultListSize)
+
+// TrancoGuard returns the guard of the first size domains of Tranco, which
+// always rank google.com.
+func TrancoGuard(size int) emailchecker.ListGuard {
+	guard := CSVGuard(size)
+	guard.MinRows = int64(size / 10)
+	guard.Samples = []string{"google.com"}
+
+	return guard
 }
 
-type fetcher interface {
-	GetTopList(ctx context.Context) ([]string, error)
+// CSVGuard returns the guard of the first size domains of another list. Its
+// length and content are unknown, so it is only guarded against shrinking.
+func CSVGuard(size int) emailchecker.ListGuard {
+	return emailchecker.ListGuard{
+		MaxShrink: 0.3,
+		MaxRows:   int64(size),
+	}
+}
+
+type Option func(*WellKnownDomainChecker)
+
+// WithMatchMode sets which names of a domain are matched against the list.
+func WithMatchMode(mode emailchecker.MatchMode) Option {
+	return func(w *WellKnownDomainChecker) {
+		w.matchMode = mode
+	}
+}
+
+// WithGuard replaces DefaultGuard.
+func WithGuard(guard emailchecker.ListGuard) Option {
+	return func(w *WellKnownDomainChecker) {
+		w.guard = guard
+	}
 }
 
 type WellKnownDomainChecker struct {
-	repo    repo
-	fetcher fetcher
+	repo      repo
+	fetcher   Fetcher
+	matchMode emailchecker.MatchMode
+	guard     emailchecker.ListGuard
 }
 
-func New(repo repo, fetcher fetcher) (*WellKnownDomainChecker, error) {
+// New creates a checker that serves the stored list. The list is downloaded
+// by UpdateWellKnownList.
+func New(repo repo, fetcher Fetcher, opts ...Option) (*WellKnownDomainChecker, error) {
 	ans := WellKnownDomainChecker{
-		repo:    repo,
-		fetcher: fetcher,
+		repo:      repo,
+		fetcher:   fetcher,
+		matchMode: emailchecker.MatchSuffix,
+		guard:     DefaultGuard,
 	}
 
-	if err := ans.UpdateWellKnownList(context.Background()); err != nil {
-		return nil, fmt.Errorf("could not update well-known list: %w", err)
+	for _, opt := range opts {
+		opt(&ans)
 	}
 
 	return &ans, nil
 }
 
-func (w *WellKnownDomainChecker) IsWellKnown(ctx context.Context, domain string) (bool, error) {
-	return w.repo.IsTop(ctx, domain)
+func (w *WellKnownDomainChecker) IsWellKnown(ctx context.Context, domain emailchecker.Domain) (bool, int, error) {
+	top, err := w.repo.GetTopDomain(ctx, domain.Candidates(w.matchMode))
+	if err != nil || top == nil {
+		return false, 0, err
+	}
+
+	return true, top.Rank, nil
+}
+
+// Domains returns the domains of the stored list in rank order, the unranked
+// ones last. Domains included by an override are not returned.
+func (w *WellKnownDomainChecker) Domains(ctx context.Context) ([]emailchecker.TopDomain, error) {
+	return w.repo.TopDomains(ctx)
 }
 
-func (w *WellKnownDomainChecker) UpdateWellKnownList(ctx context.Context) error {
-	needsRefresh, err := w.repo.TopNeedsRefresh(ctx)
+// Version returns a value that changes whenever the stored list is
+// refreshed, imported, rolled back or seeded.
+func (w *WellKnownDomainChecker) Version(ctx context.Context) (string, error) {
+	v, err := w.repo.ListVersion(ctx, emailchecker.ListTop)
 	if err != nil {
-		return fmt.Errorf("could not check if top domains need refresh: %w", err)
+		return "", err
+	}
+
+	var refreshedAt string
+	if v.RefreshedAt != nil {
+		refreshedAt = v.RefreshedAt.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprintf("%s/%s/%d/%s", v.Source, v.Version, v.Rows, refreshedAt), nil
+}
+
+// UpdateWellKnownList refreshes the list when its data is older than
+// maxAge; a zero maxAge forces the refresh.
+func (w *WellKnownDomainChecker) UpdateWellKnownList(ctx context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	return emailchecker.RecordRefresh(ctx, w.repo, emailchecker.ListTop, func(ctx context.Context) (*emailchecker.ListRefreshResult, error) {
+		return w.refresh(ctx, maxAge)
+	})
+}
+
+func (w *WellKnownDomainChecker) Sources() []emailchecker.DataSource {
+	return w.fetcher.Sources()
+}
+
+func (w *WellKnownDomainChecker) refresh(ctx context.Context, maxAge time.Duration) (*emailchecker.ListRefreshResult, error) {
+	start := time.Now()
+
+	needsRefresh, err := w.repo.TopNeedsRefresh(ctx, maxAge)
+	if err != nil {
+		return nil, fmt.Errorf("could not check if top domains need refresh: %w", err)
 	}
 
 	if !needsRefresh {
-		return nil
+		return &emailchecker.ListRefreshResult{List: emailchecker.ListTop, Skipped: true}, nil
 	}
 
 	topList, err := w.fetcher.GetTopList(ctx)
+	if errors.Is(err, httpext.ErrNotModified) {
+		if err := w.repo.MarkTopRefreshed(ctx); err != nil {
+			return nil, fmt.Errorf("could not mark top domains refreshed: %w", err)
+		}
+
+		return &emailchecker.ListRefreshResult{
+			List:        emailchecker.ListTop,
+			NotModified: true,
+			Elapsed:     time.Since(start),
+		}, nil
+	}
+
 	if err != nil {
-		return fmt.Errorf("could not fetch top domains: %w", err)
+		return nil, fmt.Errorf("could not fetch top domains: %w", err)
 	}
 
 	if len(topList) == 0 {
-		return fmt.Errorf("top list is empty")
+		return nil, fmt.Errorf("top list is empty")
 	}
 
-	err = w.repo.UpdateTopDomains(ctx, topList)
+	result, err := w.repo.UpdateTopDomains(ctx, topList, &w.guard)
 	if err != nil {
-		return fmt.Errorf("could not update top domains: %w", err)
+		return nil, fmt.Errorf("could not update top domains: %w", err)
+	}
+
+	if err := w.fetcher.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("could not store top list validators: %w", err)
 	}
 
-	return nil
+	result.List = emailchecker.ListTop
+	result.Elapsed = time.Since(start)
+
+	return result, nil
 }